@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"gateway"
+	"gateway/logging"
 	"gateway/pii"
+	"gateway/tracing"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"time"
@@ -57,60 +59,51 @@ func DefaultProviderCall(providerURL string, connectTimeout time.Duration) gatew
 		}
 		body, err := json.Marshal(payload)
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, DefaultProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(DefaultProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
 
 		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, providerURL, bytes.NewReader(body))
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, DefaultProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(DefaultProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
+		tracing.Inject(ctx, httpReq.Header)
 		httpReq.Header.Set("Content-Type", "application/json")
 
-		log.Printf(
-			"sms provider request referenceId=%q provider=%q url=%q recipientMasked=%q messageLen=%d messageHash=%q",
-			req.ReferenceID,
-			DefaultProviderName,
-			providerURL,
-			recipientMasked,
-			messageLen,
-			messageHash,
-		)
+		slog.Info("sms provider request", logging.ReferenceID(req.ReferenceID), logging.Provider(DefaultProviderName), "url", providerURL, "recipientMasked", recipientMasked, "messageLen", messageLen, "messageHash", messageHash)
 		resp, err := client.Do(httpReq)
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, DefaultProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(DefaultProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
 		defer resp.Body.Close()
 
+		if throttled, ok := providerThrottled(resp); ok {
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(DefaultProviderName), "httpStatus", resp.StatusCode, "retryAfter", throttled.RetryAfter.String(), "mapped", "provider_throttled")
+			return gateway.ProviderResult{}, throttled
+		}
 		if resp.StatusCode != http.StatusOK {
-			log.Printf("sms provider error referenceId=%q provider=%q status=%d", req.ReferenceID, DefaultProviderName, resp.StatusCode)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(DefaultProviderName), "httpStatus", resp.StatusCode)
 			return gateway.ProviderResult{}, errors.New("provider non-200 response")
 		}
 
 		dec := json.NewDecoder(resp.Body)
 		var providerResp providerResponse
 		if err := dec.Decode(&providerResp); err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, DefaultProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(DefaultProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
 		if err := dec.Decode(&struct{}{}); err != io.EOF {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, DefaultProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(DefaultProviderName), "error", err)
 			return gateway.ProviderResult{}, errors.New("provider response has trailing data")
 		}
 		if providerResp.Status == "" {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, DefaultProviderName, "provider status missing")
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(DefaultProviderName), "error", "provider status missing")
 			return gateway.ProviderResult{}, errors.New("provider status missing")
 		}
 
-		log.Printf(
-			"sms provider response referenceId=%q provider=%q status=%q reason=%q",
-			req.ReferenceID,
-			DefaultProviderName,
-			providerResp.Status,
-			providerResp.Reason,
-		)
+		slog.Info("sms provider response", logging.ReferenceID(req.ReferenceID), logging.Provider(DefaultProviderName), "status", providerResp.Status, "reason", providerResp.Reason)
 		return gateway.ProviderResult{
 			Status: providerResp.Status,
 			Reason: providerResp.Reason,
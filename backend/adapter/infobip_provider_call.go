@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"gateway"
+	"gateway/logging"
 	"gateway/pii"
-	"log"
+	"gateway/tracing"
+	"log/slog"
 	"net"
 	"net/http"
 	"time"
@@ -64,40 +66,37 @@ func SmsInfoBipProviderCall(providerURL, apiKey, senderID string, connectTimeout
 		}
 		body, err := json.Marshal(requestBody)
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, SmsInfoBipProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsInfoBipProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
 
 		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, providerURL, bytes.NewReader(body))
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, SmsInfoBipProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsInfoBipProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
+		tracing.Inject(ctx, httpReq.Header)
 		httpReq.Header.Set("Content-Type", "application/json")
 		httpReq.Header.Set("App", apiKey)
 
-		log.Printf(
-			"sms provider request referenceId=%q provider=%q url=%q recipientMasked=%q messageLen=%d messageHash=%q",
-			req.ReferenceID,
-			SmsInfoBipProviderName,
-			providerURL,
-			recipientMasked,
-			messageLen,
-			messageHash,
-		)
+		slog.Info("sms provider request", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsInfoBipProviderName), "url", providerURL, "recipientMasked", recipientMasked, "messageLen", messageLen, "messageHash", messageHash)
 		resp, err := client.Do(httpReq)
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, SmsInfoBipProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsInfoBipProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
 		defer resp.Body.Close()
 
-		log.Printf("sms provider response referenceId=%q provider=%q status=%d", req.ReferenceID, SmsInfoBipProviderName, resp.StatusCode)
+		slog.Info("sms provider response", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsInfoBipProviderName), "httpStatus", resp.StatusCode)
+		if throttled, ok := providerThrottled(resp); ok {
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsInfoBipProviderName), "httpStatus", resp.StatusCode, "retryAfter", throttled.RetryAfter.String(), "mapped", "provider_throttled")
+			return gateway.ProviderResult{}, throttled
+		}
 		if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
-			log.Printf("sms provider decision referenceId=%q provider=%q mapped=accepted", req.ReferenceID, SmsInfoBipProviderName)
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsInfoBipProviderName), "mapped", "accepted")
 			return gateway.ProviderResult{Status: "accepted"}, nil
 		}
-		log.Printf("sms provider decision referenceId=%q provider=%q status=%d mapped=provider_failure", req.ReferenceID, SmsInfoBipProviderName, resp.StatusCode)
+		slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsInfoBipProviderName), "httpStatus", resp.StatusCode, "mapped", "provider_failure")
 		return gateway.ProviderResult{}, errors.New("provider non-2xx response")
 	}
 }
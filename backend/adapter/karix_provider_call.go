@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"gateway"
+	"gateway/logging"
 	"gateway/pii"
-	"log"
+	"gateway/tracing"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
@@ -60,32 +62,29 @@ func SmsKarixProviderCall(providerURL, apiKey, version, senderID string, connect
 
 		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%q", req.ReferenceID, SmsKarixProviderName, "request_build_failed")
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsKarixProviderName), "error", "request_build_failed")
 			return gateway.ProviderResult{}, err
 		}
+		tracing.Inject(ctx, httpReq.Header)
 
-		log.Printf(
-			"sms provider request referenceId=%q provider=%q url=%q recipientMasked=%q messageLen=%d messageHash=%q",
-			req.ReferenceID,
-			SmsKarixProviderName,
-			providerURL,
-			recipientMasked,
-			messageLen,
-			messageHash,
-		)
+		slog.Info("sms provider request", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsKarixProviderName), "url", providerURL, "recipientMasked", recipientMasked, "messageLen", messageLen, "messageHash", messageHash)
 		resp, err := client.Do(httpReq)
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%q", req.ReferenceID, SmsKarixProviderName, "request_failed")
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsKarixProviderName), "error", "request_failed")
 			return gateway.ProviderResult{}, err
 		}
 		defer resp.Body.Close()
 
-		log.Printf("sms provider response referenceId=%q provider=%q status=%d", req.ReferenceID, SmsKarixProviderName, resp.StatusCode)
+		slog.Info("sms provider response", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsKarixProviderName), "httpStatus", resp.StatusCode)
+		if throttled, ok := providerThrottled(resp); ok {
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsKarixProviderName), "httpStatus", resp.StatusCode, "retryAfter", throttled.RetryAfter.String(), "mapped", "provider_throttled")
+			return gateway.ProviderResult{}, throttled
+		}
 		if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
-			log.Printf("sms provider decision referenceId=%q provider=%q mapped=accepted", req.ReferenceID, SmsKarixProviderName)
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsKarixProviderName), "mapped", "accepted")
 			return gateway.ProviderResult{Status: "accepted"}, nil
 		}
-		log.Printf("sms provider decision referenceId=%q provider=%q status=%d mapped=provider_failure", req.ReferenceID, SmsKarixProviderName, resp.StatusCode)
+		slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(SmsKarixProviderName), "httpStatus", resp.StatusCode, "mapped", "provider_failure")
 		return gateway.ProviderResult{}, errors.New("provider non-2xx response")
 	}
 }
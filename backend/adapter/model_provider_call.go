@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"gateway"
+	"gateway/logging"
 	"gateway/pii"
+	"gateway/tracing"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"strings"
@@ -59,82 +61,79 @@ func ModelProviderCall(providerURL string, connectTimeout time.Duration) gateway
 		}
 		body, err := json.Marshal(requestBody)
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, ModelProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
 
 		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, providerURL, bytes.NewReader(body))
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, ModelProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
+		tracing.Inject(ctx, httpReq.Header)
 		httpReq.Header.Set("Content-Type", "application/json")
 		if req.ReferenceID != "" {
 			httpReq.Header.Set("X-Request-Id", req.ReferenceID)
 		}
 
-		log.Printf(
-			"sms provider request referenceId=%q provider=%q url=%q recipientMasked=%q messageLen=%d messageHash=%q",
-			req.ReferenceID,
-			ModelProviderName,
-			providerURL,
-			recipientMasked,
-			messageLen,
-			messageHash,
-		)
+		slog.Info("sms provider request", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "url", providerURL, "recipientMasked", recipientMasked, "messageLen", messageLen, "messageHash", messageHash)
 		resp, err := client.Do(httpReq)
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, ModelProviderName, err)
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
 		defer resp.Body.Close()
 
-		log.Printf("sms provider response referenceId=%q provider=%q status=%d", req.ReferenceID, ModelProviderName, resp.StatusCode)
+		slog.Info("sms provider response", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "httpStatus", resp.StatusCode)
+		if throttled, ok := providerThrottled(resp); ok {
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "httpStatus", resp.StatusCode, "retryAfter", throttled.RetryAfter.String(), "mapped", "provider_throttled")
+			return gateway.ProviderResult{}, throttled
+		}
 		switch resp.StatusCode {
 		case http.StatusOK:
 			var successBody modelProviderSuccessBody
 			dec := json.NewDecoder(resp.Body)
 			if err := dec.Decode(&successBody); err != nil {
-				log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, ModelProviderName, err)
+				slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", err)
 				return gateway.ProviderResult{}, err
 			}
 			if err := dec.Decode(&struct{}{}); err != io.EOF {
-				log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, ModelProviderName, err)
+				slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", err)
 				return gateway.ProviderResult{}, errors.New("provider response has trailing data")
 			}
 			if successBody.Status != "OK" || strings.TrimSpace(successBody.ProviderID) == "" {
-				log.Printf("sms provider error referenceId=%q provider=%q error=missing_required_fields", req.ReferenceID, ModelProviderName)
+				slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", "missing_required_fields")
 				return gateway.ProviderResult{}, errors.New("provider response missing required fields")
 			}
-			log.Printf("sms provider decision referenceId=%q provider=%q mapped=accepted", req.ReferenceID, ModelProviderName)
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "mapped", "accepted")
 			return gateway.ProviderResult{Status: "accepted"}, nil
 		case http.StatusBadRequest:
 			var errorBody modelProviderErrorBody
 			dec := json.NewDecoder(resp.Body)
 			if err := dec.Decode(&errorBody); err != nil {
-				log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, ModelProviderName, err)
+				slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", err)
 				return gateway.ProviderResult{}, err
 			}
 			if err := dec.Decode(&struct{}{}); err != io.EOF {
-				log.Printf("sms provider error referenceId=%q provider=%q error=%v", req.ReferenceID, ModelProviderName, err)
+				slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", err)
 				return gateway.ProviderResult{}, errors.New("provider response has trailing data")
 			}
 			switch strings.TrimSpace(errorBody.Error) {
 			case "INVALID_RECIPIENT":
-				log.Printf("sms provider decision referenceId=%q provider=%q error=%q mapped=invalid_recipient", req.ReferenceID, ModelProviderName, errorBody.Error)
+				slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", errorBody.Error, "mapped", "invalid_recipient")
 				return gateway.ProviderResult{Status: "rejected", Reason: "invalid_recipient"}, nil
 			case "INVALID_MESSAGE":
-				log.Printf("sms provider decision referenceId=%q provider=%q error=%q mapped=invalid_message", req.ReferenceID, ModelProviderName, errorBody.Error)
+				slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", errorBody.Error, "mapped", "invalid_message")
 				return gateway.ProviderResult{Status: "rejected", Reason: "invalid_message"}, nil
 			default:
-				log.Printf("sms provider error referenceId=%q provider=%q error=%q", req.ReferenceID, ModelProviderName, errorBody.Error)
+				slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "error", errorBody.Error)
 				return gateway.ProviderResult{}, errors.New("provider response unknown error")
 			}
 		case http.StatusInternalServerError:
-			log.Printf("sms provider decision referenceId=%q provider=%q status=%d mapped=provider_failure", req.ReferenceID, ModelProviderName, resp.StatusCode)
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "httpStatus", resp.StatusCode, "mapped", "provider_failure")
 			return gateway.ProviderResult{}, errors.New("provider failure")
 		default:
-			log.Printf("sms provider decision referenceId=%q provider=%q status=%d mapped=provider_failure", req.ReferenceID, ModelProviderName, resp.StatusCode)
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(ModelProviderName), "httpStatus", resp.StatusCode, "mapped", "provider_failure")
 			return gateway.ProviderResult{}, errors.New("provider unexpected status")
 		}
 	}
@@ -3,6 +3,7 @@ package adapter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"gateway"
 	"io"
 	"net/http"
@@ -194,3 +195,25 @@ func TestModelProviderFailureStatus(t *testing.T) {
 		t.Fatal("expected error for provider failure status")
 	}
 }
+
+func TestModelProviderThrottled(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer provider.Close()
+
+	providerCall := ModelProviderCall(provider.URL, defaultProviderConnectTimeout)
+	_, err := providerCall(context.Background(), gateway.SMSRequest{
+		ReferenceID: "ref-6",
+		To:          "15551234567",
+		Message:     "hello",
+	})
+	var throttled gateway.ProviderThrottledError
+	if !errors.As(err, &throttled) {
+		t.Fatalf("expected throttled error, got %v", err)
+	}
+	if throttled.RetryAfter != 30*time.Second {
+		t.Fatalf("expected retry after 30s, got %s", throttled.RetryAfter)
+	}
+}
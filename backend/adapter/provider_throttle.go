@@ -0,0 +1,18 @@
+package adapter
+
+import (
+	"net/http"
+	"time"
+
+	"gateway"
+)
+
+// providerThrottled reports whether the provider response signals rate limiting (429).
+func providerThrottled(resp *http.Response) (gateway.ProviderThrottledError, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return gateway.ProviderThrottledError{}, false
+	}
+	return gateway.ProviderThrottledError{
+		RetryAfter: gateway.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}, true
+}
@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"gateway"
+	"gateway/logging"
 	"gateway/pii"
+	"gateway/tracing"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
@@ -79,7 +81,7 @@ func PushFCMProviderCallWithTokenSource(providerURL string, tokenSource func(con
 	return func(ctx context.Context, req gateway.PushRequest) (gateway.ProviderResult, error) {
 		token, err := tokenSource(ctx)
 		if err != nil {
-			log.Printf("push provider error referenceId=%q provider=%q error=%v", req.ReferenceID, PushFCMProviderName, err)
+			slog.Error("push provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
 
@@ -105,37 +107,34 @@ func PushFCMProviderCallWithTokenSource(providerURL string, tokenSource func(con
 		}
 		body, err := json.Marshal(requestBody)
 		if err != nil {
-			log.Printf("push provider error referenceId=%q provider=%q error=%v", req.ReferenceID, PushFCMProviderName, err)
+			slog.Error("push provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
 
 		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, providerURL, bytes.NewReader(body))
 		if err != nil {
-			log.Printf("push provider error referenceId=%q provider=%q error=%v", req.ReferenceID, PushFCMProviderName, err)
+			slog.Error("push provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
+		tracing.Inject(ctx, httpReq.Header)
 		httpReq.Header.Set("Content-Type", "application/json")
 		httpReq.Header.Set("Authorization", "Bearer "+token)
 
-		log.Printf(
-			"push provider request referenceId=%q provider=%q url=%q tokenMasked=%q messageLen=%d messageHash=%q",
-			req.ReferenceID,
-			PushFCMProviderName,
-			providerURL,
-			tokenMasked,
-			messageLen,
-			messageHash,
-		)
+		slog.Info("push provider request", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "url", providerURL, "tokenMasked", tokenMasked, "messageLen", messageLen, "messageHash", messageHash)
 		resp, err := client.Do(httpReq)
 		if err != nil {
-			log.Printf("push provider error referenceId=%q provider=%q error=%v", req.ReferenceID, PushFCMProviderName, err)
+			slog.Error("push provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "error", err)
 			return gateway.ProviderResult{}, err
 		}
 		defer resp.Body.Close()
 
-		log.Printf("push provider response referenceId=%q provider=%q status=%d", req.ReferenceID, PushFCMProviderName, resp.StatusCode)
+		slog.Info("push provider response", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "httpStatus", resp.StatusCode)
+		if throttled, ok := providerThrottled(resp); ok {
+			slog.Info("push provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "httpStatus", resp.StatusCode, "retryAfter", throttled.RetryAfter.String(), "mapped", "provider_throttled")
+			return gateway.ProviderResult{}, throttled
+		}
 		if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
-			log.Printf("push provider decision referenceId=%q provider=%q mapped=accepted", req.ReferenceID, PushFCMProviderName)
+			slog.Info("push provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "mapped", "accepted")
 			return gateway.ProviderResult{Status: "accepted"}, nil
 		}
 		errorBody := readLimitedBody(resp, fcmDebugMaxBytes)
@@ -145,17 +144,17 @@ func PushFCMProviderCallWithTokenSource(providerURL string, tokenSource func(con
 		// FCM signals stale/invalid device tokens as UNREGISTERED; surface a stable rejection reason so clients can drop the token.
 		if isFCMUnregistered(errorBody) {
 			if errorBody != "" && isFCMDebugEnabled() {
-				log.Printf("push provider error body referenceId=%q provider=%q status=%d body=%q", req.ReferenceID, PushFCMProviderName, resp.StatusCode, errorBody)
+				slog.Info("push provider error body", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "httpStatus", resp.StatusCode, "body", errorBody)
 			}
-			log.Printf("push provider decision referenceId=%q provider=%q status=%d mapped=unregistered_token", req.ReferenceID, PushFCMProviderName, resp.StatusCode)
+			slog.Info("push provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "httpStatus", resp.StatusCode, "mapped", "unregistered_token")
 			return gateway.ProviderResult{Status: "rejected", Reason: "unregistered_token"}, nil
 		}
 		if isFCMDebugEnabled() {
 			if errorBody != "" {
-				log.Printf("push provider error body referenceId=%q provider=%q status=%d body=%q", req.ReferenceID, PushFCMProviderName, resp.StatusCode, errorBody)
+				slog.Info("push provider error body", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "httpStatus", resp.StatusCode, "body", errorBody)
 			}
 		}
-		log.Printf("push provider decision referenceId=%q provider=%q status=%d mapped=provider_failure", req.ReferenceID, PushFCMProviderName, resp.StatusCode)
+		slog.Info("push provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(PushFCMProviderName), "httpStatus", resp.StatusCode, "mapped", "provider_failure")
 		return gateway.ProviderResult{}, errors.New("provider non-2xx response")
 	}
 }
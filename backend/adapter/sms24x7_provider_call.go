@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"gateway"
+	"gateway/logging"
 	"gateway/pii"
-	"log"
+	"gateway/tracing"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
@@ -58,34 +60,31 @@ func Sms24X7ProviderCall(providerURL, apiKey, serviceName, senderID string, conn
 
 		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, nil)
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%q", req.ReferenceID, Sms24X7ProviderName, "request_build_failed")
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(Sms24X7ProviderName), "error", "request_build_failed")
 			return gateway.ProviderResult{}, err
 		}
+		tracing.Inject(ctx, httpReq.Header)
 
-		log.Printf(
-			"sms provider request referenceId=%q provider=%q url=%q recipientMasked=%q messageLen=%d messageHash=%q",
-			req.ReferenceID,
-			Sms24X7ProviderName,
-			providerURL,
-			recipientMasked,
-			messageLen,
-			messageHash,
-		)
+		slog.Info("sms provider request", logging.ReferenceID(req.ReferenceID), logging.Provider(Sms24X7ProviderName), "url", providerURL, "recipientMasked", recipientMasked, "messageLen", messageLen, "messageHash", messageHash)
 		resp, err := client.Do(httpReq)
 		if err != nil {
-			log.Printf("sms provider error referenceId=%q provider=%q error=%q", req.ReferenceID, Sms24X7ProviderName, "request_failed")
+			slog.Error("sms provider error", logging.ReferenceID(req.ReferenceID), logging.Provider(Sms24X7ProviderName), "error", "request_failed")
 			return gateway.ProviderResult{}, err
 		}
 		defer resp.Body.Close()
 
-		log.Printf("sms provider response referenceId=%q provider=%q status=%d", req.ReferenceID, Sms24X7ProviderName, resp.StatusCode)
+		slog.Info("sms provider response", logging.ReferenceID(req.ReferenceID), logging.Provider(Sms24X7ProviderName), "httpStatus", resp.StatusCode)
 
+		if throttled, ok := providerThrottled(resp); ok {
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(Sms24X7ProviderName), "httpStatus", resp.StatusCode, "retryAfter", throttled.RetryAfter.String(), "mapped", "provider_throttled")
+			return gateway.ProviderResult{}, throttled
+		}
 		// We are looking for status codes in the 2xx range for success.
 		if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices { // http.StatusMultipleChoices is 300 status code.
-			log.Printf("sms provider decision referenceId=%q provider=%q mapped=accepted", req.ReferenceID, Sms24X7ProviderName)
+			slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(Sms24X7ProviderName), "mapped", "accepted")
 			return gateway.ProviderResult{Status: "accepted"}, nil
 		}
-		log.Printf("sms provider decision referenceId=%q provider=%q status=%d mapped=provider_failure", req.ReferenceID, Sms24X7ProviderName, resp.StatusCode)
+		slog.Info("sms provider decision", logging.ReferenceID(req.ReferenceID), logging.Provider(Sms24X7ProviderName), "httpStatus", resp.StatusCode, "mapped", "provider_failure")
 		return gateway.ProviderResult{}, errors.New("provider non-2xx response")
 	}
 }
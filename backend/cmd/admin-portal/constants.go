@@ -10,6 +10,7 @@ const (
 	navSMS           = "sms"
 	navPush          = "push"
 	navTroubleshoot  = "troubleshoot"
+	navSchedule      = "schedule"
 	navDashboards    = "dashboards"
 	navHAProxy       = "haproxy"
 	navCommandCenter = "command-center"
@@ -2,15 +2,20 @@ package main
 
 import (
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
 	"path/filepath"
+
+	"gateway/logging"
 )
 
 var configPath = flag.String("config", "conf/admin_portal.json", "Admin portal config file path")
 var listenAddr = flag.String("addr", ":8090", "HTTP listen address")
 var showHelp = flag.Bool("help", false, "show usage")
 var showVersion = flag.Bool("version", false, "show version")
+var logFlags = logging.RegisterFlags(flag.CommandLine)
 
 func main() {
 	flag.Parse()
@@ -19,22 +24,26 @@ func main() {
 		return
 	}
 	if *showVersion {
-		log.Printf("admin-portal version %s", version)
+		fmt.Printf("admin-portal version %s\n", version)
 		return
 	}
+	if _, err := logFlags.Setup("admin-portal"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	cfg, err := loadConfig(*configPath)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("load config", "error", err, "configPath", *configPath)
 	}
 
 	uiDir, err := findUIDir()
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("find ui dir", "error", err)
 	}
 	templates, err := loadPortalTemplates(uiDir)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("load templates", "error", err)
 	}
 
 	server := &portalServer{
@@ -57,23 +66,27 @@ func main() {
 	mux.HandleFunc("/sms/ui/", server.handleSMSUI)
 	mux.HandleFunc("/sms/ui/troubleshoot", server.handleSMSTroubleshoot)
 	mux.HandleFunc("/sms/ui/troubleshoot/history", server.handleSMSTroubleshootHistory)
+	mux.HandleFunc("/sms/ui/troubleshoot/webhook/redeliver", server.handleSMSTroubleshootRedeliver)
 	mux.HandleFunc("/sms/send", server.handleSMSAPI)
 	mux.HandleFunc("/sms/status", server.handleSMSStatus)
 	mux.HandleFunc("/dashboards", server.handleDashboards)
 	mux.HandleFunc("/dashboards/submission-manager", server.handleSubmissionManagerDashboard)
 	mux.HandleFunc("/troubleshoot", server.handleTroubleshoot)
 	mux.HandleFunc("/troubleshoot/history", server.handleTroubleshootHistory)
+	mux.HandleFunc("/troubleshoot/webhook/redeliver", server.handleTroubleshootRedeliver)
+	mux.HandleFunc("/schedule", server.handleSchedule)
 	mux.HandleFunc("/push/ui", server.handlePushUI)
 	mux.HandleFunc("/push/ui/", server.handlePushUI)
 	mux.HandleFunc("/push/ui/troubleshoot", server.handlePushTroubleshoot)
 	mux.HandleFunc("/push/ui/troubleshoot/history", server.handlePushTroubleshootHistory)
+	mux.HandleFunc("/push/ui/troubleshoot/webhook/redeliver", server.handlePushTroubleshootRedeliver)
 	mux.HandleFunc("/push/send", server.handlePushAPI)
 	mux.HandleFunc("/push/status", server.handlePushStatus)
 	mux.HandleFunc("/command-center/ui", server.handleCommandCenterUI)
 	mux.HandleFunc("/command-center/ui/", server.handleCommandCenterUI)
 
-	log.Printf("listening on %s configPath=%q", *listenAddr, *configPath)
+	slog.Info("listening", "addr", *listenAddr, "configPath", *configPath)
 	if err := http.ListenAndServe(*listenAddr, mux); err != nil {
-		log.Fatal(err)
+		logging.Fatal("server error", "error", err)
 	}
 }
@@ -303,6 +303,7 @@ func newTestPortalServer(t *testing.T, cfg fileConfig) *portalServer {
 	haproxy := template.Must(template.New("portal_haproxy.tmpl").Parse(`{{define "portal_haproxy.tmpl"}}haproxy {{len .Frontends}} {{len .Backends}} {{.Error}}{{end}}`))
 	errView := template.Must(template.New("portal_error.tmpl").Parse(`{{define "portal_error.tmpl"}}error {{.Title}} {{.Message}}{{end}}`))
 	troubleshoot := template.Must(template.New("portal_troubleshoot.tmpl").Parse(`{{define "portal_troubleshoot.tmpl"}}troubleshoot {{.HistoryAction}}{{end}}`))
+	schedule := template.Must(template.New("portal_schedule.tmpl").Parse(`{{define "portal_schedule.tmpl"}}schedule {{.LocalHolder}} {{len .Leases}} {{range .QueueDepth}}{{.SubmissionTarget}}={{.Depth}} {{end}}{{len .Due}} {{.Error}}{{end}}`))
 	dashboards := template.Must(template.New("portal_dashboards.tmpl").Parse(`{{define "portal_dashboards.tmpl"}}dashboards {{.SubmissionURL}} {{.SMSGatewayURL}} {{.PushGatewayURL}}{{end}}`))
 	dashboardEmbed := template.Must(template.New("portal_dashboard_embed.tmpl").Parse(`{{define "portal_dashboard_embed.tmpl"}}dashboard {{.Title}} {{.DashboardURL}}{{end}}`))
 	submissionResult := template.Must(template.New("submission_result.tmpl").Parse(`{{define "submission_result.tmpl"}}submission {{.IntentID}} {{.StatusEndpoint}} {{.Status}} {{.RejectedReason}} {{.ExhaustedReason}} {{.CompletedAt}} {{.Error}}{{end}}`))
@@ -314,6 +315,7 @@ func newTestPortalServer(t *testing.T, cfg fileConfig) *portalServer {
 			haproxy:          haproxy,
 			errView:          errView,
 			troubleshoot:     troubleshoot,
+			schedule:         schedule,
 			dashboards:       dashboards,
 			dashboardEmbed:   dashboardEmbed,
 			submissionResult: submissionResult,
@@ -580,6 +582,60 @@ func TestHandleTroubleshootHistoryProxy(t *testing.T) {
 	}
 }
 
+func TestHandleTroubleshootRedeliverProxy(t *testing.T) {
+	var seenIntent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ui/webhook/redeliver" {
+			t.Fatalf("expected /ui/webhook/redeliver, got %q", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		seenIntent = r.FormValue("intentId")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, "redelivery ok")
+	}))
+	defer upstream.Close()
+
+	server := newTestPortalServer(t, fileConfig{SubmissionManagerURL: upstream.URL})
+	body := strings.NewReader("intentId=abc-123")
+	req := httptest.NewRequest(http.MethodPost, "/troubleshoot/webhook/redeliver", body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	rr := httptest.NewRecorder()
+	server.handleTroubleshootRedeliver(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if seenIntent != "abc-123" {
+		t.Fatalf("expected intentId forwarded, got %q", seenIntent)
+	}
+	if !strings.Contains(rr.Body.String(), "redelivery ok") {
+		t.Fatalf("expected upstream body, got %q", rr.Body.String())
+	}
+}
+
+func TestHandleTroubleshootRedeliverConflict(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "webhook cannot be redelivered: intent_not_terminal", http.StatusConflict)
+	}))
+	defer upstream.Close()
+
+	server := newTestPortalServer(t, fileConfig{SubmissionManagerURL: upstream.URL})
+	body := strings.NewReader("intentId=pending-1")
+	req := httptest.NewRequest(http.MethodPost, "/push/ui/troubleshoot/webhook/redeliver", body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	rr := httptest.NewRecorder()
+	server.handlePushTroubleshootRedeliver(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "intent_not_terminal") {
+		t.Fatalf("expected error message, got %q", rr.Body.String())
+	}
+}
+
 func TestHandleTroubleshootHistoryProxyError(t *testing.T) {
 	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "intent not found", http.StatusNotFound)
@@ -1119,6 +1175,7 @@ func TestLoadPortalTemplates(t *testing.T) {
 	write("portal_haproxy.tmpl", `{{define "portal_haproxy.tmpl"}}haproxy{{end}}`)
 	write("portal_error.tmpl", `{{define "portal_error.tmpl"}}error{{end}}`)
 	write("portal_troubleshoot.tmpl", `{{define "portal_troubleshoot.tmpl"}}troubleshoot{{end}}`)
+	write("portal_schedule.tmpl", `{{define "portal_schedule.tmpl"}}schedule{{end}}`)
 	write("portal_dashboards.tmpl", `{{define "portal_dashboards.tmpl"}}dashboards{{end}}`)
 	write("portal_dashboard_embed.tmpl", `{{define "portal_dashboard_embed.tmpl"}}dashboard{{end}}`)
 	write("submission_result.tmpl", `{{define "submission_result.tmpl"}}submission{{end}}`)
@@ -1127,7 +1184,57 @@ func TestLoadPortalTemplates(t *testing.T) {
 	if err != nil {
 		t.Fatalf("loadPortalTemplates: %v", err)
 	}
-	if templates.topbar == nil || templates.overview == nil || templates.haproxy == nil || templates.errView == nil || templates.troubleshoot == nil || templates.dashboards == nil || templates.dashboardEmbed == nil || templates.submissionResult == nil {
+	if templates.topbar == nil || templates.overview == nil || templates.haproxy == nil || templates.errView == nil || templates.troubleshoot == nil || templates.schedule == nil || templates.dashboards == nil || templates.dashboardEmbed == nil || templates.submissionResult == nil {
 		t.Fatal("expected templates to be loaded")
 	}
 }
+
+func TestHandleScheduleNotConfigured(t *testing.T) {
+	server := newTestPortalServer(t, fileConfig{})
+	req := httptest.NewRequest(http.MethodGet, "/schedule", nil)
+	req.Header.Set("HX-Request", "true")
+	rr := httptest.NewRecorder()
+	server.handleSchedule(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestHandleSchedule(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/admin/schedule" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"local":{"mode":"leader","holderId":"holder-a"},"leases":[{"partition":0,"holderId":"holder-a","leaseEpoch":3,"active":true}],"queueDepth":{"sms.realtime":2,"push.realtime":1},"due":[{"intentId":"intent-1"}],"inFlight":[],"leaseHistory":[]}`)
+	}))
+	defer upstream.Close()
+	server := newTestPortalServer(t, fileConfig{SubmissionManagerURL: upstream.URL})
+	req := httptest.NewRequest(http.MethodGet, "/schedule", nil)
+	req.Header.Set("HX-Request", "true")
+	rr := httptest.NewRecorder()
+	server.handleSchedule(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, "schedule holder-a 1 push.realtime=1 sms.realtime=2 1") {
+		t.Fatalf("unexpected schedule body: %q", body)
+	}
+}
+
+func TestHandleScheduleUpstreamError(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, `{"error":{"code":"unavailable","message":"schedule view not configured"}}`)
+	}))
+	defer upstream.Close()
+	server := newTestPortalServer(t, fileConfig{SubmissionManagerURL: upstream.URL})
+	req := httptest.NewRequest(http.MethodGet, "/schedule", nil)
+	req.Header.Set("HX-Request", "true")
+	rr := httptest.NewRecorder()
+	server.handleSchedule(rr, req)
+	if !strings.Contains(rr.Body.String(), "schedule view not configured") {
+		t.Fatalf("expected upstream error in the page, got %q", rr.Body.String())
+	}
+}
@@ -3,7 +3,7 @@ package main
 import (
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -73,7 +73,7 @@ func (s *portalServer) proxyUI(w http.ResponseWriter, r *http.Request, baseURL,
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.WriteHeader(resp.StatusCode)
 		if _, err := w.Write(body); err != nil {
-			log.Printf("write proxy fragment: %v", err)
+			slog.Error("write proxy fragment", "error", err)
 		}
 		return
 	}
@@ -83,7 +83,7 @@ func (s *portalServer) proxyUI(w http.ResponseWriter, r *http.Request, baseURL,
 	}
 	w.WriteHeader(resp.StatusCode)
 	if _, err := w.Write(body); err != nil {
-		log.Printf("write proxy response: %v", err)
+		slog.Error("write proxy response", "error", err)
 	}
 }
 
@@ -116,7 +116,7 @@ func (s *portalServer) proxyAPI(w http.ResponseWriter, r *http.Request, baseURL
 	}
 	w.WriteHeader(resp.StatusCode)
 	if _, err := io.Copy(w, resp.Body); err != nil {
-		log.Printf("write proxy api: %v", err)
+		slog.Error("write proxy api", "error", err)
 	}
 }
 
@@ -3,7 +3,7 @@ package main
 import (
 	"html/template"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 )
 
@@ -16,7 +16,7 @@ func (s *portalServer) renderPage(w http.ResponseWriter, r *http.Request, tmpl *
 	if isHTMX(r) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		if _, err := w.Write(fragment); err != nil {
-			log.Printf("write fragment: %v", err)
+			slog.Error("write fragment", "error", err)
 		}
 		return
 	}
@@ -29,11 +29,12 @@ func (s *portalServer) renderShell(w http.ResponseWriter, fragment []byte, activ
 		ShowSMS:           s.config.SMSGatewayURL != "",
 		ShowPush:          s.config.PushGatewayURL != "",
 		ShowTroubleshoot:  s.config.SubmissionManagerURL != "",
+		ShowSchedule:      s.config.SubmissionManagerURL != "",
 		ShowDashboards:    s.config.SubmissionManagerDashboardURL != "" || s.config.SMSGatewayURL != "" || s.config.PushGatewayURL != "",
 		ShowCommandCenter: s.config.CommandCenterURL != "",
 	})
 	if err != nil {
-		log.Printf("render topbar: %v", err)
+		slog.Error("render topbar", "error", err)
 		http.Error(w, "render error", http.StatusInternalServerError)
 		return
 	}
@@ -44,23 +45,23 @@ func (s *portalServer) renderShell(w http.ResponseWriter, fragment []byte, activ
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(status)
 	if _, err := io.WriteString(w, "<!doctype html><html lang=\"en\"><head><meta charset=\"utf-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1\"><link rel=\"stylesheet\" href=\"/ui/static/ui.css\"><title>"+template.HTMLEscapeString(resolveTitle(s.config.Title))+"</title></head><body>"); err != nil {
-		log.Printf("write shell start: %v", err)
+		slog.Error("write shell start", "error", err)
 		return
 	}
 	if _, err := w.Write(topbar); err != nil {
-		log.Printf("write topbar: %v", err)
+		slog.Error("write topbar", "error", err)
 		return
 	}
 	if _, err := io.WriteString(w, "<div id=\"ui-root\" class=\"portal-root\">"); err != nil {
-		log.Printf("write shell root: %v", err)
+		slog.Error("write shell root", "error", err)
 		return
 	}
 	if _, err := w.Write(fragment); err != nil {
-		log.Printf("write shell fragment: %v", err)
+		slog.Error("write shell fragment", "error", err)
 		return
 	}
 	if _, err := io.WriteString(w, "</div><script src=\"/ui/static/htmx.min.js\"></script><script src=\"/ui/static/json-enc.js\"></script><script src=\"/ui/static/theme.js\"></script></body></html>"); err != nil {
-		log.Printf("write shell end: %v", err)
+		slog.Error("write shell end", "error", err)
 	}
 }
 
@@ -74,7 +75,7 @@ func (s *portalServer) renderError(w http.ResponseWriter, r *http.Request, statu
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.WriteHeader(status)
 		if _, err := w.Write(fragment); err != nil {
-			log.Printf("write error fragment: %v", err)
+			slog.Error("write error fragment", "error", err)
 		}
 		return
 	}
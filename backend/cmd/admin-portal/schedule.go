@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+func (s *portalServer) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.renderError(w, r, http.StatusMethodNotAllowed, "Method not allowed", "method not allowed", navSchedule)
+		return
+	}
+	if s.config.SubmissionManagerURL == "" {
+		s.renderError(w, r, http.StatusNotFound, "SubmissionManager not configured", "submissionManagerUrl is empty in the portal config.", navSchedule)
+		return
+	}
+	status, body, err := s.fetchSchedule(r.Context())
+	if err != nil {
+		s.renderError(w, r, http.StatusBadGateway, "Schedule request failed", err.Error(), navSchedule)
+		return
+	}
+	s.renderPage(w, r, s.templates.schedule, "portal_schedule.tmpl", scheduleViewFromResponse(status, body), navSchedule)
+}
+
+func scheduleViewFromResponse(status int, body []byte) scheduleView {
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		message := submissionErrorMessage(body)
+		if message == "" {
+			message = http.StatusText(status)
+		}
+		return scheduleView{Error: message}
+	}
+	var resp managerScheduleResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return scheduleView{Error: err.Error()}
+	}
+	view := scheduleView{
+		Now:          resp.Now,
+		LocalMode:    resp.Local.Mode,
+		LocalHolder:  resp.Local.HolderID,
+		Leases:       resp.Leases,
+		Due:          resp.Due,
+		InFlight:     resp.InFlight,
+		LeaseHistory: resp.LeaseHistory,
+	}
+	for target, depth := range resp.QueueDepth {
+		view.QueueDepth = append(view.QueueDepth, queueDepthView{SubmissionTarget: target, Depth: depth})
+	}
+	sort.Slice(view.QueueDepth, func(i, j int) bool {
+		return view.QueueDepth[i].SubmissionTarget < view.QueueDepth[j].SubmissionTarget
+	})
+	return view
+}
@@ -62,3 +62,24 @@ func (s *portalServer) fetchIntent(ctx context.Context, intentID string) (int, [
 	}
 	return resp.StatusCode, respBody, resp.Header.Get("Content-Type"), nil
 }
+
+func (s *portalServer) fetchSchedule(ctx context.Context) (int, []byte, error) {
+	targetURL, err := buildTargetURL(s.config.SubmissionManagerURL, "/v1/admin/schedule", "", false)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, respBody, nil
+}
@@ -3,7 +3,7 @@ package main
 import (
 	"encoding/json"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 )
@@ -39,7 +39,7 @@ func (s *portalServer) handlePushStatus(w http.ResponseWriter, r *http.Request)
 		}
 		w.WriteHeader(status)
 		if _, err := w.Write(body); err != nil {
-			log.Printf("write submission response: %v", err)
+			slog.Error("write submission response", "error", err)
 		}
 		return
 	}
@@ -123,7 +123,7 @@ func (s *portalServer) handlePushSubmission(w http.ResponseWriter, r *http.Reque
 		}
 		w.WriteHeader(status)
 		if _, err := w.Write(body); err != nil {
-			log.Printf("write submission response: %v", err)
+			slog.Error("write submission response", "error", err)
 		}
 		return
 	}
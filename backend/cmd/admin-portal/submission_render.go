@@ -1,7 +1,7 @@
 package main
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 )
 
@@ -21,7 +21,7 @@ func (s *portalServer) renderSubmissionResult(w http.ResponseWriter, status int,
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(status)
 	if _, err := w.Write(fragment); err != nil {
-		log.Printf("write submission fragment: %v", err)
+		slog.Error("write submission fragment", "error", err)
 	}
 }
 
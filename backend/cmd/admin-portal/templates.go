@@ -27,6 +27,10 @@ func loadPortalTemplates(uiDir string) (portalTemplates, error) {
 	if err != nil {
 		return portalTemplates{}, err
 	}
+	schedule, err := template.ParseFiles(filepath.Join(uiDir, "portal_schedule.tmpl"))
+	if err != nil {
+		return portalTemplates{}, err
+	}
 	dashboards, err := template.ParseFiles(filepath.Join(uiDir, "portal_dashboards.tmpl"))
 	if err != nil {
 		return portalTemplates{}, err
@@ -45,6 +49,7 @@ func loadPortalTemplates(uiDir string) (portalTemplates, error) {
 		haproxy:          haproxy,
 		errView:          errView,
 		troubleshoot:     troubleshoot,
+		schedule:         schedule,
 		dashboards:       dashboards,
 		dashboardEmbed:   dashboardEmbed,
 		submissionResult: submissionResult,
@@ -13,8 +13,9 @@ func (s *portalServer) handleSMSTroubleshoot(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	view := troubleshootView{
-		HistoryAction:  "/sms/ui/troubleshoot/history",
-		HistoryEnabled: s.config.SubmissionManagerURL != "",
+		HistoryAction:   "/sms/ui/troubleshoot/history",
+		RedeliverAction: "/sms/ui/troubleshoot/webhook/redeliver",
+		HistoryEnabled:  s.config.SubmissionManagerURL != "",
 	}
 	s.renderPage(w, r, s.templates.troubleshoot, "portal_troubleshoot.tmpl", view, navSMS)
 }
@@ -25,8 +26,9 @@ func (s *portalServer) handleTroubleshoot(w http.ResponseWriter, r *http.Request
 		return
 	}
 	view := troubleshootView{
-		HistoryAction:  "/troubleshoot/history",
-		HistoryEnabled: s.config.SubmissionManagerURL != "",
+		HistoryAction:   "/troubleshoot/history",
+		RedeliverAction: "/troubleshoot/webhook/redeliver",
+		HistoryEnabled:  s.config.SubmissionManagerURL != "",
 	}
 	s.renderPage(w, r, s.templates.troubleshoot, "portal_troubleshoot.tmpl", view, navTroubleshoot)
 }
@@ -37,8 +39,9 @@ func (s *portalServer) handlePushTroubleshoot(w http.ResponseWriter, r *http.Req
 		return
 	}
 	view := troubleshootView{
-		HistoryAction:  "/push/ui/troubleshoot/history",
-		HistoryEnabled: s.config.SubmissionManagerURL != "",
+		HistoryAction:   "/push/ui/troubleshoot/history",
+		RedeliverAction: "/push/ui/troubleshoot/webhook/redeliver",
+		HistoryEnabled:  s.config.SubmissionManagerURL != "",
 	}
 	s.renderPage(w, r, s.templates.troubleshoot, "portal_troubleshoot.tmpl", view, navPush)
 }
@@ -55,6 +58,18 @@ func (s *portalServer) handlePushTroubleshootHistory(w http.ResponseWriter, r *h
 	s.handleManagerHistory(w, r, navPush)
 }
 
+func (s *portalServer) handleSMSTroubleshootRedeliver(w http.ResponseWriter, r *http.Request) {
+	s.handleManagerWebhookRedeliver(w, r, navSMS)
+}
+
+func (s *portalServer) handleTroubleshootRedeliver(w http.ResponseWriter, r *http.Request) {
+	s.handleManagerWebhookRedeliver(w, r, navTroubleshoot)
+}
+
+func (s *portalServer) handlePushTroubleshootRedeliver(w http.ResponseWriter, r *http.Request) {
+	s.handleManagerWebhookRedeliver(w, r, navPush)
+}
+
 func (s *portalServer) handleManagerHistory(w http.ResponseWriter, r *http.Request, active string) {
 	if s.config.SubmissionManagerURL == "" {
 		s.renderTroubleshootError(w, r, http.StatusNotFound, "SubmissionManager not configured", "submissionManagerUrl is empty in the portal config.", active)
@@ -74,6 +89,25 @@ func (s *portalServer) handleManagerHistory(w http.ResponseWriter, r *http.Reque
 	s.proxyTroubleshoot(w, r, active, s.config.SubmissionManagerURL, "/ui/history", form)
 }
 
+func (s *portalServer) handleManagerWebhookRedeliver(w http.ResponseWriter, r *http.Request, active string) {
+	if s.config.SubmissionManagerURL == "" {
+		s.renderTroubleshootError(w, r, http.StatusNotFound, "SubmissionManager not configured", "submissionManagerUrl is empty in the portal config.", active)
+		return
+	}
+	if r.Method != http.MethodPost {
+		s.renderTroubleshootError(w, r, http.StatusMethodNotAllowed, "Method not allowed", "method not allowed", active)
+		return
+	}
+	intentID := strings.TrimSpace(r.FormValue("intentId"))
+	if intentID == "" {
+		s.renderTroubleshootError(w, r, http.StatusBadRequest, "IntentId required", "intentId is required", active)
+		return
+	}
+	form := url.Values{}
+	form.Set("intentId", intentID)
+	s.proxyTroubleshoot(w, r, active, s.config.SubmissionManagerURL, "/ui/webhook/redeliver", form)
+}
+
 func (s *portalServer) proxyTroubleshoot(w http.ResponseWriter, r *http.Request, active string, baseURL string, path string, form url.Values) {
 	remoteURL, err := buildTargetURL(baseURL, path, "", false)
 	if err != nil {
@@ -24,6 +24,7 @@ type portalTemplates struct {
 	haproxy          *template.Template
 	errView          *template.Template
 	troubleshoot     *template.Template
+	schedule         *template.Template
 	dashboards       *template.Template
 	dashboardEmbed   *template.Template
 	submissionResult *template.Template
@@ -47,8 +48,9 @@ type submissionResultView struct {
 }
 
 type troubleshootView struct {
-	HistoryAction  string
-	HistoryEnabled bool
+	HistoryAction   string
+	RedeliverAction string
+	HistoryEnabled  bool
 }
 
 type submissionIntentRequest struct {
@@ -67,6 +69,55 @@ type submissionIntentResponse struct {
 	ExhaustedReason  string `json:"exhaustedReason,omitempty"`
 }
 
+// managerScheduleResponse is the subset of GET /v1/admin/schedule the schedule page shows.
+type managerScheduleResponse struct {
+	Now   string `json:"now"`
+	Local struct {
+		Mode       string `json:"mode"`
+		HolderID   string `json:"holderId"`
+		Partitions []int  `json:"partitions"`
+	} `json:"local"`
+	Leases       []scheduleLease    `json:"leases"`
+	QueueDepth   map[string]int     `json:"queueDepth"`
+	Due          []scheduleDue      `json:"due"`
+	InFlight     []scheduleInFlight `json:"inFlight"`
+	LeaseHistory []scheduleEvent    `json:"leaseHistory"`
+}
+
+type scheduleLease struct {
+	Partition  int    `json:"partition"`
+	HolderID   string `json:"holderId"`
+	LeaseEpoch int64  `json:"leaseEpoch"`
+	ExpiresAt  string `json:"expiresAt"`
+	Active     bool   `json:"active"`
+}
+
+type scheduleDue struct {
+	IntentID         string `json:"intentId"`
+	SubmissionTarget string `json:"submissionTarget"`
+	Partition        int    `json:"partition"`
+	AttemptNumber    int    `json:"attemptNumber"`
+	DueAt            string `json:"dueAt"`
+}
+
+type scheduleInFlight struct {
+	IntentID      string `json:"intentId"`
+	Partition     int    `json:"partition"`
+	AttemptNumber int    `json:"attemptNumber"`
+	HolderID      string `json:"holderId"`
+	LeaseEpoch    int64  `json:"leaseEpoch"`
+	ClaimedAt     string `json:"claimedAt"`
+}
+
+type scheduleEvent struct {
+	Partition  int    `json:"partition"`
+	HolderID   string `json:"holderId"`
+	LeaseEpoch int64  `json:"leaseEpoch"`
+	Event      string `json:"event"`
+	Detail     string `json:"detail"`
+	OccurredAt string `json:"occurredAt"`
+}
+
 type submissionErrorResponse struct {
 	Error struct {
 		Code    string `json:"code"`
@@ -96,6 +147,7 @@ type topbarView struct {
 	ShowSMS           bool
 	ShowPush          bool
 	ShowTroubleshoot  bool
+	ShowSchedule      bool
 	ShowDashboards    bool
 	ShowCommandCenter bool
 }
@@ -145,6 +197,23 @@ type haproxyBackend struct {
 	StatusClass  string
 }
 
+type scheduleView struct {
+	Now          string
+	LocalMode    string
+	LocalHolder  string
+	Leases       []scheduleLease
+	QueueDepth   []queueDepthView
+	Due          []scheduleDue
+	InFlight     []scheduleInFlight
+	LeaseHistory []scheduleEvent
+	Error        string
+}
+
+type queueDepthView struct {
+	SubmissionTarget string
+	Depth            int
+}
+
 type errorView struct {
 	Title   string
 	Message string
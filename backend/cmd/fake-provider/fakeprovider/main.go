@@ -3,10 +3,14 @@ package main
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strings"
+
+	"gateway/logging"
 )
 
 const (
@@ -16,6 +20,7 @@ const (
 )
 
 var addr = flag.String("addr", ":9090", "HTTP listen address")
+var logFlags = logging.RegisterFlags(flag.CommandLine)
 
 type providerRequest struct {
 	ReferenceID string `json:"referenceId"`
@@ -31,12 +36,18 @@ type providerResponse struct {
 
 func main() {
 	flag.Parse()
+	if _, err := logFlags.Setup("fake-provider"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/sms/send", handleSend)
 
-	log.Printf("listening on %s", *addr)
-	log.Fatal(http.ListenAndServe(*addr, mux))
+	slog.Info("listening", "addr", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		logging.Fatal("server error", "error", err)
+	}
 }
 
 func handleSend(w http.ResponseWriter, r *http.Request) {
@@ -111,6 +122,6 @@ func writeProviderResponse(w http.ResponseWriter, status int, resp providerRespo
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.Printf("encode response: %v", err)
+		slog.Error("encode response", "error", err)
 	}
 }
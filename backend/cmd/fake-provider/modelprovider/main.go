@@ -3,12 +3,16 @@ package main
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"math/rand"
 	"net/http"
+	"os"
 	"strings"
 	"time"
+
+	"gateway/logging"
 )
 
 const (
@@ -21,6 +25,7 @@ const (
 )
 
 var addr = flag.String("addr", ":9091", "HTTP listen address")
+var logFlags = logging.RegisterFlags(flag.CommandLine)
 
 type modelProviderRequestBody struct {
 	Destination string `json:"destination"`
@@ -38,6 +43,10 @@ type modelProviderErrorBody struct {
 
 func main() {
 	flag.Parse()
+	if _, err := logFlags.Setup("fake-model-provider"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 	rand.Seed(time.Now().UnixNano())
 
 	mux := http.NewServeMux()
@@ -45,8 +54,10 @@ func main() {
 	mux.HandleFunc("/readyz", handleReadyz)
 	mux.HandleFunc(modelProviderEndpoint, handleSend)
 
-	log.Printf("listening on %s", *addr)
-	log.Fatal(http.ListenAndServe(*addr, mux))
+	slog.Info("listening", "addr", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		logging.Fatal("server error", "error", err)
+	}
 }
 
 func handleHealthz(w http.ResponseWriter, r *http.Request) {
@@ -103,12 +114,12 @@ func handleSend(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if strings.Contains(req.Text, providerFailureToken) {
-		log.Printf("provider decision referenceId=%q status=provider_failure", referenceID)
+		slog.Info("provider decision", logging.ReferenceID(referenceID), "status", "provider_failure")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("provider decision referenceId=%q status=accepted providerId=%q", referenceID, modelProviderID)
+	slog.Info("provider decision", logging.ReferenceID(referenceID), "status", "accepted", "providerId", modelProviderID)
 	writeSuccess(w)
 }
 
@@ -127,11 +138,11 @@ func isNumericDestination(to string) bool {
 }
 
 func writeError(w http.ResponseWriter, referenceID, code string) {
-	log.Printf("provider decision referenceId=%q status=rejected error=%q", referenceID, code)
+	slog.Info("provider decision", logging.ReferenceID(referenceID), "status", "rejected", "error", code)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusBadRequest)
 	if err := json.NewEncoder(w).Encode(modelProviderErrorBody{Error: code}); err != nil {
-		log.Printf("encode response: %v", err)
+		slog.Error("encode response", "error", err)
 	}
 }
 
@@ -142,6 +153,6 @@ func writeSuccess(w http.ResponseWriter) {
 		Status:     "OK",
 		ProviderID: modelProviderID,
 	}); err != nil {
-		log.Printf("encode response: %v", err)
+		slog.Error("encode response", "error", err)
 	}
 }
@@ -2,9 +2,13 @@ package main
 
 import (
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
 	"strings"
+
+	"gateway/logging"
 )
 
 const (
@@ -13,15 +17,22 @@ const (
 )
 
 var addr = flag.String("addr", ":9092", "HTTP listen address")
+var logFlags = logging.RegisterFlags(flag.CommandLine)
 
 func main() {
 	flag.Parse()
+	if _, err := logFlags.Setup("fake-sms24x7-provider"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc(providerEndpoint, handleSend)
 
-	log.Printf("listening on %s", *addr)
-	log.Fatal(http.ListenAndServe(*addr, mux))
+	slog.Info("listening", "addr", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		logging.Fatal("server error", "error", err)
+	}
 }
 
 func handleSend(w http.ResponseWriter, r *http.Request) {
@@ -3,10 +3,14 @@ package main
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strings"
+
+	"gateway/logging"
 )
 
 const (
@@ -16,6 +20,7 @@ const (
 )
 
 var addr = flag.String("addr", ":9094", "HTTP listen address")
+var logFlags = logging.RegisterFlags(flag.CommandLine)
 
 type infoBipRequestBody struct {
 	Messages []infoBipMessage `json:"messages"`
@@ -37,12 +42,18 @@ type infoBipResponseBody struct {
 
 func main() {
 	flag.Parse()
+	if _, err := logFlags.Setup("fake-infobip-provider"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc(providerEndpoint, handleSend)
 
-	log.Printf("listening on %s", *addr)
-	log.Fatal(http.ListenAndServe(*addr, mux))
+	slog.Info("listening", "addr", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		logging.Fatal("server error", "error", err)
+	}
 }
 
 func handleSend(w http.ResponseWriter, r *http.Request) {
@@ -95,7 +106,7 @@ func handleSend(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(infoBipResponseBody{Status: "OK"}); err != nil {
-		log.Printf("encode response: %v", err)
+		slog.Error("encode response", "error", err)
 	}
 }
 
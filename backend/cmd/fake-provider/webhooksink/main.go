@@ -3,14 +3,18 @@ package main
 import (
 	"encoding/json"
 	"flag"
+	"fmt"
+	"gateway/logging"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"sync"
 	"time"
 )
 
 var addr = flag.String("addr", ":9999", "HTTP listen address")
+var logFlags = logging.RegisterFlags(flag.CommandLine)
 
 type webhookEvent struct {
 	EventID   string       `json:"eventId"`
@@ -39,6 +43,10 @@ type webhookStore struct {
 
 func main() {
 	flag.Parse()
+	if _, err := logFlags.Setup("webhook-sink"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	store := &webhookStore{}
 	mux := http.NewServeMux()
@@ -47,8 +55,10 @@ func main() {
 	mux.HandleFunc("/webhook", store.handleWebhook)
 	mux.HandleFunc("/last", store.handleLast)
 
-	log.Printf("listening on %s", *addr)
-	log.Fatal(http.ListenAndServe(*addr, mux))
+	slog.Info("listening", "addr", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		logging.Fatal("server error", "error", err)
+	}
 }
 
 func handleHealthz(w http.ResponseWriter, r *http.Request) {
@@ -77,7 +87,7 @@ func (s *webhookStore) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	body, _ := io.ReadAll(r.Body)
 	var event webhookEvent
 	if err := json.Unmarshal(body, &event); err != nil {
-		log.Printf("webhook decode failed: %v", err)
+		slog.Error("webhook decode failed", "error", err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
@@ -85,7 +95,7 @@ func (s *webhookStore) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	s.last = lastWebhook{ReceivedAt: time.Now().UTC(), Event: event}
 	s.ok = true
 	s.mu.Unlock()
-	log.Printf("webhook received eventType=%q intentId=%q status=%q", event.EventType, event.Intent.IntentID, event.Intent.Status)
+	slog.Info("webhook received", "eventType", event.EventType, logging.IntentID(event.Intent.IntentID), "status", event.Intent.Status)
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte("ok"))
 }
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"gateway"
+	"gateway/logging"
 	"gateway/metrics"
+	"gateway/tracing"
 	"html/template"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -55,12 +57,14 @@ func handleReadyz(w http.ResponseWriter, r *http.Request) {
 func handlePushSend(gw *gateway.PushGateway, metricsRegistry *metrics.Registry, sendResult *template.Template) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		ctx, span := gw.Tracer().Start(tracing.Extract(r.Context(), r.Header), "gateway.push.send")
+		defer span.End(nil)
 		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
 
 		dec := json.NewDecoder(r.Body)
 		var req gateway.PushRequest
 		if err := dec.Decode(&req); err != nil {
-			log.Printf("push decision referenceId=%q status=rejected reason=invalid_request source=validation detail=decode_error err=%v", "", err)
+			slog.Info("push decision", logging.ReferenceID(""), "status", "rejected", "reason", "invalid_request", "source", "validation", "detail", "decode_error", "error", err)
 			writePushSendResponse(w, r, http.StatusOK, gateway.PushResponse{
 				Status: "rejected",
 				Reason: "invalid_request",
@@ -71,7 +75,7 @@ func handlePushSend(gw *gateway.PushGateway, metricsRegistry *metrics.Registry,
 			return
 		}
 		if err := dec.Decode(&struct{}{}); err != io.EOF {
-			log.Printf("push decision referenceId=%q status=rejected reason=invalid_request source=validation detail=trailing_json", req.ReferenceID)
+			slog.Info("push decision", logging.ReferenceID(req.ReferenceID), "status", "rejected", "reason", "invalid_request", "source", "validation", "detail", "trailing_json")
 			writePushSendResponse(w, r, http.StatusOK, gateway.PushResponse{
 				Status: "rejected",
 				Reason: "invalid_request",
@@ -82,22 +86,27 @@ func handlePushSend(gw *gateway.PushGateway, metricsRegistry *metrics.Registry,
 			return
 		}
 
-		resp, err := gw.SendPush(r.Context(), req)
+		span.SetAttribute("referenceId", req.ReferenceID)
+		span.SetAttribute("tenantId", req.TenantID)
+		resp, err := gw.SendPush(ctx, req)
 		source := "provider_result"
 		if err != nil && errors.Is(err, gateway.ErrInvalidRequest) {
 			source = "validation"
 		} else if resp.Reason == "provider_failure" {
 			source = "provider_failure"
 		}
-		log.Printf(
-			"push decision referenceId=%q status=%q reason=%q source=%s gatewayMessageId=%q",
-			resp.ReferenceID,
-			resp.Status,
-			resp.Reason,
-			source,
-			resp.GatewayMessageID,
-		)
-		writePushSendResponse(w, r, http.StatusOK, resp, sendResult)
+		span.SetAttribute("status", resp.Status)
+		span.SetAttribute("reason", resp.Reason)
+		httpStatus := http.StatusOK
+		var throttled gateway.ProviderThrottledError
+		if errors.As(err, &throttled) {
+			// Flow intent: surface provider throttling as HTTP backpressure so callers can delay retries.
+			source = "provider_throttled"
+			httpStatus = http.StatusTooManyRequests
+			w.Header().Set("Retry-After", gateway.RetryAfterHeader(throttled.RetryAfter))
+		}
+		slog.Info("push decision", logging.ReferenceID(resp.ReferenceID), logging.TenantID(req.TenantID), "status", resp.Status, "reason", resp.Reason, "source", source, "gatewayMessageId", resp.GatewayMessageID)
+		writePushSendResponse(w, r, httpStatus, resp, sendResult)
 		if metricsRegistry != nil {
 			metricsRegistry.ObserveRequest(resp.Status, resp.Reason, time.Since(start))
 		}
@@ -119,7 +128,7 @@ func writePushResponse(w http.ResponseWriter, status int, resp gateway.PushRespo
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.Printf("encode response: %v", err)
+		slog.Error("encode response", "error", err)
 	}
 }
 
@@ -138,14 +147,14 @@ func writePushSendResponse(w http.ResponseWriter, r *http.Request, status int, r
 func writePushResponseFragment(w http.ResponseWriter, status int, resp gateway.PushResponse, tmpl *template.Template) {
 	fragment, err := executeTemplate(tmpl, "send_result.tmpl", resp)
 	if err != nil {
-		log.Printf("render send result: %v", err)
+		slog.Error("render send result", "error", err)
 		http.Error(w, "render error", http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(status)
 	if _, err := w.Write(fragment); err != nil {
-		log.Printf("write send result: %v", err)
+		slog.Error("write send result", "error", err)
 	}
 }
 
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"html/template"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -58,28 +58,28 @@ func loadUITemplates(uiDir string) (uiTemplates, error) {
 func renderFragment(w http.ResponseWriter, tmpl *template.Template, name string, data any) {
 	fragment, err := executeTemplate(tmpl, name, data)
 	if err != nil {
-		log.Printf("render fragment: %v", err)
+		slog.Error("render fragment", "error", err)
 		http.Error(w, "render error", http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if _, err := w.Write(fragment); err != nil {
-		log.Printf("write fragment: %v", err)
+		slog.Error("write fragment", "error", err)
 	}
 }
 
 func renderShell(w http.ResponseWriter, fragment []byte, title string) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if _, err := io.WriteString(w, "<!doctype html><html lang=\"en\"><head><meta charset=\"utf-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1\"><link rel=\"stylesheet\" href=\"/ui/static/ui.css\"><title>"+title+"</title></head><body><div class=\"topbar\"><div class=\"topbar-brand\"><svg class=\"topbar-logo\" viewBox=\"0 0 48 24\" aria-hidden=\"true\" focusable=\"false\"><path d=\"M2 18c6-10 12-14 22-14s16 4 22 14\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"/><path d=\"M8 18v-6M40 18v-6M16 18v-4M32 18v-4\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"/><path d=\"M2 18h44\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"/></svg><span class=\"topbar-title\">Setu</span></div></div><div id=\"ui-root\">"); err != nil {
-		log.Printf("write shell start: %v", err)
+		slog.Error("write shell start", "error", err)
 		return
 	}
 	if _, err := w.Write(fragment); err != nil {
-		log.Printf("write shell fragment: %v", err)
+		slog.Error("write shell fragment", "error", err)
 		return
 	}
 	if _, err := io.WriteString(w, "</div><script src=\"/ui/static/htmx.min.js\"></script><script src=\"/ui/static/json-enc.js\"></script></body></html>"); err != nil {
-		log.Printf("write shell end: %v", err)
+		slog.Error("write shell end", "error", err)
 	}
 }
 
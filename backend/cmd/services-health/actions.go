@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"strings"
@@ -71,7 +71,7 @@ func (u *uiServer) runAction(serviceID, instanceName, configInput, addrInput str
 		if err := waitForHealthUp(healthURL, startWaitTimeout, exitCh); err != nil {
 			return actionResult{notice: formatStartError(err, output.String()), serviceID: serviceID, instanceName: instanceName}
 		}
-		log.Printf("started %s/%s: %s", service.ID, instance.Name, strings.Join(cmdArgs, " "))
+		slog.Info("started", "serviceId", service.ID, "instance", instance.Name, "command", strings.Join(cmdArgs, " "))
 		return actionResult{notice: fmt.Sprintf("started %s (%s)", service.Label, instance.Name), serviceID: service.ID, instanceName: instance.Name, desiredUp: boolPtr(true)}
 	}
 	if err := cmd.Run(); err != nil {
@@ -84,7 +84,7 @@ func (u *uiServer) runAction(serviceID, instanceName, configInput, addrInput str
 	if err := waitForHealthDown(healthURL, startWaitTimeout); err != nil {
 		return actionResult{notice: fmt.Sprintf("stop failed: %v", err), serviceID: serviceID, instanceName: instanceName}
 	}
-	log.Printf("stopped %s/%s: %s", service.ID, instance.Name, strings.Join(cmdArgs, " "))
+	slog.Info("stopped", "serviceId", service.ID, "instance", instance.Name, "command", strings.Join(cmdArgs, " "))
 	return actionResult{notice: fmt.Sprintf("stopped %s (%s)", service.Label, instance.Name), serviceID: service.ID, instanceName: instance.Name, desiredUp: boolPtr(false)}
 }
 
@@ -2,29 +2,38 @@ package main
 
 import (
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+
+	"gateway/logging"
 )
 
 var configPath = flag.String("config", defaultConfigPath, "Services health config file path")
 var listenAddr = flag.String("addr", defaultListenAddr, "HTTP listen address")
+var logFlags = logging.RegisterFlags(flag.CommandLine)
 
 func main() {
 	flag.Parse()
+	if _, err := logFlags.Setup("services-health"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	cfg, err := loadConfig(*configPath)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("load config", "error", err, "configPath", *configPath)
 	}
 
 	ui, err := newUIServer(cfg)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("construct ui", "error", err)
 	}
 
 	mux := newMux(ui)
-	log.Printf("services health listening on %s configPath=%q", *listenAddr, *configPath)
+	slog.Info("listening", "addr", *listenAddr, "configPath", *configPath)
 	if err := http.ListenAndServe(*listenAddr, mux); err != nil {
-		log.Fatal(err)
+		logging.Fatal("server error", "error", err)
 	}
 }
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"gateway"
+	"gateway/logging"
 	"gateway/metrics"
-	"log"
+	"gateway/tracing"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -18,6 +21,9 @@ var configPath = flag.String("config", "conf/sms/config.json", "Gateway config f
 var listenAddr = flag.String("addr", ":8080", "HTTP listen address")
 var showHelp = flag.Bool("help", false, "show usage")
 var showVersion = flag.Bool("version", false, "show version")
+var logFlags = logging.RegisterFlags(flag.CommandLine)
+var traceExporter = flag.String("trace-exporter", "none", "Span exporter: none, stdout, or file")
+var traceFile = flag.String("trace-file", "", "Span output path when -trace-exporter=file")
 
 const version = "0.1.0"
 
@@ -28,13 +34,17 @@ func main() {
 		return
 	}
 	if *showVersion {
-		log.Printf("gateway version %s", version)
+		fmt.Printf("gateway version %s\n", version)
 		return
 	}
+	if _, err := logFlags.Setup("sms-gateway"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	cfg, err := loadConfig(*configPath)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("load config", "error", err, "configPath", *configPath)
 	}
 
 	startTime := time.Now()
@@ -43,7 +53,12 @@ func main() {
 	providerConnectTimeout := time.Duration(cfg.SMSProviderConnectTimeoutSeconds) * time.Second
 	providerCall, providerName, err := providerFromConfig(cfg, providerConnectTimeout)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("configure provider", "error", err)
+	}
+
+	exporter, err := tracing.NewExporter(*traceExporter, *traceFile)
+	if err != nil {
+		logging.Fatal("configure trace exporter", "error", err)
 	}
 
 	metricsRegistry := metrics.New(providerName, latencyBuckets)
@@ -51,14 +66,15 @@ func main() {
 		ProviderCall:    providerCall,
 		ProviderTimeout: providerTimeout,
 		Metrics:         metricsRegistry,
+		Tracer:          tracing.NewTracer("sms-gateway", exporter),
 	})
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("construct gateway", "error", err)
 	}
 
 	ui, err := newUIServer(providerName, providerTimeout, cfg.GrafanaDashboardURL, metricsRegistry, startTime)
 	if err != nil {
-		log.Printf("ui disabled: %v", err)
+		slog.Warn("ui disabled", "error", err)
 	}
 
 	server := &http.Server{
@@ -74,25 +90,25 @@ func main() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 
-	log.Printf(
-		"listening on %s configPath=%q smsProvider=%q smsProviderUrl=%q smsProviderTimeoutSeconds=%d smsProviderConnectTimeoutSeconds=%d grafanaDashboardUrl=%q",
-		*listenAddr,
-		*configPath,
-		cfg.SMSProvider,
-		cfg.SMSProviderURL,
-		cfg.SMSProviderTimeoutSeconds,
-		cfg.SMSProviderConnectTimeoutSeconds,
-		cfg.GrafanaDashboardURL,
+	slog.Info(
+		"listening",
+		"addr", *listenAddr,
+		"configPath", *configPath,
+		"smsProvider", cfg.SMSProvider,
+		"smsProviderUrl", cfg.SMSProviderURL,
+		"smsProviderTimeoutSeconds", cfg.SMSProviderTimeoutSeconds,
+		"smsProviderConnectTimeoutSeconds", cfg.SMSProviderConnectTimeoutSeconds,
+		"grafanaDashboardUrl", cfg.GrafanaDashboardURL,
 	)
 
 	select {
 	case err := <-errCh:
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatal(err)
+			logging.Fatal("server error", "error", err)
 		}
 		return
 	case sig := <-sigCh:
-		log.Printf("shutdown signal: %s", sig)
+		slog.Info("shutdown signal", "signal", sig.String())
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -100,11 +116,11 @@ func main() {
 
 	// Allow in-flight requests to finish before exit.
 	if err := server.Shutdown(ctx); err != nil {
-		log.Printf("shutdown error: %v", err)
+		slog.Error("shutdown error", "error", err)
 	}
 
 	err = <-errCh
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
-		log.Printf("server error: %v", err)
+		slog.Error("server error", "error", err)
 	}
 }
@@ -13,6 +13,7 @@ import (
 
 	"gateway"
 	"gateway/adapter"
+	"gateway/tracing"
 )
 
 func TestLoadConfigAllowsHashComments(t *testing.T) {
@@ -235,3 +236,64 @@ func TestHandleSMSSendInvalidJSONReturns200(t *testing.T) {
 		t.Fatalf("unexpected response: %+v", resp)
 	}
 }
+
+func TestHandleSMSSendProviderThrottledReturns429(t *testing.T) {
+	gw, err := gateway.New(gateway.Config{
+		ProviderCall: func(ctx context.Context, req gateway.SMSRequest) (gateway.ProviderResult, error) {
+			return gateway.ProviderResult{}, gateway.ProviderThrottledError{RetryAfter: 1500 * time.Millisecond}
+		},
+		ProviderTimeout: 15 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("new gateway: %v", err)
+	}
+
+	handler := handleSMSSend(gw, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/sms/send", strings.NewReader(`{"referenceId":"ref-1","to":"15551234567","message":"hello"}`))
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("expected Retry-After 2, got %q", got)
+	}
+
+	var resp gateway.SMSResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Status != "rejected" || resp.Reason != "provider_failure" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestHandleSMSSendContinuesIncomingTrace(t *testing.T) {
+	incoming := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	var providerTrace tracing.SpanContext
+	gw, err := gateway.New(gateway.Config{
+		ProviderCall: func(ctx context.Context, req gateway.SMSRequest) (gateway.ProviderResult, error) {
+			providerTrace, _ = tracing.SpanContextFromContext(ctx)
+			return gateway.ProviderResult{Status: "accepted"}, nil
+		},
+		ProviderTimeout: 15 * time.Second,
+		Tracer:          tracing.NewTracer("sms-gateway", nil),
+	})
+	if err != nil {
+		t.Fatalf("new gateway: %v", err)
+	}
+
+	handler := handleSMSSend(gw, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/sms/send", strings.NewReader(`{"referenceId":"ref-1","to":"15551234567","message":"hello"}`))
+	req.Header.Set("traceparent", incoming)
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+
+	if providerTrace.TraceIDString() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected provider call in incoming trace, got %q", providerTrace.TraceIDString())
+	}
+	if providerTrace.SpanIDString() == "00f067aa0ba902b7" {
+		t.Fatal("expected provider call to run in a child span")
+	}
+}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -68,7 +68,7 @@ func (u *uiServer) renderPage(w http.ResponseWriter, r *http.Request, tmpl *temp
 	}
 	fragment, err := executeTemplate(tmpl, name, data)
 	if err != nil {
-		log.Printf("render page: %v", err)
+		slog.Error("render page", "error", err)
 		http.Error(w, "render error", http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,4 @@
+package main
+
+// Links the pgx database/sql driver for -store=postgres.
+import _ "github.com/jackc/pgx/v5/stdlib"
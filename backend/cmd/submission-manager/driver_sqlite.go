@@ -0,0 +1,4 @@
+package main
+
+// Links the pure-Go SQLite driver for -store=sqlite.
+import _ "modernc.org/sqlite"
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"gateway/submission"
+)
+
+const (
+	defaultEndpointFailureThreshold = 3
+	defaultEndpointCooldown         = 30 * time.Second
+)
+
+// endpointHealth tracks consecutive transport failures per gateway endpoint. An endpoint that reaches
+// the threshold is unhealthy until the cooldown passes; attempts try it only after healthy ones.
+type endpointHealth struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	failures  map[string]int
+	downUntil map[string]time.Time
+	// cursors holds the round-robin position per endpoint list.
+	cursors map[string]int
+}
+
+func newEndpointHealth(threshold int, cooldown time.Duration) *endpointHealth {
+	return &endpointHealth{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		failures:  make(map[string]int),
+		downUntil: make(map[string]time.Time),
+		cursors:   make(map[string]int),
+	}
+}
+
+// order returns the endpoints to try for one attempt: in listed order for failover, rotated by one
+// per attempt for round_robin, with unhealthy endpoints moved to the end as a last resort.
+func (h *endpointHealth) order(urls []string, strategy submission.EndpointStrategy) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	start := 0
+	if strategy == submission.EndpointRoundRobin && len(urls) > 1 {
+		key := strings.Join(urls, " ")
+		start = h.cursors[key] % len(urls)
+		h.cursors[key] = start + 1
+	}
+	now := h.now()
+	healthy := make([]string, 0, len(urls))
+	var unhealthy []string
+	for i := range urls {
+		url := urls[(start+i)%len(urls)]
+		if now.Before(h.downUntil[url]) {
+			unhealthy = append(unhealthy, url)
+			continue
+		}
+		healthy = append(healthy, url)
+	}
+	return append(healthy, unhealthy...)
+}
+
+// reportFailure records a transport failure of url and marks it unhealthy at the threshold.
+func (h *endpointHealth) reportFailure(url string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures[url]++
+	if h.failures[url] < h.threshold {
+		return
+	}
+	now := h.now()
+	if !now.Before(h.downUntil[url]) {
+		slog.Warn("gateway_endpoint_unhealthy", "gatewayUrl", url, "consecutiveFailures", h.failures[url], "cooldown", h.cooldown.String())
+	}
+	h.downUntil[url] = now.Add(h.cooldown)
+}
+
+// reportSuccess records that url answered, which makes it healthy again.
+func (h *endpointHealth) reportSuccess(url string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, down := h.downUntil[url]; down {
+		slog.Info("gateway_endpoint_healthy", "gatewayUrl", url)
+	}
+	delete(h.failures, url)
+	delete(h.downUntil, url)
+}
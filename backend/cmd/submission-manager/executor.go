@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
+	"gateway"
 	"gateway/submission"
 	"gateway/submissionmanager"
+	"gateway/tracing"
 )
 
 type gatewayResponse struct {
@@ -19,50 +25,137 @@ type gatewayResponse struct {
 }
 
 func newGatewayExecutor(client *http.Client) submissionmanager.AttemptExecutor {
+	return newGatewayExecutorWithHealth(client, newEndpointHealth(defaultEndpointFailureThreshold, defaultEndpointCooldown))
+}
+
+// newGatewayExecutorWithHealth returns an executor that moves to the next endpoint of a
+// multi-endpoint target when an endpoint cannot be connected to, tracking endpoint health in health.
+func newGatewayExecutorWithHealth(client *http.Client, health *endpointHealth) submissionmanager.AttemptExecutor {
 	if client == nil {
 		client = http.DefaultClient
 	}
-	return func(ctx context.Context, input submissionmanager.AttemptInput) (submissionmanager.GatewayOutcome, error) {
-		endpoint, err := gatewayEndpoint(input.GatewayType, input.GatewayURL)
-		if err != nil {
-			return submissionmanager.GatewayOutcome{}, err
+	return func(ctx context.Context, input submissionmanager.AttemptInput) (outcome submissionmanager.GatewayOutcome, err error) {
+		if input.Timeout > 0 {
+			parent := ctx
+			attemptCtx, cancel := context.WithTimeout(parent, input.Timeout)
+			defer cancel()
+			// Non-obvious constraint: only the attempt deadline is a timeout; parent cancellation (lease loss,
+			// shutdown) is reported as-is.
+			defer func() {
+				if err != nil && parent.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+					outcome = submissionmanager.GatewayOutcome{}
+					err = submissionmanager.AttemptTimeoutError{Timeout: input.Timeout}
+				}
+			}()
+			ctx = attemptCtx
 		}
+
 		payload := input.Payload
 		if payload == nil {
 			payload = []byte{}
 		}
-
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
-		if err != nil {
-			return submissionmanager.GatewayOutcome{}, err
+		urls := input.GatewayURLs
+		if len(urls) == 0 {
+			urls = []string{input.GatewayURL}
 		}
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := client.Do(req)
-		if err != nil {
-			return submissionmanager.GatewayOutcome{}, err
+		ordered := health.order(urls, input.EndpointStrategy)
+		for i, baseURL := range ordered {
+			endpoint, err := gatewayEndpoint(input.GatewayType, baseURL)
+			if err != nil {
+				return submissionmanager.GatewayOutcome{}, err
+			}
+			outcome, unsent, err := postGateway(ctx, client, endpoint, payload)
+			if !isTransportError(err) {
+				health.reportSuccess(baseURL)
+				return outcome, err
+			}
+			// A cancelled attempt (timeout, lease loss) says nothing about the endpoint.
+			if ctx.Err() != nil {
+				return submissionmanager.GatewayOutcome{}, err
+			}
+			health.reportFailure(baseURL)
+			// Non-obvious constraint: a transport error after the connection was made may follow a send the
+			// gateway accepted, and gateway deployments do not share referenceId dedup, so only a request
+			// that never left moves to another endpoint. Anything else is the attempt's result.
+			if !unsent {
+				return submissionmanager.GatewayOutcome{}, err
+			}
+			if i == len(ordered)-1 {
+				if len(ordered) > 1 {
+					err = classifiedError(submission.ErrorClassTransport, fmt.Errorf("all %d gateway endpoints failed, last: %w", len(ordered), errors.Unwrap(err)))
+				}
+				return submissionmanager.GatewayOutcome{}, err
+			}
+			slog.Warn("gateway_endpoint_failover", "gatewayUrl", baseURL, "nextGatewayUrl", ordered[i+1], "error", err.Error())
 		}
-		defer resp.Body.Close()
+		return submissionmanager.GatewayOutcome{}, errors.New("gateway url is required")
+	}
+}
 
-		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-			_, _ = io.Copy(io.Discard, resp.Body)
-			return submissionmanager.GatewayOutcome{}, fmt.Errorf("gateway returned status %d", resp.StatusCode)
-		}
+// postGateway sends payload to one endpoint. unsent reports that the connection could not be
+// established (a dial or DNS failure), so the request never reached the gateway.
+func postGateway(ctx context.Context, client *http.Client, endpoint string, payload []byte) (submissionmanager.GatewayOutcome, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return submissionmanager.GatewayOutcome{}, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return submissionmanager.GatewayOutcome{}, isDialError(err), classifiedError(submission.ErrorClassTransport, err)
+	}
+	defer resp.Body.Close()
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return submissionmanager.GatewayOutcome{}, err
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		class := submission.ErrorClassGateway4xx
+		if resp.StatusCode >= http.StatusInternalServerError {
+			class = submission.ErrorClassGateway5xx
 		}
-		var gatewayResp gatewayResponse
-		if err := json.Unmarshal(body, &gatewayResp); err != nil {
-			return submissionmanager.GatewayOutcome{}, fmt.Errorf("decode gateway response: %w", err)
+		// Flow intent: 429 and 503 are backpressure; the manager delays the retry by Retry-After.
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			return submissionmanager.GatewayOutcome{}, false, classifiedError(class, submissionmanager.GatewayBackpressureError{
+				StatusCode: resp.StatusCode,
+				RetryAfter: gateway.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			})
 		}
+		return submissionmanager.GatewayOutcome{}, false, classifiedError(class, fmt.Errorf("gateway returned status %d", resp.StatusCode))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return submissionmanager.GatewayOutcome{}, false, classifiedError(submission.ErrorClassTransport, err)
+	}
+	var gatewayResp gatewayResponse
+	if err := json.Unmarshal(body, &gatewayResp); err != nil {
+		return submissionmanager.GatewayOutcome{}, false, classifiedError(submission.ErrorClassDecode, fmt.Errorf("decode gateway response: %w", err))
+	}
+
+	return submissionmanager.GatewayOutcome{
+		Status: strings.TrimSpace(gatewayResp.Status),
+		Reason: strings.TrimSpace(gatewayResp.Reason),
+	}, false, nil
+}
 
-		return submissionmanager.GatewayOutcome{
-			Status: strings.TrimSpace(gatewayResp.Status),
-			Reason: strings.TrimSpace(gatewayResp.Reason),
-		}, nil
+// isDialError reports whether err happened while connecting, before any request bytes were written.
+func isDialError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
 	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func isTransportError(err error) bool {
+	var classified submissionmanager.AttemptError
+	return errors.As(err, &classified) && classified.Class == submission.ErrorClassTransport
+}
+
+func classifiedError(class submission.AttemptErrorClass, err error) error {
+	return submissionmanager.AttemptError{Class: class, Err: err}
 }
 
 func gatewayEndpoint(gatewayType submission.GatewayType, baseURL string) (string, error) {
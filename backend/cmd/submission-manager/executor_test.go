@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"gateway/submission"
 	"gateway/submissionmanager"
+	"gateway/tracing"
 )
 
 func TestExecutorParsesOutcomeOn2xx(t *testing.T) {
@@ -56,6 +59,55 @@ func TestExecutorTreatsNon2xxAsError(t *testing.T) {
 	if !strings.Contains(err.Error(), "status 500") {
 		t.Fatalf("expected status in error, got %v", err)
 	}
+	var classified submissionmanager.AttemptError
+	if !errors.As(err, &classified) || classified.Class != submission.ErrorClassGateway5xx {
+		t.Fatalf("expected gateway_5xx class, got %v", err)
+	}
+}
+
+func TestExecutorReportsBackpressure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	exec := newGatewayExecutor(server.Client())
+	_, err := exec(context.Background(), submissionmanager.AttemptInput{
+		GatewayType: submission.GatewaySMS,
+		GatewayURL:  server.URL,
+		Payload:     []byte(`{"referenceId":"ref-1"}`),
+	})
+	var backpressure submissionmanager.GatewayBackpressureError
+	if !errors.As(err, &backpressure) {
+		t.Fatalf("expected backpressure error, got %v", err)
+	}
+	if backpressure.StatusCode != http.StatusTooManyRequests || backpressure.RetryAfter != 30*time.Second {
+		t.Fatalf("unexpected backpressure: %+v", backpressure)
+	}
+	var classified submissionmanager.AttemptError
+	if !errors.As(err, &classified) || classified.Class != submission.ErrorClassGateway4xx {
+		t.Fatalf("expected gateway_4xx class, got %v", err)
+	}
+}
+
+func TestExecutorClassifiesDecodeErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("not json"))
+	}))
+	defer server.Close()
+
+	exec := newGatewayExecutor(server.Client())
+	_, err := exec(context.Background(), submissionmanager.AttemptInput{
+		GatewayType: submission.GatewaySMS,
+		GatewayURL:  server.URL,
+		Payload:     []byte(`{"referenceId":"ref-1"}`),
+	})
+	var classified submissionmanager.AttemptError
+	if !errors.As(err, &classified) || classified.Class != submission.ErrorClassDecode {
+		t.Fatalf("expected decode class, got %v", err)
+	}
 }
 
 func TestExecutorRejectsMalformedJSON(t *testing.T) {
@@ -78,3 +130,188 @@ func TestExecutorRejectsMalformedJSON(t *testing.T) {
 		t.Fatalf("expected decode error, got %v", err)
 	}
 }
+
+func TestExecutorReportsAttemptTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	exec := newGatewayExecutor(server.Client())
+	_, err := exec(context.Background(), submissionmanager.AttemptInput{
+		GatewayType: submission.GatewaySMS,
+		GatewayURL:  server.URL,
+		Payload:     []byte(`{"referenceId":"ref-1"}`),
+		Timeout:     50 * time.Millisecond,
+	})
+	var timeout submissionmanager.AttemptTimeoutError
+	if !errors.As(err, &timeout) {
+		t.Fatalf("expected attempt timeout error, got %v", err)
+	}
+	if timeout.Timeout != 50*time.Millisecond {
+		t.Fatalf("expected timeout 50ms, got %s", timeout.Timeout)
+	}
+}
+
+func TestExecutorPropagatesTraceContext(t *testing.T) {
+	var gotTraceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"accepted"}`))
+	}))
+	defer server.Close()
+
+	parent, _ := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "")
+	exec := newGatewayExecutor(server.Client())
+	_, err := exec(tracing.ContextWithSpanContext(context.Background(), parent), submissionmanager.AttemptInput{
+		GatewayType: submission.GatewaySMS,
+		GatewayURL:  server.URL,
+		Payload:     []byte(`{"referenceId":"ref-1"}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotTraceparent != parent.Traceparent() {
+		t.Fatalf("expected traceparent %q, got %q", parent.Traceparent(), gotTraceparent)
+	}
+}
+
+func TestExecutorFailsOverOnTransportError(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"accepted"}`))
+	}))
+	defer server.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	health := newEndpointHealth(2, time.Minute)
+	exec := newGatewayExecutorWithHealth(server.Client(), health)
+	input := submissionmanager.AttemptInput{
+		GatewayType:      submission.GatewaySMS,
+		GatewayURL:       downURL,
+		GatewayURLs:      []string{downURL, server.URL},
+		EndpointStrategy: submission.EndpointFailover,
+		Payload:          []byte(`{"referenceId":"ref-1"}`),
+	}
+	for i := 0; i < 2; i++ {
+		outcome, err := exec(context.Background(), input)
+		if err != nil || outcome.Status != "accepted" {
+			t.Fatalf("attempt %d: expected failover to accept, got %+v, %v", i+1, outcome, err)
+		}
+	}
+	if hits != 2 {
+		t.Fatalf("expected 2 requests on the healthy endpoint, got %d", hits)
+	}
+	// After two transport failures the first endpoint is unhealthy and tried last.
+	if order := health.order(input.GatewayURLs, submission.EndpointFailover); order[0] != server.URL {
+		t.Fatalf("expected unhealthy endpoint moved to the end, got %v", order)
+	}
+
+	// Gateway responses do not fail over: a 5xx is the attempt's result.
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	input.GatewayURLs = []string{failing.URL, server.URL}
+	if _, err := exec(context.Background(), input); err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Fatalf("expected status 500 without failover, got %v", err)
+	}
+}
+
+func TestExecutorDoesNotFailOverAfterSending(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"accepted"}`))
+	}))
+	defer server.Close()
+	// The request reaches this gateway, which drops the connection without answering.
+	var dropped int
+	dropping := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dropped++
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		_ = conn.Close()
+	}))
+	defer dropping.Close()
+
+	exec := newGatewayExecutor(server.Client())
+	_, err := exec(context.Background(), submissionmanager.AttemptInput{
+		GatewayType:      submission.GatewaySMS,
+		GatewayURL:       dropping.URL,
+		GatewayURLs:      []string{dropping.URL, server.URL},
+		EndpointStrategy: submission.EndpointFailover,
+		Payload:          []byte(`{"referenceId":"ref-1"}`),
+	})
+	var classified submissionmanager.AttemptError
+	if !errors.As(err, &classified) || classified.Class != submission.ErrorClassTransport {
+		t.Fatalf("expected transport error, got %v", err)
+	}
+	if dropped != 1 || hits != 0 {
+		t.Fatalf("expected one send and no failover, got dropped=%d hits=%d", dropped, hits)
+	}
+}
+
+func TestExecutorReportsAllEndpointsFailed(t *testing.T) {
+	var urls []string
+	for i := 0; i < 2; i++ {
+		down := httptest.NewServer(http.NotFoundHandler())
+		urls = append(urls, down.URL)
+		down.Close()
+	}
+	exec := newGatewayExecutor(http.DefaultClient)
+	_, err := exec(context.Background(), submissionmanager.AttemptInput{
+		GatewayType: submission.GatewaySMS,
+		GatewayURL:  urls[0],
+		GatewayURLs: urls,
+		Payload:     []byte(`{"referenceId":"ref-1"}`),
+	})
+	var classified submissionmanager.AttemptError
+	if !errors.As(err, &classified) || classified.Class != submission.ErrorClassTransport {
+		t.Fatalf("expected transport error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "all 2 gateway endpoints failed") {
+		t.Fatalf("expected all endpoints failed, got %v", err)
+	}
+}
+
+func TestEndpointHealthOrder(t *testing.T) {
+	now := time.Unix(0, 0)
+	health := newEndpointHealth(1, time.Minute)
+	health.now = func() time.Time { return now }
+	urls := []string{"http://a", "http://b", "http://c"}
+
+	for i, want := range []string{"http://a", "http://b", "http://c", "http://a"} {
+		if got := health.order(urls, submission.EndpointRoundRobin); got[0] != want || len(got) != 3 {
+			t.Fatalf("round robin call %d: expected %s first, got %v", i+1, want, got)
+		}
+	}
+
+	health.reportFailure("http://a")
+	if got := health.order(urls, submission.EndpointFailover); strings.Join(got, ",") != "http://b,http://c,http://a" {
+		t.Fatalf("expected unhealthy endpoint last, got %v", got)
+	}
+	now = now.Add(time.Minute)
+	if got := health.order(urls, submission.EndpointFailover); got[0] != "http://a" {
+		t.Fatalf("expected endpoint healthy after cooldown, got %v", got)
+	}
+	health.reportFailure("http://b")
+	health.reportSuccess("http://b")
+	if got := health.order(urls, submission.EndpointFailover); got[1] != "http://b" {
+		t.Fatalf("expected success to clear unhealthy state, got %v", got)
+	}
+}
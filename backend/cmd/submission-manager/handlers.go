@@ -1,19 +1,27 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"gateway/submissionmanager"
+	"gateway/tracing"
 )
 
 type apiServer struct {
-	manager *submissionmanager.Manager
+	manager  *submissionmanager.Manager
+	tracer   *tracing.Tracer
+	stepDown func(context.Context) []int
+	schedule func(context.Context, int) (submissionmanager.ScheduleView, error)
+	// drainRetryAfter is the Retry-After sent with a 503 for a new intent while draining.
+	drainRetryAfter time.Duration
 }
 
 func handleMetrics(metrics *submissionmanager.Metrics) http.HandlerFunc {
@@ -40,7 +48,9 @@ func handleHealthz(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte("ok"))
 }
 
-func handleReadyz(statusFn func() submissionmanager.LeaseStatus) http.HandlerFunc {
+// handleReadyz reports the lease role. While draining it returns 503 so load balancers stop
+// routing new traffic to the instance.
+func handleReadyz(statusFn func() submissionmanager.LeaseStatus, drainFn func() submissionmanager.DrainState) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -54,6 +64,18 @@ func handleReadyz(statusFn func() submissionmanager.LeaseStatus) http.HandlerFun
 		if status.Mode == "leader" && !status.ExpiresAt.IsZero() {
 			body = fmt.Sprintf("%s lease_expires_at=%s", body, status.ExpiresAt.UTC().Format(time.RFC3339Nano))
 		}
+		if len(status.Partitions) > 0 {
+			held := make([]string, 0, len(status.Partitions))
+			for _, partition := range status.Partitions {
+				held = append(held, strconv.Itoa(partition.Partition))
+			}
+			body = fmt.Sprintf("%s partitions=%s", body, strings.Join(held, ","))
+		}
+		if drainFn != nil && drainFn().Draining {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(body + " draining=true"))
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(body))
 	}
@@ -66,8 +88,7 @@ func (s *apiServer) handleSubmit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Non-obvious constraint: waitSeconds only controls how long we wait; it must not change idempotency or storage.
-	wait, err := parseWaitSeconds(r.URL.Query().Get("waitSeconds"))
+	wait, until, err := parseWaitQuery(r)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
 		return
@@ -85,17 +106,32 @@ func (s *apiServer) handleSubmit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	intent := submissionmanager.Intent{
-		IntentID:         strings.TrimSpace(req.IntentID),
-		SubmissionTarget: strings.TrimSpace(req.SubmissionTarget),
-		Payload:          req.Payload,
+		IntentID:          strings.TrimSpace(req.IntentID),
+		SubmissionTarget:  strings.TrimSpace(req.SubmissionTarget),
+		Payload:           req.Payload,
+		CallbackURL:       strings.TrimSpace(req.CallbackURL),
+		CallbackSecretEnv: strings.TrimSpace(req.CallbackSecretEnv),
 	}
 	if intent.IntentID == "" || intent.SubmissionTarget == "" {
 		writeError(w, http.StatusBadRequest, "invalid_request", "intentId and submissionTarget are required", nil)
 		return
 	}
 
-	stored, err := s.manager.SubmitIntent(r.Context(), intent)
+	ctx, span := s.tracer.Start(tracing.Extract(r.Context(), r.Header), "submission.submit")
+	// Non-obvious constraint: the span ends with spanErr, so every failed submit is recorded on it.
+	var spanErr error
+	defer func() { span.End(spanErr) }()
+	span.SetAttribute("intentId", intent.IntentID)
+	span.SetAttribute("submissionTarget", intent.SubmissionTarget)
+	// Non-obvious constraint: attempts run later, possibly on another instance, so the trace context is stored on the intent.
+	if sc, ok := tracing.SpanContextFromContext(ctx); ok {
+		intent.TraceParent = sc.Traceparent()
+		intent.TraceState = sc.TraceState
+	}
+
+	stored, err := s.manager.SubmitIntent(ctx, intent)
 	if err != nil {
+		spanErr = err
 		var conflict submissionmanager.IdempotencyConflictError
 		if errors.As(err, &conflict) {
 			writeError(w, http.StatusConflict, "idempotency_conflict", "intentId already exists with different payload", map[string]string{
@@ -112,13 +148,32 @@ func (s *apiServer) handleSubmit(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
+		var draining submissionmanager.DrainingError
+		if errors.As(err, &draining) {
+			writeDraining(w, s.drainRetryAfter, draining)
+			return
+		}
+		var admission submissionmanager.AdmissionRejectedError
+		if errors.As(err, &admission) {
+			writeAdmissionRejected(w, admission)
+			return
+		}
+		var callback submissionmanager.CallbackNotAllowedError
+		if errors.As(err, &callback) {
+			writeError(w, http.StatusBadRequest, "callback_not_allowed", callback.Reason, map[string]string{
+				"submissionTarget": callback.SubmissionTarget,
+				"callbackUrl":      callback.CallbackURL,
+			})
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "internal_error", "internal error", nil)
 		return
 	}
 
 	if wait > 0 {
-		waited, ok, err := s.manager.WaitForIntent(r.Context(), stored.IntentID, wait)
+		waited, ok, err := s.manager.WaitForIntentUntil(ctx, stored.IntentID, wait, until)
 		if err != nil {
+			spanErr = err
 			writeError(w, http.StatusInternalServerError, "internal_error", "internal error", nil)
 			return
 		}
@@ -134,18 +189,23 @@ func (s *apiServer) handleSubmit(w http.ResponseWriter, r *http.Request) {
 
 func (s *apiServer) handleGet(w http.ResponseWriter, r *http.Request) {
 	// Flow intent: find intent or history and return it.
+	path := strings.TrimPrefix(r.URL.Path, "/v1/intents/")
+	path = strings.Trim(path, "/")
+	parts := strings.Split(path, "/")
+	// Non-obvious constraint: redelivery is the only POST under /v1/intents/{id}; route it before the GET check.
+	if len(parts) == 2 && parts[1] == "webhook:redeliver" {
+		s.handleWebhookRedeliver(w, r, strings.TrimSpace(parts[0]))
+		return
+	}
+
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", nil)
 		return
 	}
-
-	path := strings.TrimPrefix(r.URL.Path, "/v1/intents/")
-	path = strings.Trim(path, "/")
 	if path == "" {
 		writeError(w, http.StatusBadRequest, "invalid_request", "intentId is required", nil)
 		return
 	}
-	parts := strings.Split(path, "/")
 	if len(parts) == 2 && parts[1] == "history" {
 		intentID := strings.TrimSpace(parts[0])
 		if intentID == "" {
@@ -166,7 +226,17 @@ func (s *apiServer) handleGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	intent, ok := s.manager.GetIntent(intentID)
+	wait, until, err := parseWaitQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	intent, ok, err := s.manager.WaitForIntentUntil(r.Context(), intentID, wait, until)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal error", nil)
+		return
+	}
 	if !ok {
 		writeError(w, http.StatusNotFound, "not_found", "intent not found", map[string]string{"intentId": intentID})
 		return
@@ -175,6 +245,21 @@ func (s *apiServer) handleGet(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, toIntentResponse(intent))
 }
 
+// parseWaitQuery reads waitSeconds and waitFor, which only control how long a request waits; they
+// must not change idempotency or storage.
+func parseWaitQuery(r *http.Request) (time.Duration, submissionmanager.WaitCondition, error) {
+	query := r.URL.Query()
+	wait, err := parseWaitSeconds(query.Get("waitSeconds"))
+	if err != nil {
+		return 0, "", err
+	}
+	until, err := parseWaitFor(query.Get("waitFor"))
+	if err != nil {
+		return 0, "", err
+	}
+	return wait, until, nil
+}
+
 func (s *apiServer) handleHistory(w http.ResponseWriter, r *http.Request, intentID string) {
 	intent, ok := s.manager.GetIntent(intentID)
 	if !ok {
@@ -187,3 +272,116 @@ func (s *apiServer) handleHistory(w http.ResponseWriter, r *http.Request, intent
 	}
 	writeJSON(w, http.StatusOK, response)
 }
+
+func (s *apiServer) handleWebhookRedeliver(w http.ResponseWriter, r *http.Request, intentID string) {
+	// Flow intent: resend the terminal webhook and return the recorded redelivery result.
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", nil)
+		return
+	}
+	if intentID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "intentId is required", nil)
+		return
+	}
+
+	intent, ok, err := s.manager.RedeliverWebhook(r.Context(), intentID)
+	if err != nil {
+		var unavailable submissionmanager.WebhookRedeliveryError
+		if errors.As(err, &unavailable) {
+			writeError(w, http.StatusConflict, unavailable.Reason, "webhook cannot be redelivered", map[string]string{
+				"intentId": unavailable.IntentID,
+			})
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal error", nil)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "intent not found", map[string]string{"intentId": intentID})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toWebhookRedeliveryResponse(intent))
+}
+
+func (s *apiServer) handleStepDown(w http.ResponseWriter, r *http.Request) {
+	// Flow intent: release every held partition lease now; the process keeps serving HTTP as a follower.
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", nil)
+		return
+	}
+	if s.stepDown == nil {
+		writeError(w, http.StatusServiceUnavailable, "unavailable", "step-down not configured", nil)
+		return
+	}
+	released := s.stepDown(r.Context())
+	writeJSON(w, http.StatusOK, stepDownResponse{ReleasedPartitions: released})
+}
+
+func (s *apiServer) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	// Flow intent: read-only view of leases, queue depth, due and in-flight attempts, and lease history.
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", nil)
+		return
+	}
+	if s.schedule == nil {
+		writeError(w, http.StatusServiceUnavailable, "unavailable", "schedule view not configured", nil)
+		return
+	}
+	limit, err := parseScheduleLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+	view, err := s.schedule(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal error", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, toScheduleResponse(view))
+}
+
+func (s *apiServer) handleDrain(w http.ResponseWriter, r *http.Request) {
+	// Flow intent: read or flip the cluster-wide drain switch; other instances apply it on their next refresh.
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, toDrainResponse(s.manager.DrainState()))
+	case http.MethodPost:
+		dec := json.NewDecoder(r.Body)
+		var req drainRequest
+		if err := dec.Decode(&req); err != nil || req.Draining == nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "draining (boolean) is required", nil)
+			return
+		}
+		state, err := s.manager.SetDrain(r.Context(), *req.Draining, req.Reason)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal error", nil)
+			return
+		}
+		writeJSON(w, http.StatusOK, toDrainResponse(state))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", nil)
+	}
+}
+
+func writeDraining(w http.ResponseWriter, retryAfter time.Duration, draining submissionmanager.DrainingError) {
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+	details := map[string]string{"intentId": draining.IntentID}
+	if draining.Reason != "" {
+		details["reason"] = draining.Reason
+	}
+	writeError(w, http.StatusServiceUnavailable, "draining", "submission manager is draining; retry later", details)
+}
+
+func writeAdmissionRejected(w http.ResponseWriter, rejected submissionmanager.AdmissionRejectedError) {
+	seconds := int((rejected.RetryAfter + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+	writeError(w, http.StatusTooManyRequests, "admission_rejected", "too many pending intents; retry later", map[string]string{
+		"intentId":         rejected.IntentID,
+		"submissionTarget": rejected.SubmissionTarget,
+		"scope":            rejected.Scope,
+		"pending":          strconv.Itoa(rejected.Pending),
+		"limit":            strconv.Itoa(rejected.Limit),
+	})
+}
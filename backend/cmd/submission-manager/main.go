@@ -7,24 +7,31 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	_ "github.com/microsoft/go-mssqldb"
 
+	"gateway/logging"
 	"gateway/submission"
 	"gateway/submissionmanager"
+	"gateway/tracing"
 )
 
 var (
 	addrFlag                 = flag.String("addr", ":8082", "HTTP listen address")
 	registryPathFlag         = flag.String("registry", "conf/submission/submission_targets.json", "SubmissionTarget registry path")
+	storeFlag                = flag.String("store", envOrDefault("SM_STORE", "sqlserver"), "Persistence backend: sqlserver, postgres, or sqlite")
+	postgresDSNFlag          = flag.String("postgres-dsn", envOrDefault("SM_POSTGRES_DSN", ""), "PostgreSQL connection string when -store=postgres")
+	sqlitePathFlag           = flag.String("sqlite-path", envOrDefault("SM_SQLITE_PATH", "submission-manager.db"), "SQLite database file when -store=sqlite")
+	migrationsDirFlag        = flag.String("migrations-dir", envOrDefault("SM_MIGRATIONS_DIR", ""), "Schema migrations directory (defaults to the store's directory under conf/sql/submissionmanager)")
 	mssqlHostFlag            = flag.String("sql-host", envOrDefault("MSSQL_HOST", "localhost"), "SQL Server host")
 	mssqlPortFlag            = flag.String("sql-port", envOrDefault("MSSQL_PORT", "1433"), "SQL Server port")
 	mssqlUserFlag            = flag.String("sql-user", envOrDefault("MSSQL_USER", "sa"), "SQL Server user")
@@ -35,67 +42,161 @@ var (
 	leaseRenewIntervalFlag   = flag.String("lease-renew-interval", envOrDefault("SM_RENEW_INTERVAL", "20s"), "Leader lease renew interval (example: 20s)")
 	leaseAcquireIntervalFlag = flag.String("lease-acquire-interval", envOrDefault("SM_ACQUIRE_INTERVAL", "30s"), "Leader lease acquire interval (example: 30s)")
 	scheduleRefreshFlag      = flag.String("schedule-refresh-interval", envOrDefault("SM_SCHEDULE_REFRESH_INTERVAL", "1s"), "Schedule refresh interval (example: 1s)")
+	leasePartitionsFlag      = flag.String("lease-partitions", envOrDefault("SM_LEASE_PARTITIONS", "1"), "Number of executor lease partitions; must match on every instance")
 	leaseNameFlag            = flag.String("lease-name", envOrDefault("SM_LEASE_NAME", "submission-manager-executor"), "Leader lease name")
+	stepDownTimeoutFlag      = flag.String("step-down-timeout", envOrDefault("SM_STEP_DOWN_TIMEOUT", "30s"), "How long a step-down waits for in-flight attempts before releasing the lease (example: 30s)")
+	drainRefreshFlag         = flag.String("drain-refresh-interval", envOrDefault("SM_DRAIN_REFRESH_INTERVAL", "2s"), "How often drain mode is reloaded from SQL (example: 2s)")
+	drainRetryAfterFlag      = flag.String("drain-retry-after", envOrDefault("SM_DRAIN_RETRY_AFTER", "30s"), "Retry-After sent with 503 for new intents while draining (example: 30s)")
+	admissionMaxPendingFlag  = flag.String("admission-max-pending", envOrDefault("SM_ADMISSION_MAX_PENDING", "0"), "Global pending-intent limit above which new intents get 429; 0 disables it")
+	admissionRefreshFlag     = flag.String("admission-refresh-interval", envOrDefault("SM_ADMISSION_REFRESH_INTERVAL", "5s"), "How often pending-intent counts are reloaded from SQL for admission control (example: 5s)")
+	endpointFailuresFlag     = flag.String("endpoint-failure-threshold", envOrDefault("SM_ENDPOINT_FAILURE_THRESHOLD", "3"), "Consecutive transport failures after which a gateway endpoint is marked unhealthy")
+	endpointCooldownFlag     = flag.String("endpoint-cooldown", envOrDefault("SM_ENDPOINT_COOLDOWN", "30s"), "How long an unhealthy gateway endpoint is tried only after healthy ones (example: 30s)")
+	claimStrategyFlag        = flag.String("claim-strategy", envOrDefault("SM_CLAIM_STRATEGY", "retry"), "How a new partition holder resolves attempts the previous holder did not record: retry or review")
 	holderIDFlag             = flag.String("holder-id", envOrDefault("SM_HOLDER_ID", ""), "Leader holder id (defaults to hostname-pid-rand)")
+	webhookAllowedHostsFlag  = flag.String("webhook-allowed-hosts", envOrDefault("SM_WEBHOOK_ALLOWED_HOSTS", ""), "Comma-separated webhook host allowlist; *.suffix matches subdomains (empty allows any host)")
+	webhookAllowedCIDRsFlag  = flag.String("webhook-allowed-cidrs", envOrDefault("SM_WEBHOOK_ALLOWED_CIDRS", ""), "Comma-separated non-public CIDRs webhooks may reach")
+	webhookAllowPrivateFlag  = flag.String("webhook-allow-private", envOrDefault("SM_WEBHOOK_ALLOW_PRIVATE", "false"), "Allow webhooks to loopback, private, and link-local addresses")
+	traceExporterFlag        = flag.String("trace-exporter", envOrDefault("SM_TRACE_EXPORTER", "none"), "Span exporter: none, stdout, or file")
+	traceFileFlag            = flag.String("trace-file", envOrDefault("SM_TRACE_FILE", ""), "Span output path when -trace-exporter=file")
+	logFlags                 = logging.RegisterFlags(flag.CommandLine)
 )
 
 func main() {
 	flag.Parse()
+	if _, err := logFlags.Setup("submission-manager"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if flag.NArg() > 0 {
+		runCommand(flag.Args())
+		return
+	}
 
 	leaseDuration, err := parseDurationFlag("lease-duration", *leaseDurationFlag)
 	if err != nil {
-		log.Fatalf("parse lease-duration: %v", err)
+		logging.Fatal("parse lease-duration", "error", err)
 	}
 	renewInterval, err := parseDurationFlag("lease-renew-interval", *leaseRenewIntervalFlag)
 	if err != nil {
-		log.Fatalf("parse lease-renew-interval: %v", err)
+		logging.Fatal("parse lease-renew-interval", "error", err)
 	}
 	acquireInterval, err := parseDurationFlag("lease-acquire-interval", *leaseAcquireIntervalFlag)
 	if err != nil {
-		log.Fatalf("parse lease-acquire-interval: %v", err)
+		logging.Fatal("parse lease-acquire-interval", "error", err)
 	}
 	scheduleRefreshInterval, err := parseDurationFlag("schedule-refresh-interval", *scheduleRefreshFlag)
 	if err != nil {
-		log.Fatalf("parse schedule-refresh-interval: %v", err)
+		logging.Fatal("parse schedule-refresh-interval", "error", err)
 	}
 	if renewInterval >= leaseDuration {
-		log.Fatalf("lease-renew-interval must be less than lease-duration")
+		logging.Fatal("lease-renew-interval must be less than lease-duration")
+	}
+	leasePartitions, err := parseLeasePartitions(*leasePartitionsFlag)
+	if err != nil {
+		logging.Fatal("parse lease-partitions", "error", err)
+	}
+	stepDownTimeout, err := parseDurationFlag("step-down-timeout", *stepDownTimeoutFlag)
+	if err != nil {
+		logging.Fatal("parse step-down-timeout", "error", err)
+	}
+	drainRefreshInterval, err := parseDurationFlag("drain-refresh-interval", *drainRefreshFlag)
+	if err != nil {
+		logging.Fatal("parse drain-refresh-interval", "error", err)
+	}
+	drainRetryAfter, err := parseDurationFlag("drain-retry-after", *drainRetryAfterFlag)
+	if err != nil {
+		logging.Fatal("parse drain-retry-after", "error", err)
+	}
+	admissionMaxPending, err := parseAdmissionMaxPending(*admissionMaxPendingFlag)
+	if err != nil {
+		logging.Fatal("parse admission-max-pending", "error", err)
+	}
+	admissionRefreshInterval, err := parseDurationFlag("admission-refresh-interval", *admissionRefreshFlag)
+	if err != nil {
+		logging.Fatal("parse admission-refresh-interval", "error", err)
+	}
+	endpointFailureThreshold, err := parseEndpointFailureThreshold(*endpointFailuresFlag)
+	if err != nil {
+		logging.Fatal("parse endpoint-failure-threshold", "error", err)
+	}
+	endpointCooldown, err := parseDurationFlag("endpoint-cooldown", *endpointCooldownFlag)
+	if err != nil {
+		logging.Fatal("parse endpoint-cooldown", "error", err)
+	}
+	claimStrategy, err := submissionmanager.ParseClaimStrategy(*claimStrategyFlag)
+	if err != nil {
+		logging.Fatal("parse claim-strategy", "error", err)
 	}
 
-	registry, err := submission.LoadRegistry(*registryPathFlag)
+	allowPrivate, err := strconv.ParseBool(strings.TrimSpace(*webhookAllowPrivateFlag))
+	if err != nil {
+		logging.Fatal("parse webhook-allow-private", "error", err)
+	}
+	egressPolicy, err := newWebhookEgressPolicy(*webhookAllowedHostsFlag, *webhookAllowedCIDRsFlag, allowPrivate)
 	if err != nil {
-		log.Fatalf("load registry: %v", err)
+		logging.Fatal("parse webhook egress policy", "error", err)
 	}
 
-	dsn, err := buildSQLServerDSN(*mssqlHostFlag, *mssqlPortFlag, *mssqlUserFlag, *mssqlPasswordFlag, *mssqlDBFlag, *mssqlEncryptFlag)
+	registry, err := submission.LoadRegistry(*registryPathFlag)
 	if err != nil {
-		log.Fatalf("build SQL Server DSN: %v", err)
+		logging.Fatal("load registry", "error", err)
 	}
 
-	db, err := sql.Open("sqlserver", dsn)
+	backend, err := parseStoreBackend(*storeFlag)
+	if err != nil {
+		logging.Fatal("parse store", "error", err)
+	}
+	db, err := openStoreDB(backend)
 	if err != nil {
-		log.Fatalf("open SQL Server: %v", err)
+		logging.Fatal("open store", "store", backend, "error", err)
 	}
 	defer func() {
 		_ = db.Close()
 	}()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	if err := db.PingContext(ctx); err != nil {
+	migrations, err := newMigrationRunner(db, backend, *migrationsDirFlag)
+	if err != nil {
+		logging.Fatal("load migrations", "error", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	if backend == storeSQLite {
+		// The embedded store has no separate deploy step, so it migrates itself on start.
+		applied, err := migrations.Up(ctx)
+		if err != nil {
+			cancel()
+			logging.Fatal("migrate up", "store", backend, "error", err)
+		}
+		for _, migration := range applied {
+			slog.Info("migration_applied", "migration", migration.ID())
+		}
+	}
+	if err := migrations.Check(ctx); err != nil {
 		cancel()
-		log.Fatalf("ping SQL Server: %v", err)
+		logging.Fatal("incompatible schema", "store", backend, "schemaVersion", migrations.Version(), "error", err)
 	}
 	cancel()
 
+	store, err := newStore(backend, db)
+	if err != nil {
+		logging.Fatal("construct store", "store", backend, "error", err)
+	}
 	client := &http.Client{}
-	exec := newGatewayExecutor(client)
-	manager, err := submissionmanager.NewManager(registry, exec, submissionmanager.Clock{}, db)
+	exec := newGatewayExecutorWithHealth(client, newEndpointHealth(endpointFailureThreshold, endpointCooldown))
+	manager, err := submissionmanager.NewManagerWithStore(registry, exec, submissionmanager.Clock{}, store)
 	if err != nil {
-		log.Fatalf("construct manager: %v", err)
+		logging.Fatal("construct manager", "error", err)
 	}
 	metrics := submissionmanager.NewMetrics()
 	manager.SetMetrics(metrics)
-	manager.SetWebhookSender(newWebhookSender(client))
+	manager.SetClaimStrategy(claimStrategy)
+	manager.SetAdmissionLimit(admissionMaxPending)
+	manager.SetWebhookSender(newWebhookSender(newWebhookClient(egressPolicy)))
+	traceExporter, err := tracing.NewExporter(*traceExporterFlag, *traceFileFlag)
+	if err != nil {
+		logging.Fatal("configure trace exporter", "error", err)
+	}
+	tracer := tracing.NewTracer("submission-manager", traceExporter)
+	manager.SetTracer(tracer)
 
 	holderID := strings.TrimSpace(*holderIDFlag)
 	if holderID == "" {
@@ -103,7 +204,7 @@ func main() {
 	}
 	leaseName := strings.TrimSpace(*leaseNameFlag)
 	if leaseName == "" {
-		log.Fatalf("lease-name is required")
+		logging.Fatal("lease-name is required")
 	}
 
 	leaseCfg := submissionmanager.LeaseConfig{
@@ -113,15 +214,16 @@ func main() {
 		RenewInterval:           renewInterval,
 		AcquireInterval:         acquireInterval,
 		ScheduleRefreshInterval: scheduleRefreshInterval,
+		Partitions:              leasePartitions,
 	}
 	runner := submissionmanager.NewLeaderRunnerFromManager(manager, leaseCfg)
 
 	var uiServer *managerUIServer
 	uiDir, err := findUIDir()
 	if err != nil {
-		log.Printf("ui disabled: %v", err)
+		slog.Warn("ui disabled", "error", err)
 	} else if templates, err := loadManagerTemplates(uiDir); err != nil {
-		log.Printf("ui disabled: %v", err)
+		slog.Warn("ui disabled", "error", err)
 	} else {
 		uiServer = &managerUIServer{templates: templates, manager: manager}
 	}
@@ -129,9 +231,20 @@ func main() {
 	ctx, cancel = context.WithCancel(context.Background())
 	defer cancel()
 	go runner.Run(ctx)
+	go manager.RunDrainRefresh(ctx, drainRefreshInterval)
+	go manager.RunAdmissionRefresh(ctx, admissionRefreshInterval)
 
-	server := &apiServer{manager: manager}
-	mux := newMux(server, uiServer, metrics, runner.Status)
+	stepDown := func(ctx context.Context) []int {
+		return runner.StepDown(ctx, stepDownTimeout)
+	}
+	server := &apiServer{
+		manager:         manager,
+		tracer:          tracer,
+		stepDown:        stepDown,
+		schedule:        runner.Schedule,
+		drainRetryAfter: drainRetryAfter,
+	}
+	mux := newMux(server, uiServer, metrics, runner.Status, manager.DrainState)
 
 	httpServer := &http.Server{
 		Addr:    *addrFlag,
@@ -143,18 +256,60 @@ func main() {
 
 	go func() {
 		<-stop
+		// Flow intent: hand the lease to a follower before the listener closes so execution resumes
+		// on the next acquire tick instead of after lease expiry.
+		stepDown(context.Background())
 		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer shutdownCancel()
 		_ = httpServer.Shutdown(shutdownCtx)
 		cancel()
 	}()
 
-	log.Printf("submission-manager listening on %s", *addrFlag)
+	slog.Info("listening", "addr", *addrFlag)
 	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		log.Fatalf("http server: %v", err)
+		logging.Fatal("http server", "error", err)
 	}
 }
 
+// openStoreDB opens and pings the database for backend using the connection flags.
+func openStoreDB(backend string) (*sql.DB, error) {
+	driver, err := storeDriver(backend)
+	if err != nil {
+		return nil, err
+	}
+	var dsn string
+	switch backend {
+	case storePostgres:
+		dsn = strings.TrimSpace(*postgresDSNFlag)
+		if dsn == "" {
+			return nil, errors.New("postgres-dsn is required when store is postgres")
+		}
+	case storeSQLite:
+		path := strings.TrimSpace(*sqlitePathFlag)
+		if path == "" {
+			return nil, errors.New("sqlite-path is required when store is sqlite")
+		}
+		dsn = submissionmanager.SQLiteDSN(path)
+	default:
+		dsn, err = buildSQLServerDSN(*mssqlHostFlag, *mssqlPortFlag, *mssqlUserFlag, *mssqlPasswordFlag, *mssqlDBFlag, *mssqlEncryptFlag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("ping: %w", err)
+	}
+	return db, nil
+}
+
 func envOrDefault(key, fallback string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -179,6 +334,42 @@ func buildSQLServerDSN(host, port, user, password, database, encrypt string) (st
 	return uri.String(), nil
 }
 
+// maxLeasePartitions keeps partitioned lease names within the lease_name column.
+const maxLeasePartitions = 1024
+
+func parseLeasePartitions(value string) (int, error) {
+	partitions, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	if partitions < 1 || partitions > maxLeasePartitions {
+		return 0, fmt.Errorf("lease-partitions must be between 1 and %d", maxLeasePartitions)
+	}
+	return partitions, nil
+}
+
+func parseAdmissionMaxPending(value string) (int, error) {
+	limit, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	if limit < 0 {
+		return 0, errors.New("admission-max-pending must be zero or greater")
+	}
+	return limit, nil
+}
+
+func parseEndpointFailureThreshold(value string) (int, error) {
+	threshold, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	if threshold < 1 {
+		return 0, errors.New("endpoint-failure-threshold must be greater than zero")
+	}
+	return threshold, nil
+}
+
 func parseDurationFlag(name, value string) (time.Duration, error) {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
@@ -13,14 +13,17 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	_ "github.com/microsoft/go-mssqldb"
 
+	"gateway/migrate"
 	"gateway/submission"
 	"gateway/submissionmanager"
+	"gateway/tracing"
 )
 
 type stubExecutor struct{}
@@ -29,7 +32,7 @@ func (s stubExecutor) Exec(ctx context.Context, input submissionmanager.AttemptI
 	return submissionmanager.GatewayOutcome{}, nil
 }
 
-func newTestManager(t *testing.T, db *sql.DB) *submissionmanager.Manager {
+func newTestManager(t *testing.T, store submissionmanager.Store) *submissionmanager.Manager {
 	t.Helper()
 	contract := submission.TargetContract{
 		SubmissionTarget: "sms.realtime",
@@ -40,7 +43,7 @@ func newTestManager(t *testing.T, db *sql.DB) *submissionmanager.Manager {
 		TerminalOutcomes: []string{"invalid_request"},
 	}
 	registry := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
-	manager, err := submissionmanager.NewManager(registry, stubExecutor{}.Exec, submissionmanager.Clock{}, db)
+	manager, err := submissionmanager.NewManagerWithStore(registry, stubExecutor{}.Exec, submissionmanager.Clock{}, store)
 	if err != nil {
 		t.Fatalf("new manager: %v", err)
 	}
@@ -48,8 +51,7 @@ func newTestManager(t *testing.T, db *sql.DB) *submissionmanager.Manager {
 }
 
 func TestSubmitIntentIdempotent(t *testing.T) {
-	db := newTestDB(t)
-	manager := newTestManager(t, db)
+	manager := newTestManager(t, newTestStore(t))
 	server := &apiServer{manager: manager}
 
 	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
@@ -78,8 +80,7 @@ func TestSubmitIntentIdempotent(t *testing.T) {
 }
 
 func TestGetIntent(t *testing.T) {
-	db := newTestDB(t)
-	manager := newTestManager(t, db)
+	manager := newTestManager(t, newTestStore(t))
 	server := &apiServer{manager: manager}
 
 	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
@@ -113,8 +114,7 @@ func TestGetIntent(t *testing.T) {
 }
 
 func TestGetIntentHistory(t *testing.T) {
-	db := newTestDB(t)
-	manager := newTestManager(t, db)
+	manager := newTestManager(t, newTestStore(t))
 	server := &apiServer{manager: manager}
 
 	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
@@ -148,8 +148,7 @@ func TestGetIntentHistory(t *testing.T) {
 }
 
 func TestGetIntentNotFound(t *testing.T) {
-	db := newTestDB(t)
-	manager := newTestManager(t, db)
+	manager := newTestManager(t, newTestStore(t))
 	server := &apiServer{manager: manager}
 
 	req := httptest.NewRequest(http.MethodGet, "/v1/intents/missing", nil)
@@ -161,8 +160,7 @@ func TestGetIntentNotFound(t *testing.T) {
 }
 
 func TestSubmitUnknownTarget(t *testing.T) {
-	db := newTestDB(t)
-	manager := newTestManager(t, db)
+	manager := newTestManager(t, newTestStore(t))
 	server := &apiServer{manager: manager}
 
 	body := `{"intentId":"intent-1","submissionTarget":"unknown.target","payload":{"to":"+1","message":"hello"}}`
@@ -175,9 +173,36 @@ func TestSubmitUnknownTarget(t *testing.T) {
 	}
 }
 
+type spanRecorder struct {
+	spans []tracing.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(data tracing.SpanData) {
+	r.spans = append(r.spans, data)
+}
+
+func TestSubmitSpanRecordsRejection(t *testing.T) {
+	manager := newTestManager(t, newTestStore(t))
+	recorder := &spanRecorder{}
+	server := &apiServer{manager: manager, tracer: tracing.NewTracer("submission-manager", recorder)}
+
+	body := `{"intentId":"intent-1","submissionTarget":"unknown.target","payload":{"to":"+1","message":"hello"}}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/intents", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	server.handleSubmit(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+	if len(recorder.spans) != 1 || recorder.spans[0].Name != "submission.submit" {
+		t.Fatalf("expected one submission.submit span, got %+v", recorder.spans)
+	}
+	if recorder.spans[0].Error == "" {
+		t.Fatalf("expected the rejected submit to record an error on its span")
+	}
+}
+
 func TestSubmitWaitSecondsInvalid(t *testing.T) {
-	db := newTestDB(t)
-	manager := newTestManager(t, db)
+	manager := newTestManager(t, newTestStore(t))
 	server := &apiServer{manager: manager}
 
 	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
@@ -190,8 +215,7 @@ func TestSubmitWaitSecondsInvalid(t *testing.T) {
 }
 
 func TestSubmitWaitSecondsNegative(t *testing.T) {
-	db := newTestDB(t)
-	manager := newTestManager(t, db)
+	manager := newTestManager(t, newTestStore(t))
 	server := &apiServer{manager: manager}
 
 	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
@@ -204,8 +228,7 @@ func TestSubmitWaitSecondsNegative(t *testing.T) {
 }
 
 func TestSubmitWaitSecondsTimeout(t *testing.T) {
-	db := newTestDB(t)
-	manager := newTestManager(t, db)
+	manager := newTestManager(t, newTestStore(t))
 	server := &apiServer{manager: manager}
 
 	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
@@ -231,13 +254,25 @@ func TestSubmitWaitSecondsTimeout(t *testing.T) {
 }
 
 func TestSubmitWaitSecondsEarlyReturn(t *testing.T) {
-	db := newTestDB(t)
-	manager := newTestManager(t, db)
+	manager := newTestManager(t, newTestStore(t))
 	server := &apiServer{manager: manager}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
-	go manager.Run(ctx)
+	// Attempts only run while the manager holds the executor lease.
+	runner := submissionmanager.NewLeaderRunnerFromManager(manager, submissionmanager.LeaseConfig{
+		LeaseName:               "submission-manager-executor",
+		HolderID:                "holder-a",
+		LeaseDuration:           2 * time.Second,
+		RenewInterval:           500 * time.Millisecond,
+		AcquireInterval:         50 * time.Millisecond,
+		ScheduleRefreshInterval: 50 * time.Millisecond,
+	})
+	go runner.Run(ctx)
+	deadline := time.Now().Add(2 * time.Second)
+	for !runner.IsLeader() && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
 
 	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
 	req := httptest.NewRequest(http.MethodPost, "/v1/intents?waitSeconds=5", strings.NewReader(body))
@@ -261,6 +296,77 @@ func TestSubmitWaitSecondsEarlyReturn(t *testing.T) {
 	}
 }
 
+func TestWebhookRedeliverPendingIntentConflict(t *testing.T) {
+	manager := newTestManager(t, newTestStore(t))
+	server := &apiServer{manager: manager}
+
+	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/intents", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	server.handleSubmit(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/intents/intent-1/webhook:redeliver", nil)
+	rr = httptest.NewRecorder()
+	server.handleGet(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "intent_not_terminal") {
+		t.Fatalf("expected intent_not_terminal, got %q", rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/intents/missing/webhook:redeliver", nil)
+	rr = httptest.NewRecorder()
+	server.handleGet(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestWebhookRedeliverMethodNotAllowed(t *testing.T) {
+	server := &apiServer{}
+	req := httptest.NewRequest(http.MethodGet, "/v1/intents/intent-1/webhook:redeliver", nil)
+	rr := httptest.NewRecorder()
+	server.handleGet(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/intents/intent-1", nil)
+	rr = httptest.NewRecorder()
+	server.handleGet(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rr.Code)
+	}
+}
+
+func TestHandleStepDown(t *testing.T) {
+	server := &apiServer{stepDown: func(context.Context) []int { return []int{0, 3} }}
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/step-down", nil)
+	rr := httptest.NewRecorder()
+	server.handleStepDown(rr, req)
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/admin/step-down", nil)
+	rr = httptest.NewRecorder()
+	server.handleStepDown(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var resp stepDownResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.ReleasedPartitions) != 2 || resp.ReleasedPartitions[1] != 3 {
+		t.Fatalf("unexpected released partitions: %v", resp.ReleasedPartitions)
+	}
+}
+
 func TestHandleMetrics(t *testing.T) {
 	metrics := submissionmanager.NewMetrics()
 	metrics.ObserveIntentCreated()
@@ -304,15 +410,105 @@ func TestHandleHealthzAndReadyz(t *testing.T) {
 
 	req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
 	rr = httptest.NewRecorder()
-	handleReadyz(nil).ServeHTTP(rr, req)
+	handleReadyz(nil, nil).ServeHTTP(rr, req)
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", rr.Code)
 	}
 }
 
+func TestHandleReadyzDraining(t *testing.T) {
+	draining := func() submissionmanager.DrainState { return submissionmanager.DrainState{Draining: true} }
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rr := httptest.NewRecorder()
+	handleReadyz(nil, draining).ServeHTTP(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "draining=true") {
+		t.Fatalf("expected draining in the body, got %q", rr.Body.String())
+	}
+}
+
+func TestHandleSubmitWhileDraining(t *testing.T) {
+	manager := newTestManager(t, newTestStore(t))
+	server := &apiServer{manager: manager, drainRetryAfter: 45 * time.Second}
+
+	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/intents", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	server.handleSubmit(rr, req)
+	if rr.Code != http.StatusAccepted && rr.Code != http.StatusOK {
+		t.Fatalf("expected the first submit to succeed, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/admin/drain", strings.NewReader(`{"draining":true,"reason":"maintenance"}`))
+	rr = httptest.NewRecorder()
+	server.handleDrain(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 from drain, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/intents", strings.NewReader(strings.Replace(body, "intent-1", "intent-2", 1)))
+	rr = httptest.NewRecorder()
+	server.handleSubmit(rr, req)
+	if rr.Code != http.StatusServiceUnavailable || rr.Header().Get("Retry-After") != "45" {
+		t.Fatalf("expected 503 with Retry-After 45, got %d %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/intents", strings.NewReader(body))
+	rr = httptest.NewRecorder()
+	server.handleSubmit(rr, req)
+	if rr.Code == http.StatusServiceUnavailable {
+		t.Fatalf("expected the replay to be answered while draining")
+	}
+}
+
+func TestHandleSubmitAboveAdmissionLimit(t *testing.T) {
+	manager := newTestManager(t, newTestStore(t))
+	server := &apiServer{manager: manager}
+
+	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/intents", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	server.handleSubmit(rr, req)
+	if rr.Code != http.StatusAccepted && rr.Code != http.StatusOK {
+		t.Fatalf("expected the first submit to succeed, got %d", rr.Code)
+	}
+
+	manager.SetAdmissionLimit(1)
+	if err := manager.RefreshAdmission(context.Background()); err != nil {
+		t.Fatalf("refresh admission: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/v1/intents", strings.NewReader(strings.Replace(body, "intent-1", "intent-2", 1)))
+	rr = httptest.NewRecorder()
+	server.handleSubmit(rr, req)
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "30" {
+		t.Fatalf("expected 429 with Retry-After 30, got %d %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+	if !strings.Contains(rr.Body.String(), `"admission_rejected"`) {
+		t.Fatalf("expected admission_rejected code, got %s", rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/intents", strings.NewReader(body))
+	rr = httptest.NewRecorder()
+	server.handleSubmit(rr, req)
+	if rr.Code == http.StatusTooManyRequests {
+		t.Fatalf("expected the replay to be answered above the limit")
+	}
+}
+
+func TestHandleDrainRequiresDraining(t *testing.T) {
+	server := &apiServer{}
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/drain", strings.NewReader(`{"reason":"x"}`))
+	rr := httptest.NewRecorder()
+	server.handleDrain(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
 func TestHandleHistoryResults(t *testing.T) {
-	db := newTestDB(t)
-	manager := newTestManager(t, db)
+	manager := newTestManager(t, newTestStore(t))
 	server := &apiServer{manager: manager}
 
 	body := `{"intentId":"intent-1","submissionTarget":"sms.realtime","payload":{"to":"+1","message":"hello"}}`
@@ -337,11 +533,27 @@ func TestHandleHistoryResults(t *testing.T) {
 	}
 }
 
+func TestBuildHistoryViewShowsIntentCallback(t *testing.T) {
+	intent := submissionmanager.Intent{
+		IntentID: "intent-1",
+		Contract: submission.TargetContract{Webhook: &submission.WebhookConfig{URL: "https://hooks.example.com/default"}},
+	}
+	if view := buildHistoryView(intent); view.Webhook.URL != "https://hooks.example.com/default" {
+		t.Fatalf("expected target webhook url, got %q", view.Webhook.URL)
+	}
+	intent.CallbackURL = "https://hooks.example.com/team-a/events"
+	if view := buildHistoryView(intent); view.Webhook.URL != intent.CallbackURL {
+		t.Fatalf("expected intent callback url, got %q", view.Webhook.URL)
+	}
+}
+
 func newTestUIServer() *managerUIServer {
 	history := template.Must(template.New("manager_history_results.tmpl").Parse(`{{define "manager_history_results.tmpl"}}history {{.IntentID}}{{end}}`))
+	redelivery := template.Must(template.New("manager_webhook_redelivery.tmpl").Parse(`{{define "manager_webhook_redelivery.tmpl"}}redelivery {{.IntentID}} {{.Status}}{{end}}`))
 	return &managerUIServer{
 		templates: managerTemplates{
-			historyResults: history,
+			historyResults:    history,
+			webhookRedelivery: redelivery,
 		},
 	}
 }
@@ -352,16 +564,65 @@ func newTestUIServerWithManager(manager *submissionmanager.Manager) *managerUISe
 	return ui
 }
 
-func newTestDB(t *testing.T) *sql.DB {
+// newTestStore returns a migrated store on the database newTestStoreDB selects.
+func newTestStore(t *testing.T) submissionmanager.Store {
+	t.Helper()
+
+	db, backend := newTestStoreDB(t)
+	runner := newTestMigrationRunner(t, db, backend)
+	if _, err := runner.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	store, err := newStore(backend, db)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	return store
+}
+
+// newTestStoreDB returns an empty database, preferring SQL Server when MSSQL_SA_PASSWORD is
+// configured and otherwise using an embedded SQLite file.
+func newTestStoreDB(t *testing.T) (*sql.DB, string) {
 	t.Helper()
 
 	password, ok, err := resolveSQLPassword(t)
 	if err != nil {
 		t.Fatalf("resolve sql password: %v", err)
 	}
-	if !ok {
-		t.Skip("MSSQL_SA_PASSWORD not set; start docker compose and set env or backend/.env")
+	if ok {
+		return newTestSQLServerDB(t, password), storeSQLServer
 	}
+	return newTestSQLiteDB(t), storeSQLite
+}
+
+func newTestMigrationRunner(t *testing.T, db *sql.DB, backend string) *migrate.Runner {
+	t.Helper()
+	dir := filepath.Join(moduleRoot(t), defaultMigrationsDir)
+	if backend != storeSQLServer {
+		dir = filepath.Join(dir, backend)
+	}
+	runner, err := newMigrationRunner(db, backend, dir)
+	if err != nil {
+		t.Fatalf("new migration runner: %v", err)
+	}
+	return runner
+}
+
+func newTestSQLiteDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open(submissionmanager.SQLiteDriver, submissionmanager.SQLiteDSN(filepath.Join(t.TempDir(), "submissionmanager.db")))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db
+}
+
+func newTestSQLServerDB(t *testing.T, password string) *sql.DB {
+	t.Helper()
 
 	host := envOrDefault("MSSQL_HOST", "localhost")
 	port := envOrDefault("MSSQL_PORT", "1433")
@@ -399,19 +660,6 @@ func newTestDB(t *testing.T) *sql.DB {
 		t.Fatalf("open test db: %v", err)
 	}
 
-	schemaPath := filepath.Join(moduleRoot(t), "conf", "sql", "submissionmanager", "001_create_schema.sql")
-	schema, err := os.ReadFile(schemaPath)
-	if err != nil {
-		_ = db.Close()
-		_ = dropTestDB(ctx, masterDB, dbName)
-		t.Fatalf("read schema: %v", err)
-	}
-	if _, err := db.ExecContext(ctx, string(schema)); err != nil {
-		_ = db.Close()
-		_ = dropTestDB(ctx, masterDB, dbName)
-		t.Fatalf("apply schema: %v", err)
-	}
-
 	t.Cleanup(func() {
 		_ = db.Close()
 		_ = dropTestDB(context.Background(), masterDB, dbName)
@@ -471,3 +719,145 @@ func moduleRoot(t *testing.T) string {
 	}
 	return filepath.Clean(filepath.Join(filepath.Dir(filename), "..", ".."))
 }
+
+func TestParseStoreBackend(t *testing.T) {
+	for _, value := range []string{"sqlserver", " Postgres ", "SQLite"} {
+		if _, err := parseStoreBackend(value); err != nil {
+			t.Fatalf("parse %q: %v", value, err)
+		}
+	}
+	if _, err := parseStoreBackend("mysql"); err == nil {
+		t.Fatalf("expected unknown store to fail")
+	}
+}
+
+func TestStoreDriverLinksEveryBackend(t *testing.T) {
+	for _, backend := range []string{storeSQLServer, storePostgres, storeSQLite} {
+		if _, err := storeDriver(backend); err != nil {
+			t.Fatalf("expected %s driver to be linked: %v", backend, err)
+		}
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	for _, args := range [][]string{{"migrate", "up"}, {"migrate", "status"}} {
+		if _, err := parseCommand(args); err != nil {
+			t.Fatalf("parse %v: %v", args, err)
+		}
+	}
+	for _, args := range [][]string{{"migrate"}, {"migrate", "down"}, {"serve"}, {"migrate", "up", "extra"}} {
+		if _, err := parseCommand(args); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
+
+func TestMigrateUpAndStatus(t *testing.T) {
+	db, backend := newTestStoreDB(t)
+	runner := newTestMigrationRunner(t, db, backend)
+	ctx := context.Background()
+
+	if err := runner.Check(ctx); err == nil {
+		t.Fatalf("expected an empty database to be incompatible")
+	}
+	var out strings.Builder
+	if err := runMigrate(ctx, &out, runner, migrateStatus); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !slices.ContainsFunc(strings.Split(out.String(), "\n"), func(line string) bool {
+		return slices.Equal(strings.Fields(line), []string{"001", "create_schema", "pending", "-"})
+	}) {
+		t.Fatalf("expected pending baseline, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runMigrate(ctx, &out, runner, migrateUp); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if !strings.Contains(out.String(), "applied 001_create_schema") {
+		t.Fatalf("expected baseline to be applied, got:\n%s", out.String())
+	}
+	if err := runner.Check(ctx); err != nil {
+		t.Fatalf("expected migrated database to be compatible: %v", err)
+	}
+
+	out.Reset()
+	if err := runMigrate(ctx, &out, runner, migrateUp); err != nil {
+		t.Fatalf("second up: %v", err)
+	}
+	if strings.Contains(out.String(), "applied ") {
+		t.Fatalf("expected second up to be a no-op, got:\n%s", out.String())
+	}
+}
+
+func TestParseWaitFor(t *testing.T) {
+	cases := map[string]submissionmanager.WaitCondition{
+		"":              submissionmanager.WaitFirstAttempt,
+		"first_attempt": submissionmanager.WaitFirstAttempt,
+		" terminal ":    submissionmanager.WaitTerminal,
+	}
+	for raw, want := range cases {
+		got, err := parseWaitFor(raw)
+		if err != nil || got != want {
+			t.Fatalf("parse %q: got %q err=%v, want %q", raw, got, err, want)
+		}
+	}
+	if _, err := parseWaitFor("accepted"); err == nil {
+		t.Fatalf("expected unknown waitFor to fail")
+	}
+}
+
+func TestGetIntentWaitForInvalid(t *testing.T) {
+	server := &apiServer{}
+	req := httptest.NewRequest(http.MethodGet, "/v1/intents/intent-1?waitSeconds=1&waitFor=never", nil)
+	rr := httptest.NewRecorder()
+	server.handleGet(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestHandleSchedule(t *testing.T) {
+	var gotLimit int
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := &apiServer{schedule: func(_ context.Context, limit int) (submissionmanager.ScheduleView, error) {
+		gotLimit = limit
+		return submissionmanager.ScheduleView{
+			Now:        now,
+			Local:      submissionmanager.LeaseStatus{Mode: "leader", HolderID: "holder-a", Partitions: []submissionmanager.PartitionLease{{Partition: 0}}},
+			Leases:     []submissionmanager.PartitionLeaseState{{Partition: 0, HolderID: "holder-a", LeaseEpoch: 4, ExpiresAt: now.Add(time.Minute), Active: true}},
+			QueueDepth: map[string]int{"sms.realtime": 2},
+			Due:        []submissionmanager.DueAttempt{{IntentID: "intent-1", SubmissionTarget: "sms.realtime", AttemptNumber: 2, DueAt: now}},
+		}, nil
+	}}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/schedule?limit=0", nil)
+	rr := httptest.NewRecorder()
+	server.handleSchedule(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/admin/schedule?limit=500", nil)
+	rr = httptest.NewRecorder()
+	server.handleSchedule(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if gotLimit != maxScheduleLimit {
+		t.Fatalf("expected limit capped at %d, got %d", maxScheduleLimit, gotLimit)
+	}
+	var resp scheduleResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Local.Mode != "leader" || len(resp.Leases) != 1 || !resp.Leases[0].Active || resp.Leases[0].LeaseEpoch != 4 {
+		t.Fatalf("unexpected leases: %+v %+v", resp.Local, resp.Leases)
+	}
+	if resp.QueueDepth["sms.realtime"] != 2 || len(resp.Due) != 1 || resp.Due[0].DueAt != now.Format(time.RFC3339Nano) {
+		t.Fatalf("unexpected queue: %+v %+v", resp.QueueDepth, resp.Due)
+	}
+	if resp.InFlight == nil || resp.LeaseHistory == nil {
+		t.Fatalf("expected empty lists rather than null")
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"gateway/logging"
+	"gateway/migrate"
+)
+
+const (
+	migrateUp     = "up"
+	migrateStatus = "status"
+)
+
+// parseCommand accepts `migrate up` and `migrate status`, the only subcommands.
+func parseCommand(args []string) (string, error) {
+	if len(args) != 2 || args[0] != "migrate" || (args[1] != migrateUp && args[1] != migrateStatus) {
+		return "", fmt.Errorf("usage: submission-manager [flags] migrate %s|%s", migrateUp, migrateStatus)
+	}
+	return args[1], nil
+}
+
+// runCommand runs a subcommand against the store selected by the connection flags and exits on
+// failure.
+func runCommand(args []string) {
+	action, err := parseCommand(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	backend, err := parseStoreBackend(*storeFlag)
+	if err != nil {
+		logging.Fatal("parse store", "error", err)
+	}
+	db, err := openStoreDB(backend)
+	if err != nil {
+		logging.Fatal("open store", "store", backend, "error", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	runner, err := newMigrationRunner(db, backend, *migrationsDirFlag)
+	if err != nil {
+		logging.Fatal("load migrations", "error", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	if err := runMigrate(ctx, os.Stdout, runner, action); err != nil {
+		logging.Fatal("migrate "+action, "store", backend, "error", err)
+	}
+}
+
+func runMigrate(ctx context.Context, out io.Writer, runner *migrate.Runner, action string) error {
+	if action == migrateUp {
+		applied, err := runner.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied %s\n", migration.ID())
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "schema is at version %d\n", runner.Version())
+		return nil
+	}
+
+	statuses, err := runner.Status(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%03d\t%s\t%s\t%s\n", status.Version, status.Name, status.State, appliedAt)
+	}
+	return tw.Flush()
+}
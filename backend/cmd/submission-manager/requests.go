@@ -6,16 +6,32 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"gateway/submissionmanager"
 )
 
 type submitRequest struct {
 	IntentID         string          `json:"intentId"`
 	SubmissionTarget string          `json:"submissionTarget"`
 	Payload          json.RawMessage `json:"payload"`
+	// CallbackURL optionally overrides the target's webhook URL; it must match the target allowlist.
+	CallbackURL       string `json:"callbackUrl"`
+	CallbackSecretEnv string `json:"callbackSecretEnv"`
 }
 
 const maxWaitSeconds = 30
 
+// drainRequest flips drain mode; Draining is a pointer so that a missing field is rejected.
+type drainRequest struct {
+	Draining *bool  `json:"draining"`
+	Reason   string `json:"reason"`
+}
+
+const (
+	defaultScheduleLimit = 20
+	maxScheduleLimit     = 200
+)
+
 func parseWaitSeconds(raw string) (time.Duration, error) {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -33,3 +49,29 @@ func parseWaitSeconds(raw string) (time.Duration, error) {
 	}
 	return time.Duration(value) * time.Second, nil
 }
+
+// parseWaitFor reads the waitFor query parameter; the default keeps the first-attempt behavior.
+func parseWaitFor(raw string) (submissionmanager.WaitCondition, error) {
+	switch condition := submissionmanager.WaitCondition(strings.TrimSpace(raw)); condition {
+	case "":
+		return submissionmanager.WaitFirstAttempt, nil
+	case submissionmanager.WaitFirstAttempt, submissionmanager.WaitTerminal:
+		return condition, nil
+	default:
+		return "", errors.New("waitFor must be first_attempt or terminal")
+	}
+}
+
+// parseScheduleLimit reads the limit query parameter of the admin schedule view, capped at
+// maxScheduleLimit.
+func parseScheduleLimit(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultScheduleLimit, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+	return min(value, maxScheduleLimit), nil
+}
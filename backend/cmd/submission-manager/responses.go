@@ -9,11 +9,13 @@ import (
 type intentResponse struct {
 	IntentID         string `json:"intentId"`
 	SubmissionTarget string `json:"submissionTarget"`
+	TargetAlias      string `json:"targetAlias,omitempty"`
 	CreatedAt        string `json:"createdAt"`
 	Status           string `json:"status"`
 	CompletedAt      string `json:"completedAt,omitempty"`
 	RejectedReason   string `json:"rejectedReason,omitempty"`
 	ExhaustedReason  string `json:"exhaustedReason,omitempty"`
+	CallbackURL      string `json:"callbackUrl,omitempty"`
 }
 
 type intentHistoryResponse struct {
@@ -28,6 +30,25 @@ type attemptResponse struct {
 	OutcomeStatus string `json:"outcomeStatus,omitempty"`
 	OutcomeReason string `json:"outcomeReason,omitempty"`
 	Error         string `json:"error,omitempty"`
+	ErrorClass    string `json:"errorClass,omitempty"`
+}
+
+type stepDownResponse struct {
+	ReleasedPartitions []int `json:"releasedPartitions"`
+}
+
+type drainResponse struct {
+	Draining  bool   `json:"draining"`
+	Reason    string `json:"reason,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+type webhookRedeliveryResponse struct {
+	IntentID        string `json:"intentId"`
+	Status          string `json:"status"`
+	AttemptedAt     string `json:"attemptedAt,omitempty"`
+	Error           string `json:"error,omitempty"`
+	RedeliveryCount int    `json:"redeliveryCount"`
 }
 
 func toIntentResponse(intent submissionmanager.Intent) intentResponse {
@@ -53,11 +74,13 @@ func toIntentResponse(intent submissionmanager.Intent) intentResponse {
 	return intentResponse{
 		IntentID:         intent.IntentID,
 		SubmissionTarget: intent.SubmissionTarget,
+		TargetAlias:      intent.TargetAlias,
 		CreatedAt:        intent.CreatedAt.UTC().Format(timeFormat),
 		Status:           string(intent.Status),
 		CompletedAt:      completedAt,
 		RejectedReason:   rejectedReason,
 		ExhaustedReason:  exhaustedReason,
+		CallbackURL:      intent.CallbackURL,
 	}
 }
 
@@ -71,6 +94,7 @@ func toAttemptResponses(attempts []submissionmanager.Attempt) []attemptResponse
 			OutcomeStatus: attempt.GatewayOutcome.Status,
 			OutcomeReason: attempt.GatewayOutcome.Reason,
 			Error:         attempt.Error,
+			ErrorClass:    string(attempt.ErrorClass),
 		})
 	}
 	return out
@@ -84,3 +108,134 @@ func formatAttemptTime(value time.Time) string {
 }
 
 const timeFormat = time.RFC3339Nano
+
+func toWebhookRedeliveryResponse(intent submissionmanager.Intent) webhookRedeliveryResponse {
+	return webhookRedeliveryResponse{
+		IntentID:        intent.IntentID,
+		Status:          intent.WebhookRedeliveryStatus,
+		AttemptedAt:     formatAttemptTime(intent.WebhookRedeliveryAttemptedAt),
+		Error:           intent.WebhookRedeliveryError,
+		RedeliveryCount: intent.WebhookRedeliveryCount,
+	}
+}
+
+type scheduleResponse struct {
+	Now          string                   `json:"now"`
+	Local        localLeaseResponse       `json:"local"`
+	Leases       []partitionLeaseResponse `json:"leases"`
+	QueueDepth   map[string]int           `json:"queueDepth"`
+	Due          []dueAttemptResponse     `json:"due"`
+	InFlight     []inFlightResponse       `json:"inFlight"`
+	LeaseHistory []leaseEventResponse     `json:"leaseHistory"`
+}
+
+type localLeaseResponse struct {
+	Mode       string `json:"mode"`
+	HolderID   string `json:"holderId"`
+	Partitions []int  `json:"partitions"`
+}
+
+type partitionLeaseResponse struct {
+	Partition  int    `json:"partition"`
+	LeaseName  string `json:"leaseName"`
+	HolderID   string `json:"holderId"`
+	LeaseEpoch int64  `json:"leaseEpoch"`
+	ExpiresAt  string `json:"expiresAt"`
+	Active     bool   `json:"active"`
+}
+
+type dueAttemptResponse struct {
+	IntentID         string `json:"intentId"`
+	SubmissionTarget string `json:"submissionTarget"`
+	Partition        int    `json:"partition"`
+	AttemptNumber    int    `json:"attemptNumber"`
+	DueAt            string `json:"dueAt"`
+}
+
+type inFlightResponse struct {
+	IntentID      string `json:"intentId"`
+	Partition     int    `json:"partition"`
+	AttemptNumber int    `json:"attemptNumber"`
+	HolderID      string `json:"holderId"`
+	LeaseEpoch    int64  `json:"leaseEpoch"`
+	ClaimedAt     string `json:"claimedAt"`
+}
+
+type leaseEventResponse struct {
+	Partition  int    `json:"partition"`
+	HolderID   string `json:"holderId"`
+	LeaseEpoch int64  `json:"leaseEpoch"`
+	Event      string `json:"event"`
+	Detail     string `json:"detail,omitempty"`
+	OccurredAt string `json:"occurredAt"`
+}
+
+func toScheduleResponse(view submissionmanager.ScheduleView) scheduleResponse {
+	resp := scheduleResponse{
+		Now: formatAttemptTime(view.Now),
+		Local: localLeaseResponse{
+			Mode:       view.Local.Mode,
+			HolderID:   view.Local.HolderID,
+			Partitions: make([]int, 0, len(view.Local.Partitions)),
+		},
+		Leases:       make([]partitionLeaseResponse, 0, len(view.Leases)),
+		QueueDepth:   view.QueueDepth,
+		Due:          make([]dueAttemptResponse, 0, len(view.Due)),
+		InFlight:     make([]inFlightResponse, 0, len(view.InFlight)),
+		LeaseHistory: make([]leaseEventResponse, 0, len(view.LeaseHistory)),
+	}
+	if resp.QueueDepth == nil {
+		resp.QueueDepth = map[string]int{}
+	}
+	for _, partition := range view.Local.Partitions {
+		resp.Local.Partitions = append(resp.Local.Partitions, partition.Partition)
+	}
+	for _, lease := range view.Leases {
+		resp.Leases = append(resp.Leases, partitionLeaseResponse{
+			Partition:  lease.Partition,
+			LeaseName:  lease.LeaseName,
+			HolderID:   lease.HolderID,
+			LeaseEpoch: lease.LeaseEpoch,
+			ExpiresAt:  formatAttemptTime(lease.ExpiresAt),
+			Active:     lease.Active,
+		})
+	}
+	for _, due := range view.Due {
+		resp.Due = append(resp.Due, dueAttemptResponse{
+			IntentID:         due.IntentID,
+			SubmissionTarget: due.SubmissionTarget,
+			Partition:        due.Partition,
+			AttemptNumber:    due.AttemptNumber,
+			DueAt:            formatAttemptTime(due.DueAt),
+		})
+	}
+	for _, attempt := range view.InFlight {
+		resp.InFlight = append(resp.InFlight, inFlightResponse{
+			IntentID:      attempt.IntentID,
+			Partition:     attempt.Partition,
+			AttemptNumber: attempt.AttemptNumber,
+			HolderID:      attempt.HolderID,
+			LeaseEpoch:    attempt.LeaseEpoch,
+			ClaimedAt:     formatAttemptTime(attempt.ClaimedAt),
+		})
+	}
+	for _, event := range view.LeaseHistory {
+		resp.LeaseHistory = append(resp.LeaseHistory, leaseEventResponse{
+			Partition:  event.Partition,
+			HolderID:   event.HolderID,
+			LeaseEpoch: event.LeaseEpoch,
+			Event:      event.Event,
+			Detail:     event.Detail,
+			OccurredAt: formatAttemptTime(event.OccurredAt),
+		})
+	}
+	return resp
+}
+
+func toDrainResponse(state submissionmanager.DrainState) drainResponse {
+	return drainResponse{
+		Draining:  state.Draining,
+		Reason:    state.Reason,
+		UpdatedAt: formatAttemptTime(state.UpdatedAt),
+	}
+}
@@ -6,15 +6,19 @@ import (
 	"gateway/submissionmanager"
 )
 
-func newMux(server *apiServer, ui *managerUIServer, metrics *submissionmanager.Metrics, statusFn func() submissionmanager.LeaseStatus) *http.ServeMux {
+func newMux(server *apiServer, ui *managerUIServer, metrics *submissionmanager.Metrics, statusFn func() submissionmanager.LeaseStatus, drainFn func() submissionmanager.DrainState) *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", handleHealthz)
-	mux.HandleFunc("/readyz", handleReadyz(statusFn))
+	mux.HandleFunc("/readyz", handleReadyz(statusFn, drainFn))
 	mux.Handle("/metrics", handleMetrics(metrics))
 	mux.HandleFunc("/v1/intents", server.handleSubmit)
 	mux.HandleFunc("/v1/intents/", server.handleGet)
+	mux.HandleFunc("/v1/admin/step-down", server.handleStepDown)
+	mux.HandleFunc("/v1/admin/schedule", server.handleSchedule)
+	mux.HandleFunc("/v1/admin/drain", server.handleDrain)
 	if ui != nil {
 		mux.HandleFunc("/ui/history", ui.handleHistory)
+		mux.HandleFunc("/ui/webhook/redeliver", ui.handleWebhookRedeliver)
 	}
 	return mux
 }
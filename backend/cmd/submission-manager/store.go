@@ -0,0 +1,76 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gateway/migrate"
+	"gateway/submissionmanager"
+)
+
+const (
+	storeSQLServer = "sqlserver"
+	storePostgres  = "postgres"
+	storeSQLite    = "sqlite"
+)
+
+// postgresDriver is the database/sql driver the PostgreSQL store opens (see driver_postgres.go).
+const postgresDriver = "pgx"
+
+func parseStoreBackend(value string) (string, error) {
+	backend := strings.ToLower(strings.TrimSpace(value))
+	switch backend {
+	case storeSQLServer, storePostgres, storeSQLite:
+		return backend, nil
+	default:
+		return "", fmt.Errorf("store must be %s, %s, or %s", storeSQLServer, storePostgres, storeSQLite)
+	}
+}
+
+// storeDriver returns the database/sql driver for backend and fails early when it is not linked.
+func storeDriver(backend string) (string, error) {
+	driver := "sqlserver"
+	switch backend {
+	case storePostgres:
+		driver = postgresDriver
+	case storeSQLite:
+		driver = submissionmanager.SQLiteDriver
+	}
+	if !slices.Contains(sql.Drivers(), driver) {
+		return "", fmt.Errorf("%s driver %q is not linked into this binary", backend, driver)
+	}
+	return driver, nil
+}
+
+func newStore(backend string, db *sql.DB) (submissionmanager.Store, error) {
+	switch backend {
+	case storePostgres:
+		return submissionmanager.NewPostgresStore(db)
+	case storeSQLite:
+		return submissionmanager.NewSQLiteStore(db)
+	default:
+		return submissionmanager.NewSQLServerStore(db)
+	}
+}
+
+// defaultMigrationsDir holds the SQL Server migrations; the other stores use a subdirectory named
+// after the store.
+const defaultMigrationsDir = "conf/sql/submissionmanager"
+
+func newMigrationRunner(db *sql.DB, backend, dir string) (*migrate.Runner, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		dir = defaultMigrationsDir
+		if backend != storeSQLServer {
+			dir = filepath.Join(defaultMigrationsDir, backend)
+		}
+	}
+	migrations, err := migrate.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.NewRunner(db, migrate.Dialect(backend), migrations)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -14,7 +15,8 @@ import (
 )
 
 type managerTemplates struct {
-	historyResults *template.Template
+	historyResults    *template.Template
+	webhookRedelivery *template.Template
 }
 
 type managerUIServer struct {
@@ -30,9 +32,30 @@ type managerHistoryView struct {
 	CompletedAt      string
 	RejectedReason   string
 	ExhaustedReason  string
+	Webhook          *managerWebhookView
 	Attempts         []managerAttemptView
 }
 
+type managerWebhookView struct {
+	URL                   string
+	Status                string
+	AttemptedAt           string
+	DeliveredAt           string
+	Error                 string
+	RedeliveryCount       int
+	RedeliveryStatus      string
+	RedeliveryAttemptedAt string
+	RedeliveryError       string
+}
+
+type managerRedeliveryView struct {
+	IntentID    string
+	Status      string
+	AttemptedAt string
+	Error       string
+	Count       int
+}
+
 type managerAttemptView struct {
 	Number        int
 	StartedAt     string
@@ -40,6 +63,7 @@ type managerAttemptView struct {
 	OutcomeStatus string
 	OutcomeReason string
 	Error         string
+	ErrorClass    string
 }
 
 func loadManagerTemplates(uiDir string) (managerTemplates, error) {
@@ -47,8 +71,13 @@ func loadManagerTemplates(uiDir string) (managerTemplates, error) {
 	if err != nil {
 		return managerTemplates{}, err
 	}
+	webhookRedelivery, err := template.ParseFiles(filepath.Join(uiDir, "manager_webhook_redelivery.tmpl"))
+	if err != nil {
+		return managerTemplates{}, err
+	}
 	return managerTemplates{
-		historyResults: historyResults,
+		historyResults:    historyResults,
+		webhookRedelivery: webhookRedelivery,
 	}, nil
 }
 
@@ -98,6 +127,48 @@ func (u *managerUIServer) handleHistory(w http.ResponseWriter, r *http.Request)
 	u.renderFragment(w, u.templates.historyResults, "manager_history_results.tmpl", view)
 }
 
+func (u *managerUIServer) handleWebhookRedeliver(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	intentID := strings.TrimSpace(r.FormValue("intentId"))
+	if intentID == "" {
+		http.Error(w, "intentId is required", http.StatusBadRequest)
+		return
+	}
+	if u.manager == nil {
+		http.Error(w, "manager not configured", http.StatusInternalServerError)
+		return
+	}
+	intent, ok, err := u.manager.RedeliverWebhook(r.Context(), intentID)
+	if err != nil {
+		var unavailable submissionmanager.WebhookRedeliveryError
+		if errors.As(err, &unavailable) {
+			http.Error(w, "webhook cannot be redelivered: "+unavailable.Reason, http.StatusConflict)
+			return
+		}
+		http.Error(w, "webhook redelivery failed", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "intent not found", http.StatusNotFound)
+		return
+	}
+	view := managerRedeliveryView{
+		IntentID:    intent.IntentID,
+		Status:      intent.WebhookRedeliveryStatus,
+		AttemptedAt: formatTime(intent.WebhookRedeliveryAttemptedAt),
+		Error:       intent.WebhookRedeliveryError,
+		Count:       intent.WebhookRedeliveryCount,
+	}
+	u.renderFragment(w, u.templates.webhookRedelivery, "manager_webhook_redelivery.tmpl", view)
+}
+
 func (u *managerUIServer) renderFragment(w http.ResponseWriter, tmpl *template.Template, name string, data any) {
 	fragment, err := executeTemplate(tmpl, name, data)
 	if err != nil {
@@ -134,6 +205,24 @@ func buildHistoryView(intent submissionmanager.Intent) managerHistoryView {
 	if intent.Status == submissionmanager.IntentExhausted {
 		view.ExhaustedReason = intent.ExhaustedReason
 	}
+	if intent.Contract.Webhook != nil {
+		// A per-intent callback replaces the target's webhook URL for delivery and redelivery alike.
+		webhookURL := intent.Contract.Webhook.URL
+		if intent.CallbackURL != "" {
+			webhookURL = intent.CallbackURL
+		}
+		view.Webhook = &managerWebhookView{
+			URL:                   webhookURL,
+			Status:                intent.WebhookStatus,
+			AttemptedAt:           formatTime(intent.WebhookAttemptedAt),
+			DeliveredAt:           formatTime(intent.WebhookDeliveredAt),
+			Error:                 intent.WebhookError,
+			RedeliveryCount:       intent.WebhookRedeliveryCount,
+			RedeliveryStatus:      intent.WebhookRedeliveryStatus,
+			RedeliveryAttemptedAt: formatTime(intent.WebhookRedeliveryAttemptedAt),
+			RedeliveryError:       intent.WebhookRedeliveryError,
+		}
+	}
 	for _, attempt := range intent.Attempts {
 		view.Attempts = append(view.Attempts, managerAttemptView{
 			Number:        attempt.Number,
@@ -142,6 +231,7 @@ func buildHistoryView(intent submissionmanager.Intent) managerHistoryView {
 			OutcomeStatus: attempt.GatewayOutcome.Status,
 			OutcomeReason: attempt.GatewayOutcome.Reason,
 			Error:         attempt.Error,
+			ErrorClass:    string(attempt.ErrorClass),
 		})
 	}
 	return view
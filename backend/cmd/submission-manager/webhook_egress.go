@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"gateway/submissionmanager"
+)
+
+// webhookEgressPolicy restricts where webhook callbacks may be sent.
+type webhookEgressPolicy struct {
+	// AllowedHosts lists exact hostnames or "*.suffix" patterns. Empty allows any host.
+	AllowedHosts []string
+	// AllowPrivate permits loopback, private, link-local, and other non-public addresses.
+	AllowPrivate bool
+	// AllowedCIDRs are non-public ranges that are permitted even when AllowPrivate is false.
+	AllowedCIDRs []*net.IPNet
+}
+
+var blockedEgressCIDRs = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+)
+
+func newWebhookEgressPolicy(allowedHosts, allowedCIDRs string, allowPrivate bool) (webhookEgressPolicy, error) {
+	policy := webhookEgressPolicy{AllowPrivate: allowPrivate}
+	for _, host := range strings.Split(allowedHosts, ",") {
+		trimmed := strings.ToLower(strings.TrimSpace(host))
+		if trimmed == "" {
+			continue
+		}
+		policy.AllowedHosts = append(policy.AllowedHosts, trimmed)
+	}
+	for _, cidr := range strings.Split(allowedCIDRs, ",") {
+		trimmed := strings.TrimSpace(cidr)
+		if trimmed == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(trimmed)
+		if err != nil {
+			return webhookEgressPolicy{}, fmt.Errorf("invalid webhook allowed CIDR %q: %w", trimmed, err)
+		}
+		policy.AllowedCIDRs = append(policy.AllowedCIDRs, network)
+	}
+	return policy, nil
+}
+
+// newWebhookClient builds an HTTP client that enforces the egress policy on every request,
+// redirect, and dialed address.
+func newWebhookClient(policy webhookEgressPolicy) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		// Non-obvious constraint: check the resolved address at dial time so DNS rebinding cannot bypass the policy.
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return policy.checkAddress(address)
+		},
+	}
+	transport := &http.Transport{
+		// Proxies are disabled so the dialed address is always the webhook destination.
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	return &http.Client{
+		Transport: &egressRoundTripper{policy: policy, next: transport},
+	}
+}
+
+type egressRoundTripper struct {
+	policy webhookEgressPolicy
+	next   http.RoundTripper
+}
+
+func (t *egressRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Redirects also pass through here, so each hop is checked against the host allowlist.
+	if err := t.policy.checkHost(req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+func (p webhookEgressPolicy) checkHost(host string) error {
+	if len(p.AllowedHosts) == 0 {
+		return nil
+	}
+	normalized := strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range p.AllowedHosts {
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(normalized, allowed[1:]) {
+				return nil
+			}
+			continue
+		}
+		if normalized == allowed {
+			return nil
+		}
+	}
+	return submissionmanager.WebhookEgressDeniedError{Host: host, Reason: "host is not in the webhook allowlist"}
+}
+
+func (p webhookEgressPolicy) checkAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return submissionmanager.WebhookEgressDeniedError{Host: host, Reason: "dialed address is not an IP"}
+	}
+	if p.AllowPrivate || !isNonPublicIP(ip) {
+		return nil
+	}
+	for _, network := range p.AllowedCIDRs {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	return submissionmanager.WebhookEgressDeniedError{Host: host, Reason: "address is in a blocked range"}
+}
+
+func isNonPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range blockedEgressCIDRs {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDRs(values ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
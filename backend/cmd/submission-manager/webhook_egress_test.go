@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gateway/submissionmanager"
+)
+
+func TestWebhookClientEgressPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name         string
+		allowedHosts string
+		allowedCIDRs string
+		allowPrivate bool
+		wantDenied   bool
+	}{
+		{name: "loopback blocked by default", wantDenied: true},
+		{name: "loopback allowed with private egress", allowPrivate: true},
+		{name: "loopback allowed by CIDR", allowedCIDRs: "127.0.0.0/8"},
+		{name: "host outside allowlist", allowedHosts: "hooks.example.com", allowPrivate: true, wantDenied: true},
+		{name: "host in allowlist", allowedHosts: "127.0.0.1", allowPrivate: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := newWebhookEgressPolicy(tc.allowedHosts, tc.allowedCIDRs, tc.allowPrivate)
+			if err != nil {
+				t.Fatalf("build policy: %v", err)
+			}
+			sender := newWebhookSender(newWebhookClient(policy))
+			err = sender(context.Background(), submissionmanager.WebhookDelivery{
+				URL:  server.URL,
+				Body: []byte(`{"ok":true}`),
+			})
+			var denied submissionmanager.WebhookEgressDeniedError
+			if tc.wantDenied {
+				if !errors.As(err, &denied) {
+					t.Fatalf("expected egress denied error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("send webhook: %v", err)
+			}
+		})
+	}
+}
+
+func TestWebhookEgressPolicyBlocksNonPublicAddresses(t *testing.T) {
+	policy, err := newWebhookEgressPolicy("*.example.com", "", false)
+	if err != nil {
+		t.Fatalf("build policy: %v", err)
+	}
+	for _, address := range []string{"169.254.169.254:80", "10.0.0.5:443", "[::1]:8080", "100.64.1.1:80"} {
+		if err := policy.checkAddress(address); err == nil {
+			t.Fatalf("expected %s to be blocked", address)
+		}
+	}
+	if err := policy.checkAddress(net.JoinHostPort("93.184.216.34", "443")); err != nil {
+		t.Fatalf("expected public address to be allowed, got %v", err)
+	}
+	if err := policy.checkHost("hooks.example.com"); err != nil {
+		t.Fatalf("expected wildcard host to be allowed, got %v", err)
+	}
+	if err := policy.checkHost("example.com.evil.test"); err == nil {
+		t.Fatal("expected unrelated host to be denied")
+	}
+}
+
+func TestNewWebhookEgressPolicyRejectsInvalidCIDR(t *testing.T) {
+	if _, err := newWebhookEgressPolicy("", "10.0.0.0/33", false); err == nil {
+		t.Fatal("expected error")
+	}
+}
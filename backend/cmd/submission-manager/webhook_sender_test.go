@@ -74,3 +74,42 @@ func TestWebhookSenderMissingEnvFails(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+func TestWebhookSenderSignsCloudEventsBinaryBody(t *testing.T) {
+	t.Setenv("WEBHOOK_SECRET", "secret-value")
+
+	var gotSignature string
+	var gotEventID string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get("X-Setu-Signature")
+		gotEventID = r.Header.Get("ce-id")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newWebhookSender(server.Client())
+	delivery := submissionmanager.WebhookDelivery{
+		URL:       server.URL,
+		SecretEnv: "WEBHOOK_SECRET",
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+			"ce-id":        "intent-1",
+		},
+		Body: []byte(`{"intentId":"intent-1"}`),
+	}
+	if err := sender(context.Background(), delivery); err != nil {
+		t.Fatalf("send webhook: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret-value"))
+	_, _ = mac.Write(gotBody)
+	if gotSignature != hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("expected signature over event data body, got %q", gotSignature)
+	}
+	if gotEventID != "intent-1" {
+		t.Fatalf("expected ce-id header, got %q", gotEventID)
+	}
+}
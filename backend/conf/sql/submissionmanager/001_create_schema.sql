@@ -1,3 +1,6 @@
+-- Migration 001, the baseline. The IF ... guards below upgrade databases created before versioned
+-- migrations; new schema changes go in a new NNN_name.sql file (see `submission-manager migrate`).
+
 SET ANSI_NULLS ON;
 SET QUOTED_IDENTIFIER ON;
 
@@ -13,8 +16,14 @@ BEGIN
     policy NVARCHAR(32) NOT NULL,
     max_acceptance_seconds INT NULL,
     max_attempts INT NULL,
+    attempt_timeout_seconds INT NULL,
+    unresolved_send NVARCHAR(16) NULL,
+    budget_exempt_error_classes NVARCHAR(MAX) NULL,
+    max_exempt_attempts INT NULL,
+    budget_exempt_backpressure BIT NOT NULL DEFAULT 0,
     terminal_outcomes NVARCHAR(MAX) NOT NULL,
     webhook_url NVARCHAR(512) NULL,
+    webhook_format NVARCHAR(32) NULL,
     webhook_headers NVARCHAR(MAX) NULL,
     webhook_headers_env NVARCHAR(MAX) NULL,
     webhook_secret_env NVARCHAR(256) NULL,
@@ -22,16 +31,30 @@ BEGIN
     webhook_attempted_at DATETIME2(7) NULL,
     webhook_delivered_at DATETIME2(7) NULL,
     webhook_error NVARCHAR(512) NULL,
+    webhook_redelivery_count INT NOT NULL DEFAULT 0,
+    webhook_redelivery_status NVARCHAR(32) NULL,
+    webhook_redelivery_attempted_at DATETIME2(7) NULL,
+    webhook_redelivery_error NVARCHAR(512) NULL,
+    callback_url NVARCHAR(512) NULL,
+    callback_secret_env NVARCHAR(256) NULL,
+    trace_parent NVARCHAR(55) NULL,
+    trace_state NVARCHAR(512) NULL,
     status NVARCHAR(32) NOT NULL,
     final_outcome_status NVARCHAR(32) NULL,
     final_outcome_reason NVARCHAR(64) NULL,
     exhausted_reason NVARCHAR(64) NULL,
     -- attempt_count is the authoritative attempt number source.
     attempt_count INT NOT NULL DEFAULT 0,
+    -- prior_send_unresolved is set while a timed-out send may still be in flight at the gateway.
+    prior_send_unresolved BIT NOT NULL DEFAULT 0,
+    -- charged_attempt_count counts attempts that consumed the policy's attempt budget.
+    charged_attempt_count INT NOT NULL DEFAULT 0,
     created_at DATETIME2(7) NOT NULL,
     updated_at DATETIME2(7) NOT NULL,
     last_modified_at DATETIME2(7) NOT NULL,
-    next_attempt_at DATETIME2(7) NULL
+    next_attempt_at DATETIME2(7) NULL,
+    -- partition_key assigns the intent to lease partition partition_key % partitions.
+    partition_key AS (CAST(SUBSTRING(HASHBYTES('SHA2_256', intent_id), 1, 4) AS INT) & 2147483647) PERSISTED
   );
 END;
 
@@ -47,6 +70,18 @@ BEGIN
   );
 END;
 
+IF OBJECT_ID('dbo.submission_manager_members', 'U') IS NULL
+BEGIN
+  CREATE TABLE dbo.submission_manager_members (
+    lease_name NVARCHAR(64) NOT NULL,
+    holder_id NVARCHAR(128) NOT NULL,
+    heartbeat_at DATETIME2(7) NOT NULL,
+    expires_at DATETIME2(7) NOT NULL,
+    partition_count INT NOT NULL,
+    CONSTRAINT PK_submission_manager_members PRIMARY KEY (lease_name, holder_id)
+  );
+END;
+
 IF COL_LENGTH('dbo.submission_intents', 'mode') IS NOT NULL
 BEGIN
   ALTER TABLE dbo.submission_intents DROP COLUMN mode;
@@ -57,6 +92,11 @@ BEGIN
   ALTER TABLE dbo.submission_intents ADD webhook_url NVARCHAR(512) NULL;
 END;
 
+IF COL_LENGTH('dbo.submission_intents', 'webhook_format') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD webhook_format NVARCHAR(32) NULL;
+END;
+
 IF COL_LENGTH('dbo.submission_intents', 'webhook_headers') IS NULL
 BEGIN
   ALTER TABLE dbo.submission_intents ADD webhook_headers NVARCHAR(MAX) NULL;
@@ -92,6 +132,91 @@ BEGIN
   ALTER TABLE dbo.submission_intents ADD webhook_error NVARCHAR(512) NULL;
 END;
 
+IF COL_LENGTH('dbo.submission_intents', 'webhook_redelivery_count') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents
+    ADD webhook_redelivery_count INT NOT NULL
+      CONSTRAINT DF_submission_intents_webhook_redelivery_count DEFAULT 0;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'webhook_redelivery_status') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD webhook_redelivery_status NVARCHAR(32) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'webhook_redelivery_attempted_at') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD webhook_redelivery_attempted_at DATETIME2(7) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'webhook_redelivery_error') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD webhook_redelivery_error NVARCHAR(512) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'callback_url') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD callback_url NVARCHAR(512) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'callback_secret_env') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD callback_secret_env NVARCHAR(256) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'trace_parent') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD trace_parent NVARCHAR(55) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'trace_state') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD trace_state NVARCHAR(512) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'attempt_timeout_seconds') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD attempt_timeout_seconds INT NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'prior_send_unresolved') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents
+    ADD prior_send_unresolved BIT NOT NULL
+      CONSTRAINT DF_submission_intents_prior_send_unresolved DEFAULT 0;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'unresolved_send') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD unresolved_send NVARCHAR(16) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'budget_exempt_error_classes') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD budget_exempt_error_classes NVARCHAR(MAX) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'budget_exempt_backpressure') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents
+    ADD budget_exempt_backpressure BIT NOT NULL
+      CONSTRAINT DF_submission_intents_budget_exempt_backpressure DEFAULT 0;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'charged_attempt_count') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents
+    ADD charged_attempt_count INT NOT NULL
+      CONSTRAINT DF_submission_intents_charged_attempt_count DEFAULT 0;
+  -- Non-obvious constraint: every attempt before error classes existed was charged.
+  EXEC('UPDATE dbo.submission_intents SET charged_attempt_count = attempt_count');
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'max_exempt_attempts') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD max_exempt_attempts INT NULL;
+END;
+
 IF COL_LENGTH('dbo.submission_intents', 'last_modified_at') IS NULL
 BEGIN
   ALTER TABLE dbo.submission_intents
@@ -99,6 +224,12 @@ BEGIN
       CONSTRAINT DF_submission_intents_last_modified_at DEFAULT SYSUTCDATETIME();
 END;
 
+IF COL_LENGTH('dbo.submission_intents', 'partition_key') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents
+    ADD partition_key AS (CAST(SUBSTRING(HASHBYTES('SHA2_256', intent_id), 1, 4) AS INT) & 2147483647) PERSISTED;
+END;
+
 IF OBJECT_ID('dbo.submission_attempts', 'U') IS NULL
 BEGIN
   CREATE TABLE dbo.submission_attempts (
@@ -109,12 +240,18 @@ BEGIN
     outcome_status NVARCHAR(32) NULL,
     outcome_reason NVARCHAR(64) NULL,
     error NVARCHAR(512) NULL,
+    error_class NVARCHAR(32) NULL,
     CONSTRAINT PK_submission_attempts PRIMARY KEY (intent_id, attempt_number),
     CONSTRAINT FK_submission_attempts_intent FOREIGN KEY (intent_id)
       REFERENCES dbo.submission_intents(intent_id) ON DELETE CASCADE
   );
 END;
 
+IF COL_LENGTH('dbo.submission_attempts', 'error_class') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_attempts ADD error_class NVARCHAR(32) NULL;
+END;
+
 IF NOT EXISTS (
   SELECT 1
   FROM sys.indexes
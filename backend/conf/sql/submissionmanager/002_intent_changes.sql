@@ -0,0 +1,18 @@
+-- Migration 002: intent change tracking. SQL Server stamps change_version on every insert and update
+-- of an intent; WaitForIntent polls for versions past a high-water mark taken with
+-- MIN_ACTIVE_ROWVERSION(), below which every version is committed.
+
+IF COL_LENGTH('dbo.submission_intents', 'change_version') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD change_version ROWVERSION;
+END;
+
+IF NOT EXISTS (
+  SELECT 1
+  FROM sys.indexes
+  WHERE name = 'idx_submission_intents_change_version'
+    AND object_id = OBJECT_ID('dbo.submission_intents')
+)
+BEGIN
+  EXEC('CREATE INDEX idx_submission_intents_change_version ON dbo.submission_intents(change_version)');
+END;
@@ -0,0 +1,16 @@
+-- Migration 003: attempt claims. A claim row is written, fenced by the partition lease, before an
+-- attempt calls the gateway and is deleted in the transaction that records the attempt. A claim
+-- that survives a leader change marks a send whose outcome is unknown.
+
+IF OBJECT_ID('dbo.submission_attempt_claims', 'U') IS NULL
+BEGIN
+  CREATE TABLE dbo.submission_attempt_claims (
+    intent_id NVARCHAR(200) NOT NULL PRIMARY KEY,
+    attempt_number INT NOT NULL,
+    holder_id NVARCHAR(128) NOT NULL,
+    lease_epoch BIGINT NOT NULL,
+    claimed_at DATETIME2(7) NOT NULL,
+    CONSTRAINT FK_submission_attempt_claims_intent FOREIGN KEY (intent_id)
+      REFERENCES dbo.submission_intents(intent_id) ON DELETE CASCADE
+  );
+END;
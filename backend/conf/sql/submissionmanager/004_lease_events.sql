@@ -0,0 +1,18 @@
+-- Migration 004: lease history. One row per partition lease transition (acquired, renew_failed,
+-- lost, released) for the admin schedule view; renewals are not recorded.
+
+IF OBJECT_ID('dbo.submission_manager_lease_events', 'U') IS NULL
+BEGIN
+  CREATE TABLE dbo.submission_manager_lease_events (
+    event_id BIGINT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+    lease_name NVARCHAR(64) NOT NULL,
+    partition_number INT NOT NULL,
+    holder_id NVARCHAR(128) NOT NULL,
+    lease_epoch BIGINT NOT NULL,
+    event_type NVARCHAR(32) NOT NULL,
+    detail NVARCHAR(512) NULL,
+    occurred_at DATETIME2(7) NOT NULL
+  );
+  CREATE INDEX idx_submission_manager_lease_events_occurred
+    ON dbo.submission_manager_lease_events(lease_name, occurred_at);
+END;
@@ -0,0 +1,12 @@
+-- Migration 005: drain mode. A single row (drain_id = 1) shared by every instance; while draining,
+-- POST /v1/intents refuses new intent IDs. No row means not draining.
+
+IF OBJECT_ID('dbo.submission_manager_drain', 'U') IS NULL
+BEGIN
+  CREATE TABLE dbo.submission_manager_drain (
+    drain_id INT NOT NULL PRIMARY KEY CHECK (drain_id = 1),
+    draining BIT NOT NULL,
+    reason NVARCHAR(256) NULL,
+    updated_at DATETIME2(7) NOT NULL
+  );
+END;
@@ -0,0 +1,12 @@
+-- Migration 006: per-outcome retry rules. The contract snapshot keeps outcomeRules and
+-- errorClassRules as JSON objects; NULL means no rules.
+
+IF COL_LENGTH('dbo.submission_intents', 'outcome_rules') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD outcome_rules NVARCHAR(MAX) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'error_class_rules') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD error_class_rules NVARCHAR(MAX) NULL;
+END;
@@ -0,0 +1,12 @@
+-- Migration 007: delivery windows. delivery_window is the contract snapshot's window as JSON (NULL
+-- means none); deferred_ms accumulates the time attempts were deferred to a window opening.
+
+IF COL_LENGTH('dbo.submission_intents', 'delivery_window') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD delivery_window NVARCHAR(MAX) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'deferred_ms') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD deferred_ms BIGINT NOT NULL DEFAULT 0;
+END;
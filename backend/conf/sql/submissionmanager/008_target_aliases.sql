@@ -0,0 +1,7 @@
+-- Migration 008: submissionTarget aliases. target_alias is the alias an intent was submitted to;
+-- submission_target stays the concrete target it resolved to. NULL means no alias.
+
+IF COL_LENGTH('dbo.submission_intents', 'target_alias') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD target_alias NVARCHAR(200) NULL;
+END;
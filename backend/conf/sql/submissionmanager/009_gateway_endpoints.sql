@@ -0,0 +1,12 @@
+-- Migration 009: multiple gateway endpoints per target. gateway_urls is the snapshot's endpoint list as
+-- a JSON array and endpoint_strategy its selection strategy; NULL means the single gateway_url.
+
+IF COL_LENGTH('dbo.submission_intents', 'gateway_urls') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD gateway_urls NVARCHAR(MAX) NULL;
+END;
+
+IF COL_LENGTH('dbo.submission_intents', 'endpoint_strategy') IS NULL
+BEGIN
+  ALTER TABLE dbo.submission_intents ADD endpoint_strategy NVARCHAR(32) NULL;
+END;
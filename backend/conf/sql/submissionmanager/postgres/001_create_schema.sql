@@ -0,0 +1,95 @@
+-- PostgreSQL schema for the submission manager. Mirrors ../001_create_schema.sql: timestamps are
+-- UTC wall-clock values without a time zone, and utc_now() plays the role of SYSUTCDATETIME() so
+-- the database clock stays authoritative for every lease and schedule comparison.
+
+CREATE OR REPLACE FUNCTION utc_now() RETURNS TIMESTAMP(6)
+  LANGUAGE sql VOLATILE
+  AS $$ SELECT clock_timestamp() AT TIME ZONE 'UTC' $$;
+
+CREATE TABLE IF NOT EXISTS submission_intents (
+  intent_id VARCHAR(200) NOT NULL PRIMARY KEY,
+  submission_target VARCHAR(200) NOT NULL,
+  payload BYTEA NOT NULL,
+  payload_hash BYTEA NOT NULL,
+  gateway_type VARCHAR(32) NOT NULL,
+  gateway_url VARCHAR(512) NOT NULL,
+  policy VARCHAR(32) NOT NULL,
+  max_acceptance_seconds INTEGER NULL,
+  max_attempts INTEGER NULL,
+  attempt_timeout_seconds INTEGER NULL,
+  unresolved_send VARCHAR(16) NULL,
+  budget_exempt_error_classes TEXT NULL,
+  max_exempt_attempts INTEGER NULL,
+  budget_exempt_backpressure BOOLEAN NOT NULL DEFAULT FALSE,
+  terminal_outcomes TEXT NOT NULL,
+  webhook_url VARCHAR(512) NULL,
+  webhook_format VARCHAR(32) NULL,
+  webhook_headers TEXT NULL,
+  webhook_headers_env TEXT NULL,
+  webhook_secret_env VARCHAR(256) NULL,
+  webhook_status VARCHAR(32) NULL,
+  webhook_attempted_at TIMESTAMP(6) NULL,
+  webhook_delivered_at TIMESTAMP(6) NULL,
+  webhook_error VARCHAR(512) NULL,
+  webhook_redelivery_count INTEGER NOT NULL DEFAULT 0,
+  webhook_redelivery_status VARCHAR(32) NULL,
+  webhook_redelivery_attempted_at TIMESTAMP(6) NULL,
+  webhook_redelivery_error VARCHAR(512) NULL,
+  callback_url VARCHAR(512) NULL,
+  callback_secret_env VARCHAR(256) NULL,
+  trace_parent VARCHAR(55) NULL,
+  trace_state VARCHAR(512) NULL,
+  status VARCHAR(32) NOT NULL,
+  final_outcome_status VARCHAR(32) NULL,
+  final_outcome_reason VARCHAR(64) NULL,
+  exhausted_reason VARCHAR(64) NULL,
+  -- attempt_count is the authoritative attempt number source.
+  attempt_count INTEGER NOT NULL DEFAULT 0,
+  -- prior_send_unresolved is set while a timed-out send may still be in flight at the gateway.
+  prior_send_unresolved BOOLEAN NOT NULL DEFAULT FALSE,
+  -- charged_attempt_count counts attempts that consumed the policy's attempt budget.
+  charged_attempt_count INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP(6) NOT NULL,
+  updated_at TIMESTAMP(6) NOT NULL,
+  last_modified_at TIMESTAMP(6) NOT NULL,
+  next_attempt_at TIMESTAMP(6) NULL,
+  -- partition_key is written by the store (SHA-256 of the UTF-16LE intent id, as SQL Server computes it).
+  partition_key INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS submission_manager_leases (
+  lease_name VARCHAR(64) NOT NULL PRIMARY KEY,
+  holder_id VARCHAR(128) NOT NULL,
+  lease_epoch BIGINT NOT NULL,
+  acquired_at TIMESTAMP(6) NOT NULL,
+  renewed_at TIMESTAMP(6) NOT NULL,
+  expires_at TIMESTAMP(6) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS submission_manager_members (
+  lease_name VARCHAR(64) NOT NULL,
+  holder_id VARCHAR(128) NOT NULL,
+  heartbeat_at TIMESTAMP(6) NOT NULL,
+  expires_at TIMESTAMP(6) NOT NULL,
+  partition_count INTEGER NOT NULL,
+  CONSTRAINT pk_submission_manager_members PRIMARY KEY (lease_name, holder_id)
+);
+
+CREATE TABLE IF NOT EXISTS submission_attempts (
+  intent_id VARCHAR(200) NOT NULL REFERENCES submission_intents(intent_id) ON DELETE CASCADE,
+  attempt_number INTEGER NOT NULL,
+  started_at TIMESTAMP(6) NOT NULL,
+  finished_at TIMESTAMP(6) NOT NULL,
+  outcome_status VARCHAR(32) NULL,
+  outcome_reason VARCHAR(64) NULL,
+  error VARCHAR(512) NULL,
+  error_class VARCHAR(32) NULL,
+  CONSTRAINT pk_submission_attempts PRIMARY KEY (intent_id, attempt_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_intents_next_attempt
+  ON submission_intents(next_attempt_at)
+  WHERE next_attempt_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_submission_intents_last_modified
+  ON submission_intents(last_modified_at, intent_id);
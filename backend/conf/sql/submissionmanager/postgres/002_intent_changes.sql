@@ -0,0 +1,22 @@
+-- Migration 002: intent change tracking. Mirrors ../002_intent_changes.sql: a trigger stamps
+-- change_xid with the writing transaction's ID, and the high-water mark is the oldest transaction
+-- still running, below which every stamp is committed.
+
+ALTER TABLE submission_intents ADD COLUMN IF NOT EXISTS change_xid XID8 NULL;
+
+CREATE OR REPLACE FUNCTION stamp_intent_change() RETURNS TRIGGER
+  LANGUAGE plpgsql
+  AS $$
+BEGIN
+  NEW.change_xid := pg_current_xact_id();
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS submission_intents_change ON submission_intents;
+CREATE TRIGGER submission_intents_change
+  BEFORE INSERT OR UPDATE ON submission_intents
+  FOR EACH ROW EXECUTE FUNCTION stamp_intent_change();
+
+CREATE INDEX IF NOT EXISTS idx_submission_intents_change_xid
+  ON submission_intents(change_xid);
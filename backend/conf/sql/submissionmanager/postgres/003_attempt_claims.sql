@@ -0,0 +1,9 @@
+-- Migration 003: attempt claims. Mirrors ../003_attempt_claims.sql.
+
+CREATE TABLE IF NOT EXISTS submission_attempt_claims (
+  intent_id VARCHAR(200) NOT NULL PRIMARY KEY REFERENCES submission_intents(intent_id) ON DELETE CASCADE,
+  attempt_number INTEGER NOT NULL,
+  holder_id VARCHAR(128) NOT NULL,
+  lease_epoch BIGINT NOT NULL,
+  claimed_at TIMESTAMP(6) NOT NULL
+);
@@ -0,0 +1,15 @@
+-- Migration 004: lease history. Mirrors ../004_lease_events.sql.
+
+CREATE TABLE IF NOT EXISTS submission_manager_lease_events (
+  event_id BIGSERIAL PRIMARY KEY,
+  lease_name VARCHAR(64) NOT NULL,
+  partition_number INTEGER NOT NULL,
+  holder_id VARCHAR(128) NOT NULL,
+  lease_epoch BIGINT NOT NULL,
+  event_type VARCHAR(32) NOT NULL,
+  detail VARCHAR(512) NULL,
+  occurred_at TIMESTAMP(6) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_manager_lease_events_occurred
+  ON submission_manager_lease_events(lease_name, occurred_at);
@@ -0,0 +1,4 @@
+-- Migration 007: delivery windows. Mirrors ../007_delivery_windows.sql.
+
+ALTER TABLE submission_intents ADD COLUMN IF NOT EXISTS delivery_window TEXT NULL;
+ALTER TABLE submission_intents ADD COLUMN IF NOT EXISTS deferred_ms BIGINT NOT NULL DEFAULT 0;
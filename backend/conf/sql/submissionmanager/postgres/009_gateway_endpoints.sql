@@ -0,0 +1,4 @@
+-- Migration 009: multiple gateway endpoints per target. Mirrors ../009_gateway_endpoints.sql.
+
+ALTER TABLE submission_intents ADD COLUMN IF NOT EXISTS gateway_urls TEXT NULL;
+ALTER TABLE submission_intents ADD COLUMN IF NOT EXISTS endpoint_strategy VARCHAR(32) NULL;
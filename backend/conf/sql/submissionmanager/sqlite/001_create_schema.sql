@@ -0,0 +1,91 @@
+-- SQLite schema for the embedded submission manager store. Mirrors ../001_create_schema.sql.
+-- Timestamps are fixed-width UTC text (YYYY-MM-DD HH:MM:SS.fffffffff) so string comparison orders
+-- them; the store writes every timestamp, including "now", from the process clock.
+
+CREATE TABLE IF NOT EXISTS submission_intents (
+  intent_id TEXT NOT NULL PRIMARY KEY,
+  submission_target TEXT NOT NULL,
+  payload BLOB NOT NULL,
+  payload_hash BLOB NOT NULL,
+  gateway_type TEXT NOT NULL,
+  gateway_url TEXT NOT NULL,
+  policy TEXT NOT NULL,
+  max_acceptance_seconds INTEGER NULL,
+  max_attempts INTEGER NULL,
+  attempt_timeout_seconds INTEGER NULL,
+  unresolved_send TEXT NULL,
+  budget_exempt_error_classes TEXT NULL,
+  max_exempt_attempts INTEGER NULL,
+  budget_exempt_backpressure BOOLEAN NOT NULL DEFAULT 0,
+  terminal_outcomes TEXT NOT NULL,
+  webhook_url TEXT NULL,
+  webhook_format TEXT NULL,
+  webhook_headers TEXT NULL,
+  webhook_headers_env TEXT NULL,
+  webhook_secret_env TEXT NULL,
+  webhook_status TEXT NULL,
+  webhook_attempted_at DATETIME NULL,
+  webhook_delivered_at DATETIME NULL,
+  webhook_error TEXT NULL,
+  webhook_redelivery_count INTEGER NOT NULL DEFAULT 0,
+  webhook_redelivery_status TEXT NULL,
+  webhook_redelivery_attempted_at DATETIME NULL,
+  webhook_redelivery_error TEXT NULL,
+  callback_url TEXT NULL,
+  callback_secret_env TEXT NULL,
+  trace_parent TEXT NULL,
+  trace_state TEXT NULL,
+  status TEXT NOT NULL,
+  final_outcome_status TEXT NULL,
+  final_outcome_reason TEXT NULL,
+  exhausted_reason TEXT NULL,
+  -- attempt_count is the authoritative attempt number source.
+  attempt_count INTEGER NOT NULL DEFAULT 0,
+  -- prior_send_unresolved is set while a timed-out send may still be in flight at the gateway.
+  prior_send_unresolved BOOLEAN NOT NULL DEFAULT 0,
+  -- charged_attempt_count counts attempts that consumed the policy's attempt budget.
+  charged_attempt_count INTEGER NOT NULL DEFAULT 0,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  last_modified_at DATETIME NOT NULL,
+  next_attempt_at DATETIME NULL,
+  -- partition_key is written by the store (SHA-256 of the UTF-16LE intent id, as SQL Server computes it).
+  partition_key INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS submission_manager_leases (
+  lease_name TEXT NOT NULL PRIMARY KEY,
+  holder_id TEXT NOT NULL,
+  lease_epoch INTEGER NOT NULL,
+  acquired_at DATETIME NOT NULL,
+  renewed_at DATETIME NOT NULL,
+  expires_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS submission_manager_members (
+  lease_name TEXT NOT NULL,
+  holder_id TEXT NOT NULL,
+  heartbeat_at DATETIME NOT NULL,
+  expires_at DATETIME NOT NULL,
+  partition_count INTEGER NOT NULL,
+  PRIMARY KEY (lease_name, holder_id)
+);
+
+CREATE TABLE IF NOT EXISTS submission_attempts (
+  intent_id TEXT NOT NULL REFERENCES submission_intents(intent_id) ON DELETE CASCADE,
+  attempt_number INTEGER NOT NULL,
+  started_at DATETIME NOT NULL,
+  finished_at DATETIME NOT NULL,
+  outcome_status TEXT NULL,
+  outcome_reason TEXT NULL,
+  error TEXT NULL,
+  error_class TEXT NULL,
+  PRIMARY KEY (intent_id, attempt_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_intents_next_attempt
+  ON submission_intents(next_attempt_at)
+  WHERE next_attempt_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_submission_intents_last_modified
+  ON submission_intents(last_modified_at, intent_id);
@@ -0,0 +1,25 @@
+-- Migration 002: intent change tracking. Mirrors ../002_intent_changes.sql: triggers stamp change_seq
+-- from a counter that only grows, which is safe because SQLite runs one writer at a time and intents
+-- are never deleted.
+
+ALTER TABLE submission_intents ADD COLUMN change_seq INTEGER NULL;
+
+CREATE INDEX IF NOT EXISTS idx_submission_intents_change_seq
+  ON submission_intents(change_seq);
+
+CREATE TRIGGER IF NOT EXISTS submission_intents_change_insert
+  AFTER INSERT ON submission_intents
+BEGIN
+  UPDATE submission_intents
+  SET change_seq = (SELECT COALESCE(MAX(change_seq), 0) + 1 FROM submission_intents)
+  WHERE intent_id = NEW.intent_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS submission_intents_change_update
+  AFTER UPDATE ON submission_intents
+  WHEN NEW.change_seq IS OLD.change_seq
+BEGIN
+  UPDATE submission_intents
+  SET change_seq = (SELECT COALESCE(MAX(change_seq), 0) + 1 FROM submission_intents)
+  WHERE intent_id = NEW.intent_id;
+END;
@@ -0,0 +1,9 @@
+-- Migration 003: attempt claims. Mirrors ../003_attempt_claims.sql.
+
+CREATE TABLE IF NOT EXISTS submission_attempt_claims (
+  intent_id TEXT NOT NULL PRIMARY KEY REFERENCES submission_intents(intent_id) ON DELETE CASCADE,
+  attempt_number INTEGER NOT NULL,
+  holder_id TEXT NOT NULL,
+  lease_epoch INTEGER NOT NULL,
+  claimed_at DATETIME NOT NULL
+);
@@ -0,0 +1,15 @@
+-- Migration 004: lease history. Mirrors ../004_lease_events.sql.
+
+CREATE TABLE IF NOT EXISTS submission_manager_lease_events (
+  event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  lease_name TEXT NOT NULL,
+  partition_number INTEGER NOT NULL,
+  holder_id TEXT NOT NULL,
+  lease_epoch INTEGER NOT NULL,
+  event_type TEXT NOT NULL,
+  detail TEXT NULL,
+  occurred_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_manager_lease_events_occurred
+  ON submission_manager_lease_events(lease_name, occurred_at);
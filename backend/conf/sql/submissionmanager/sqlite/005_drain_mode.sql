@@ -0,0 +1,8 @@
+-- Migration 005: drain mode. Mirrors ../005_drain_mode.sql.
+
+CREATE TABLE IF NOT EXISTS submission_manager_drain (
+  drain_id INTEGER NOT NULL PRIMARY KEY CHECK (drain_id = 1),
+  draining INTEGER NOT NULL,
+  reason TEXT NULL,
+  updated_at DATETIME NOT NULL
+);
@@ -0,0 +1,4 @@
+-- Migration 006: per-outcome retry rules. Mirrors ../006_retry_rules.sql.
+
+ALTER TABLE submission_intents ADD COLUMN outcome_rules TEXT NULL;
+ALTER TABLE submission_intents ADD COLUMN error_class_rules TEXT NULL;
@@ -0,0 +1,4 @@
+-- Migration 007: delivery windows. Mirrors ../007_delivery_windows.sql.
+
+ALTER TABLE submission_intents ADD COLUMN delivery_window TEXT NULL;
+ALTER TABLE submission_intents ADD COLUMN deferred_ms INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Migration 008: submissionTarget aliases. Mirrors ../008_target_aliases.sql.
+
+ALTER TABLE submission_intents ADD COLUMN target_alias TEXT NULL;
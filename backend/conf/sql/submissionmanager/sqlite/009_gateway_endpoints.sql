@@ -0,0 +1,4 @@
+-- Migration 009: multiple gateway endpoints per target. Mirrors ../009_gateway_endpoints.sql.
+
+ALTER TABLE submission_intents ADD COLUMN gateway_urls TEXT NULL;
+ALTER TABLE submission_intents ADD COLUMN endpoint_strategy TEXT NULL;
@@ -0,0 +1,136 @@
+// Package logging configures the process-wide log/slog logger with a shared field schema.
+package logging
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Field keys shared by every service so log pipelines can join lines across components.
+const (
+	KeyService     = "service"
+	KeyInstance    = "instance"
+	KeyIntentID    = "intentId"
+	KeyReferenceID = "referenceId"
+	KeyAttempt     = "attempt"
+	KeyProvider    = "provider"
+	KeyTenantID    = "tenantId"
+)
+
+// Config selects the log level and output format for a process.
+type Config struct {
+	Service string
+	// Instance defaults to the hostname.
+	Instance string
+	// Level is debug, info, warn, or error.
+	Level string
+	// Format is json or text.
+	Format string
+}
+
+// Flags holds the -log-level and -log-format flag values.
+type Flags struct {
+	level  *string
+	format *string
+}
+
+// RegisterFlags adds -log-level (LOG_LEVEL) and -log-format (LOG_FORMAT) to fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	return &Flags{
+		level:  fs.String("log-level", envOrDefault("LOG_LEVEL", "info"), "Log level: debug, info, warn, or error"),
+		format: fs.String("log-format", envOrDefault("LOG_FORMAT", "json"), "Log format: json or text"),
+	}
+}
+
+// Setup configures the default logger for service from the parsed flags.
+func (f *Flags) Setup(service string) (*slog.Logger, error) {
+	return Setup(os.Stderr, Config{Service: service, Level: *f.level, Format: *f.format})
+}
+
+// Setup builds a logger writing to w, tags it with service and instance, and installs it as the
+// slog default. Non-obvious constraint: slog.SetDefault also routes the standard log package
+// through this handler, so any remaining log.Printf output follows the same format.
+func Setup(w io.Writer, cfg Config) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(strings.TrimSpace(cfg.Format)) {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want json or text)", cfg.Format)
+	}
+	instance := strings.TrimSpace(cfg.Instance)
+	if instance == "" {
+		instance, _ = os.Hostname()
+	}
+	logger := slog.New(handler).With(
+		slog.String(KeyService, cfg.Service),
+		slog.String(KeyInstance, instance),
+	)
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+// ParseLevel parses debug, info, warn, or error; empty means info.
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q (want debug, info, warn, or error)", value)
+	}
+}
+
+// Fatal logs msg at error level and exits, replacing log.Fatal.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// IntentID returns the intentId attribute.
+func IntentID(value string) slog.Attr {
+	return slog.String(KeyIntentID, value)
+}
+
+// ReferenceID returns the referenceId attribute.
+func ReferenceID(value string) slog.Attr {
+	return slog.String(KeyReferenceID, value)
+}
+
+// Attempt returns the attempt number attribute.
+func Attempt(number int) slog.Attr {
+	return slog.Int(KeyAttempt, number)
+}
+
+// Provider returns the provider attribute.
+func Provider(name string) slog.Attr {
+	return slog.String(KeyProvider, name)
+}
+
+// TenantID returns the tenantId attribute.
+func TenantID(value string) slog.Attr {
+	return slog.String(KeyTenantID, value)
+}
+
+func envOrDefault(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
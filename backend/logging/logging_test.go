@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"testing"
+)
+
+func TestSetupJSONSchema(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	var buf bytes.Buffer
+	logger, err := Setup(&buf, Config{Service: "sms-gateway", Instance: "host-1", Level: "info", Format: "json"})
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	logger.Debug("hidden")
+	logger.Info("sms decision", ReferenceID("ref-1"), Provider("model"), TenantID("t-1"), IntentID("intent-1"), Attempt(2))
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decode log line %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"msg":         "sms decision",
+		"level":       "INFO",
+		"service":     "sms-gateway",
+		"instance":    "host-1",
+		"referenceId": "ref-1",
+		"provider":    "model",
+		"tenantId":    "t-1",
+		"intentId":    "intent-1",
+		"attempt":     float64(2),
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Fatalf("expected %s=%v, got %v (line %s)", key, value, entry[key], buf.String())
+		}
+	}
+}
+
+func TestSetupRoutesStandardLog(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	var buf bytes.Buffer
+	if _, err := Setup(&buf, Config{Service: "svc", Instance: "host-1"}); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	log.Printf("legacy line")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decode log line %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "legacy line" || entry["service"] != "svc" {
+		t.Fatalf("unexpected entry: %v", entry)
+	}
+}
+
+func TestSetupRejectsUnknownSettings(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Setup(&buf, Config{Level: "loud"}); err == nil {
+		t.Fatal("expected level error")
+	}
+	if _, err := Setup(&buf, Config{Format: "xml"}); err == nil {
+		t.Fatal("expected format error")
+	}
+}
@@ -55,6 +55,14 @@ func New(providerName string, buckets []time.Duration) *Registry {
 	}
 }
 
+// ProviderName returns the provider label for this registry.
+func (r *Registry) ProviderName() string {
+	if r == nil {
+		return ""
+	}
+	return r.providerName
+}
+
 // ObserveRequest records a gateway request outcome and its duration.
 func (r *Registry) ObserveRequest(outcome, reason string, duration time.Duration) {
 	if r == nil {
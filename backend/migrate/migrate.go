@@ -0,0 +1,322 @@
+// Package migrate applies ordered, checksummed SQL migration files and records them in a
+// schema_migrations table, so a service can tell which schema version a database is at.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect selects the SQL used for the schema_migrations table.
+type Dialect string
+
+const (
+	SQLServer Dialect = "sqlserver"
+	Postgres  Dialect = "postgres"
+	SQLite    Dialect = "sqlite"
+)
+
+// Migration is one NNN_name.sql file. Version is the numeric prefix; Checksum is the SHA-256 of the
+// file with line endings normalized, so a checkout with CRLF endings does not read as modified.
+type Migration struct {
+	Version  int
+	Name     string
+	SQL      string
+	Checksum string
+}
+
+// ID returns the migration's file stem, such as 001_create_schema.
+func (m Migration) ID() string {
+	return fmt.Sprintf("%03d_%s", m.Version, m.Name)
+}
+
+// State is a migration's standing relative to the database.
+type State string
+
+const (
+	StateApplied  State = "applied"
+	StatePending  State = "pending"
+	StateModified State = "modified"
+	StateUnknown  State = "unknown"
+)
+
+// Status reports one migration. StateUnknown marks a version recorded in the database that has no
+// file, typically because the database was migrated by a newer build.
+type Status struct {
+	Version   int
+	Name      string
+	State     State
+	AppliedAt time.Time
+}
+
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+// Load reads the *.sql files directly in dir, ordered by version. Every file must be named
+// NNN_name.sql and versions must be unique.
+func Load(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must match NNN_name.sql", entry.Name())
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %s: version must be a positive integer", entry.Name())
+		}
+		if previous, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", previous, entry.Name(), version)
+		}
+		seen[version] = entry.Name()
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		body := strings.ReplaceAll(string(data), "\r\n", "\n")
+		sum := sha256.Sum256([]byte(body))
+		migrations = append(migrations, Migration{
+			Version:  version,
+			Name:     match[2],
+			SQL:      body,
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("no migrations found in %s", dir)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Runner applies migrations to one database.
+type Runner struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+// NewRunner returns a Runner for migrations, which must be ordered as Load returns them.
+func NewRunner(db *sql.DB, dialect Dialect, migrations []Migration) (*Runner, error) {
+	if db == nil {
+		return nil, errors.New("db is required")
+	}
+	switch dialect {
+	case SQLServer, Postgres, SQLite:
+	default:
+		return nil, fmt.Errorf("unknown dialect %q", dialect)
+	}
+	if len(migrations) == 0 {
+		return nil, errors.New("at least one migration is required")
+	}
+	return &Runner{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// Version returns the highest migration version the runner knows.
+func (r *Runner) Version() int {
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Status reports every known migration in order, followed by any versions the database has
+// recorded that the runner does not know.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	if err := r.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := r.loadApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, migration := range r.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name, State: StatePending}
+		if row, ok := applied[migration.Version]; ok {
+			status.AppliedAt = row.appliedAt
+			status.State = StateApplied
+			if row.checksum != migration.Checksum {
+				status.State = StateModified
+			}
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	var unknown []Status
+	for version, row := range applied {
+		unknown = append(unknown, Status{Version: version, Name: row.name, State: StateUnknown, AppliedAt: row.appliedAt})
+	}
+	sort.Slice(unknown, func(i, j int) bool {
+		return unknown[i].Version < unknown[j].Version
+	})
+	return append(statuses, unknown...), nil
+}
+
+// Check returns an error unless every known migration is applied unchanged and the database has
+// no versions the runner does not know.
+func (r *Runner) Check(ctx context.Context) error {
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return compatible(statuses, r.Version())
+}
+
+// Up applies pending migrations in order, each in its own transaction together with its
+// schema_migrations row. It refuses to run when an applied migration was modified or the database
+// is ahead of the runner, and returns the migrations it applied.
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if status.State == StateModified || status.State == StateUnknown {
+			return nil, compatible(statuses, r.Version())
+		}
+	}
+	var applied []Migration
+	for i, migration := range r.migrations {
+		if statuses[i].State != StatePending {
+			continue
+		}
+		if err := r.apply(ctx, migration); err != nil {
+			return applied, fmt.Errorf("apply migration %s: %w", migration.ID(), err)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+func compatible(statuses []Status, version int) error {
+	var pending []string
+	for _, status := range statuses {
+		id := fmt.Sprintf("%03d_%s", status.Version, status.Name)
+		switch status.State {
+		case StateModified:
+			return fmt.Errorf("migration %s changed after it was applied", id)
+		case StateUnknown:
+			return fmt.Errorf("database has migration %s, newer than this build's version %d", id, version)
+		case StatePending:
+			pending = append(pending, id)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("schema is behind version %d: pending %s; run migrate up", version, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+func (r *Runner) apply(ctx context.Context, migration Migration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, r.insertSQL(), migration.Version, migration.Name, migration.Checksum); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type appliedRow struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+func (r *Runner) loadApplied(ctx context.Context) (map[int]appliedRow, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM `+r.table())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int]appliedRow)
+	for rows.Next() {
+		var version int
+		var row appliedRow
+		if err := rows.Scan(&version, &row.name, &row.checksum, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		row.checksum = strings.TrimSpace(row.checksum)
+		row.appliedAt = row.appliedAt.UTC()
+		applied[version] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+func (r *Runner) table() string {
+	if r.dialect == SQLServer {
+		return "dbo.schema_migrations"
+	}
+	return "schema_migrations"
+}
+
+func (r *Runner) ensureTable(ctx context.Context) error {
+	var stmt string
+	switch r.dialect {
+	case SQLServer:
+		stmt = `IF OBJECT_ID('dbo.schema_migrations', 'U') IS NULL
+BEGIN
+  CREATE TABLE dbo.schema_migrations (
+    version INT NOT NULL PRIMARY KEY,
+    name NVARCHAR(200) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    applied_at DATETIME2(7) NOT NULL CONSTRAINT DF_schema_migrations_applied_at DEFAULT SYSUTCDATETIME()
+  );
+END;`
+	case Postgres:
+		stmt = `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INTEGER NOT NULL PRIMARY KEY,
+  name TEXT NOT NULL,
+  checksum CHAR(64) NOT NULL,
+  applied_at TIMESTAMP(6) NOT NULL DEFAULT (clock_timestamp() AT TIME ZONE 'UTC')
+)`
+	case SQLite:
+		stmt = `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INTEGER NOT NULL PRIMARY KEY,
+  name TEXT NOT NULL,
+  checksum TEXT NOT NULL,
+  applied_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+)`
+	}
+	_, err := r.db.ExecContext(ctx, stmt)
+	return err
+}
+
+func (r *Runner) insertSQL() string {
+	switch r.dialect {
+	case SQLServer:
+		return `INSERT INTO dbo.schema_migrations (version, name, checksum) VALUES (@p1, @p2, @p3)`
+	case Postgres:
+		return `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`
+	default:
+		return `INSERT INTO schema_migrations (version, name, checksum) VALUES (?1, ?2, ?3)`
+	}
+}
@@ -0,0 +1,92 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeMigration(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestLoadOrdersByVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeMigration(t, dir, "010_add_index.sql", "CREATE INDEX x ON t(a);")
+	writeMigration(t, dir, "002_add_column.sql", "ALTER TABLE t ADD b INT;")
+	writeMigration(t, dir, "001_create_schema.sql", "CREATE TABLE t (a INT);")
+	writeMigration(t, dir, "README.md", "not a migration")
+	if err := os.Mkdir(filepath.Join(dir, "postgres"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	migrations, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	var ids []string
+	for _, migration := range migrations {
+		ids = append(ids, migration.ID())
+	}
+	if got := strings.Join(ids, ","); got != "001_create_schema,002_add_column,010_add_index" {
+		t.Fatalf("unexpected order %s", got)
+	}
+}
+
+func TestLoadRejectsBadNamesAndDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	writeMigration(t, dir, "create_schema.sql", "")
+	if _, err := Load(dir); err == nil {
+		t.Fatalf("expected unnumbered file to fail")
+	}
+
+	dir = t.TempDir()
+	writeMigration(t, dir, "001_create_schema.sql", "")
+	writeMigration(t, dir, "01_again.sql", "")
+	if _, err := Load(dir); err == nil {
+		t.Fatalf("expected duplicate version to fail")
+	}
+
+	if _, err := Load(t.TempDir()); err == nil {
+		t.Fatalf("expected empty directory to fail")
+	}
+}
+
+func TestChecksumIgnoresLineEndings(t *testing.T) {
+	lf := t.TempDir()
+	crlf := t.TempDir()
+	writeMigration(t, lf, "001_create_schema.sql", "CREATE TABLE t (a INT);\nCREATE TABLE u (b INT);\n")
+	writeMigration(t, crlf, "001_create_schema.sql", "CREATE TABLE t (a INT);\r\nCREATE TABLE u (b INT);\r\n")
+	a, err := Load(lf)
+	if err != nil {
+		t.Fatalf("load lf: %v", err)
+	}
+	b, err := Load(crlf)
+	if err != nil {
+		t.Fatalf("load crlf: %v", err)
+	}
+	if a[0].Checksum != b[0].Checksum {
+		t.Fatalf("expected equal checksums, got %s and %s", a[0].Checksum, b[0].Checksum)
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	applied := Status{Version: 1, Name: "create_schema", State: StateApplied}
+	if err := compatible([]Status{applied}, 1); err != nil {
+		t.Fatalf("expected applied schema to be compatible: %v", err)
+	}
+	cases := map[string][]Status{
+		"pending":  {applied, {Version: 2, Name: "add_column", State: StatePending}},
+		"modified": {{Version: 1, Name: "create_schema", State: StateModified}},
+		"unknown":  {applied, {Version: 2, Name: "add_column", State: StateUnknown}},
+	}
+	for name, statuses := range cases {
+		if err := compatible(statuses, 1); err == nil {
+			t.Fatalf("%s: expected incompatible schema", name)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProviderThrottledError reports that the provider asked callers to slow down.
+// RetryAfter is zero when the provider did not say how long to wait.
+type ProviderThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e ProviderThrottledError) Error() string {
+	if e.RetryAfter <= 0 {
+		return "provider throttled"
+	}
+	return fmt.Sprintf("provider throttled (retry after %s)", e.RetryAfter)
+}
+
+// ParseRetryAfter parses an HTTP Retry-After value (delay seconds or HTTP date).
+// It returns zero for empty, invalid, or past values.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	at, err := http.ParseTime(value)
+	if err != nil || !at.After(now) {
+		return 0
+	}
+	return at.Sub(now)
+}
+
+// RetryAfterHeader formats a delay as whole Retry-After seconds, rounding up.
+func RetryAfterHeader(delay time.Duration) string {
+	seconds := int((delay + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
@@ -3,8 +3,10 @@ package gateway
 import (
 	"context"
 	"errors"
+	"gateway/logging"
 	"gateway/metrics"
-	"log"
+	"gateway/tracing"
+	"log/slog"
 	"sync"
 	"time"
 )
@@ -32,6 +34,8 @@ type PushConfig struct {
 	ProviderCall    PushProviderCall
 	ProviderTimeout time.Duration
 	Metrics         *metrics.Registry
+	// Tracer is optional; nil disables gateway spans.
+	Tracer *tracing.Tracer
 }
 
 // PushProviderCall invokes the configured push provider.
@@ -45,6 +49,7 @@ type PushGateway struct {
 	providerCall    PushProviderCall
 	providerTimeout time.Duration
 	metrics         *metrics.Registry
+	tracer          *tracing.Tracer
 }
 
 // NewPushGateway constructs a PushGateway instance.
@@ -60,9 +65,15 @@ func NewPushGateway(cfg PushConfig) (*PushGateway, error) {
 		providerCall:    cfg.ProviderCall,
 		providerTimeout: cfg.ProviderTimeout,
 		metrics:         cfg.Metrics,
+		tracer:          cfg.Tracer,
 	}, nil
 }
 
+// Tracer returns the gateway's tracer; it may be nil.
+func (g *PushGateway) Tracer() *tracing.Tracer {
+	return g.tracer
+}
+
 // SendPush submits a push request to the configured provider.
 func (g *PushGateway) SendPush(ctx context.Context, req PushRequest) (PushResponse, error) {
 	if req.ReferenceID == "" {
@@ -115,6 +126,9 @@ func (g *PushGateway) SendPush(ctx context.Context, req PushRequest) (PushRespon
 
 	providerCtx, cancel := context.WithTimeout(ctx, g.providerTimeout)
 	defer cancel()
+	providerCtx, providerSpan := g.tracer.Start(providerCtx, "provider.call")
+	providerSpan.SetAttribute("referenceId", req.ReferenceID)
+	providerSpan.SetAttribute("provider", g.metrics.ProviderName())
 
 	providerStart := time.Now()
 	panicRecovered := false
@@ -123,7 +137,7 @@ func (g *PushGateway) SendPush(ctx context.Context, req PushRequest) (PushRespon
 		defer func() {
 			if r := recover(); r != nil {
 				panicRecovered = true
-				log.Printf("push provider panic referenceId=%q panic=%v", req.ReferenceID, r)
+				slog.Error("push provider panic", logging.ReferenceID(req.ReferenceID), "panic", r)
 				err = errors.New("provider panic")
 			}
 		}()
@@ -132,9 +146,19 @@ func (g *PushGateway) SendPush(ctx context.Context, req PushRequest) (PushRespon
 	if g.metrics != nil {
 		g.metrics.ObserveProviderCall(time.Since(providerStart), err, panicRecovered)
 	}
+	providerSpan.End(err)
 	if err != nil {
 		status := "rejected"
 		reason := "provider_failure"
+		// Throttling keeps the provider_failure outcome but returns the error so the handler can signal backpressure.
+		var throttled ProviderThrottledError
+		if errors.As(err, &throttled) {
+			return PushResponse{
+				ReferenceID: req.ReferenceID,
+				Status:      status,
+				Reason:      reason,
+			}, throttled
+		}
 		return PushResponse{
 			ReferenceID: req.ReferenceID,
 			Status:      status,
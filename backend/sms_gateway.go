@@ -5,8 +5,10 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"gateway/logging"
 	"gateway/metrics"
-	"log"
+	"gateway/tracing"
+	"log/slog"
 	"sync"
 	"time"
 )
@@ -42,6 +44,8 @@ type Config struct {
 	ProviderCall    ProviderCall
 	ProviderTimeout time.Duration
 	Metrics         *metrics.Registry
+	// Tracer is optional; nil disables gateway spans.
+	Tracer *tracing.Tracer
 }
 
 // ProviderCall invokes the configured SMS provider.
@@ -61,6 +65,7 @@ type SMSGateway struct {
 	providerCall    ProviderCall
 	providerTimeout time.Duration
 	metrics         *metrics.Registry
+	tracer          *tracing.Tracer
 }
 
 // New constructs an SMSGateway instance.
@@ -76,9 +81,15 @@ func New(cfg Config) (*SMSGateway, error) {
 		providerCall:    cfg.ProviderCall,
 		providerTimeout: cfg.ProviderTimeout,
 		metrics:         cfg.Metrics,
+		tracer:          cfg.Tracer,
 	}, nil
 }
 
+// Tracer returns the gateway's tracer; it may be nil.
+func (g *SMSGateway) Tracer() *tracing.Tracer {
+	return g.tracer
+}
+
 // SendSMS submits an SMS request to the configured provider.
 func (g *SMSGateway) SendSMS(ctx context.Context, req SMSRequest) (SMSResponse, error) {
 	if req.ReferenceID == "" {
@@ -131,6 +142,9 @@ func (g *SMSGateway) SendSMS(ctx context.Context, req SMSRequest) (SMSResponse,
 
 	providerCtx, cancel := context.WithTimeout(ctx, g.providerTimeout)
 	defer cancel()
+	providerCtx, providerSpan := g.tracer.Start(providerCtx, "provider.call")
+	providerSpan.SetAttribute("referenceId", req.ReferenceID)
+	providerSpan.SetAttribute("provider", g.metrics.ProviderName())
 
 	providerStart := time.Now()
 	panicRecovered := false
@@ -139,7 +153,7 @@ func (g *SMSGateway) SendSMS(ctx context.Context, req SMSRequest) (SMSResponse,
 		defer func() {
 			if r := recover(); r != nil {
 				panicRecovered = true
-				log.Printf("sms provider panic referenceId=%q panic=%v", req.ReferenceID, r)
+				slog.Error("sms provider panic", logging.ReferenceID(req.ReferenceID), "panic", r)
 				err = errors.New("provider panic")
 			}
 		}()
@@ -148,9 +162,19 @@ func (g *SMSGateway) SendSMS(ctx context.Context, req SMSRequest) (SMSResponse,
 	if g.metrics != nil {
 		g.metrics.ObserveProviderCall(time.Since(providerStart), err, panicRecovered)
 	}
+	providerSpan.End(err)
 	if err != nil {
 		status := "rejected"
 		reason := "provider_failure"
+		// Throttling keeps the provider_failure outcome but returns the error so the handler can signal backpressure.
+		var throttled ProviderThrottledError
+		if errors.As(err, &throttled) {
+			return SMSResponse{
+				ReferenceID: req.ReferenceID,
+				Status:      status,
+				Reason:      reason,
+			}, throttled
+		}
 		return SMSResponse{
 			ReferenceID: req.ReferenceID,
 			Status:      status,
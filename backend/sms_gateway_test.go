@@ -379,3 +379,45 @@ func TestSendSMSProviderTimeoutApplied(t *testing.T) {
 		t.Fatalf("expected deadline <= 15s, got %v", remaining)
 	}
 }
+
+func TestSendSMSProviderThrottled(t *testing.T) {
+	gw, err := New(Config{
+		ProviderCall: func(ctx context.Context, req SMSRequest) (ProviderResult, error) {
+			return ProviderResult{}, ProviderThrottledError{RetryAfter: 10 * time.Second}
+		},
+		ProviderTimeout: defaultProviderTimeout,
+	})
+	if err != nil {
+		t.Fatalf("new gateway: %v", err)
+	}
+
+	resp, err := gw.SendSMS(context.Background(), SMSRequest{
+		ReferenceID: "ref-4c",
+		To:          "15551234567",
+		Message:     "hello",
+	})
+	var throttled ProviderThrottledError
+	if !errors.As(err, &throttled) || throttled.RetryAfter != 10*time.Second {
+		t.Fatalf("expected throttled error, got %v", err)
+	}
+	if resp.Status != "rejected" || resp.Reason != "provider_failure" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 2, 2, 10, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"abc":                           0,
+		"-5":                            0,
+		"120":                           2 * time.Minute,
+		"Mon, 02 Feb 2026 10:00:30 GMT": 30 * time.Second,
+		"Mon, 02 Feb 2026 09:59:00 GMT": 0,
+	}
+	for value, want := range cases {
+		if got := ParseRetryAfter(value, now); got != want {
+			t.Fatalf("ParseRetryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
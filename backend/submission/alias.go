@@ -0,0 +1,123 @@
+package submission
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// TargetAlias is a registry-level name that resolves to concrete
+// submissionTargets by weight, e.g. to shift traffic to a new gateway
+// deployment in steps.
+type TargetAlias struct {
+	Alias  string
+	Routes []WeightedTarget
+}
+
+// WeightedTarget is one concrete submissionTarget of an alias and its share.
+type WeightedTarget struct {
+	SubmissionTarget string
+	Weight           int
+}
+
+type aliasConfig struct {
+	Alias   string                 `json:"alias"`
+	Targets []weightedTargetConfig `json:"targets"`
+}
+
+type weightedTargetConfig struct {
+	SubmissionTarget string `json:"submissionTarget"`
+	Weight           int    `json:"weight"`
+}
+
+// Resolve picks the concrete submissionTarget for intentID. The choice is a
+// hash of intentID, so replays resolve the same way while weights are
+// unchanged. Routes split the hash space in listed order, so raising the
+// weight of the first route only moves intents toward it.
+func (a TargetAlias) Resolve(intentID string) string {
+	total := 0
+	for _, route := range a.Routes {
+		total += route.Weight
+	}
+	sum := sha256.Sum256([]byte(intentID))
+	// Non-obvious constraint: a fixed point in [0, 1) scaled by the total (rather than hash % total)
+	// puts an intent on the first route exactly when the point is below that route's share, so
+	// raising the share only adds intents to it instead of reshuffling all of them.
+	point := float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53) * float64(total)
+	cumulative := 0
+	for _, route := range a.Routes {
+		cumulative += route.Weight
+		if point < float64(cumulative) {
+			return route.SubmissionTarget
+		}
+	}
+	for i := len(a.Routes) - 1; i >= 0; i-- {
+		if a.Routes[i].Weight > 0 {
+			return a.Routes[i].SubmissionTarget
+		}
+	}
+	return ""
+}
+
+// AliasFor returns the alias with the given name if it exists.
+func (r Registry) AliasFor(name string) (TargetAlias, bool) {
+	if r.Aliases == nil {
+		return TargetAlias{}, false
+	}
+	alias, ok := r.Aliases[strings.TrimSpace(name)]
+	return alias, ok
+}
+
+func buildAliases(cfgs []aliasConfig, targets map[string]TargetContract) (map[string]TargetAlias, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	aliases := make(map[string]TargetAlias, len(cfgs))
+	for i, cfg := range cfgs {
+		name := strings.TrimSpace(cfg.Alias)
+		if name == "" {
+			return nil, fmt.Errorf("aliases[%d].alias is required", i)
+		}
+		if _, exists := targets[name]; exists {
+			return nil, fmt.Errorf("aliases[%d].alias %q is already a submissionTarget", i, name)
+		}
+		if _, exists := aliases[name]; exists {
+			return nil, fmt.Errorf("aliases[%d].alias %q is duplicated", i, name)
+		}
+		if len(cfg.Targets) == 0 {
+			return nil, fmt.Errorf("aliases[%d].targets is required", i)
+		}
+
+		alias := TargetAlias{Alias: name}
+		seen := make(map[string]struct{}, len(cfg.Targets))
+		var gatewayType GatewayType
+		total := 0
+		for j, route := range cfg.Targets {
+			target := strings.TrimSpace(route.SubmissionTarget)
+			contract, ok := targets[target]
+			if !ok {
+				return nil, fmt.Errorf("aliases[%d].targets[%d].submissionTarget %q is not a known submissionTarget", i, j, target)
+			}
+			if _, ok := seen[target]; ok {
+				return nil, fmt.Errorf("aliases[%d].targets[%d].submissionTarget %q is duplicated", i, j, target)
+			}
+			seen[target] = struct{}{}
+			// The caller's payload must be valid for every route.
+			if j > 0 && contract.GatewayType != gatewayType {
+				return nil, fmt.Errorf("aliases[%d].targets[%d].submissionTarget %q has gatewayType %q; all targets of an alias must share one", i, j, target, contract.GatewayType)
+			}
+			gatewayType = contract.GatewayType
+			if route.Weight < 0 {
+				return nil, fmt.Errorf("aliases[%d].targets[%d].weight must be zero or greater", i, j)
+			}
+			total += route.Weight
+			alias.Routes = append(alias.Routes, WeightedTarget{SubmissionTarget: target, Weight: route.Weight})
+		}
+		if total == 0 {
+			return nil, fmt.Errorf("aliases[%d].targets must have a weight greater than zero", i)
+		}
+		aliases[name] = alias
+	}
+	return aliases, nil
+}
@@ -0,0 +1,111 @@
+package submission
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTargetAliasResolve(t *testing.T) {
+	alias := TargetAlias{Alias: "otp-sms", Routes: []WeightedTarget{
+		{SubmissionTarget: "sms.v2", Weight: 5},
+		{SubmissionTarget: "sms.v1", Weight: 95},
+	}}
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		intentID := fmt.Sprintf("intent-%d", i)
+		target := alias.Resolve(intentID)
+		if again := alias.Resolve(intentID); again != target {
+			t.Fatalf("Resolve(%s) is not deterministic: %s then %s", intentID, target, again)
+		}
+		counts[target]++
+	}
+	if counts["sms.v2"] < 350 || counts["sms.v2"] > 650 {
+		t.Fatalf("expected about 5%% on sms.v2, got %v", counts)
+	}
+
+	// Raising the first route's weight only moves intents toward it.
+	ramped := TargetAlias{Alias: "otp-sms", Routes: []WeightedTarget{
+		{SubmissionTarget: "sms.v2", Weight: 50},
+		{SubmissionTarget: "sms.v1", Weight: 50},
+	}}
+	for i := 0; i < 10000; i++ {
+		intentID := fmt.Sprintf("intent-%d", i)
+		if alias.Resolve(intentID) == "sms.v2" && ramped.Resolve(intentID) != "sms.v2" {
+			t.Fatalf("intent %s moved away from the ramped route", intentID)
+		}
+	}
+
+	full := TargetAlias{Alias: "otp-sms", Routes: []WeightedTarget{
+		{SubmissionTarget: "sms.v2", Weight: 100},
+		{SubmissionTarget: "sms.v1", Weight: 0},
+	}}
+	for i := 0; i < 1000; i++ {
+		if target := full.Resolve(fmt.Sprintf("intent-%d", i)); target != "sms.v2" {
+			t.Fatalf("expected all intents on sms.v2, got %s", target)
+		}
+	}
+}
+
+const aliasTargetsConfig = `
+    {"submissionTarget": "sms.v1", "gatewayType": "sms", "gatewayUrl": "http://localhost:8080", "policy": "one_shot", "terminalOutcomes": ["invalid_request"]},
+    {"submissionTarget": "sms.v2", "gatewayType": "sms", "gatewayUrl": "http://localhost:8090", "policy": "one_shot", "terminalOutcomes": ["invalid_request"]},
+    {"submissionTarget": "push.v1", "gatewayType": "push", "gatewayUrl": "http://localhost:8081", "policy": "one_shot", "terminalOutcomes": ["invalid_request"]}`
+
+func TestLoadRegistryAliases(t *testing.T) {
+	config := `{
+  "targets": [` + aliasTargetsConfig + `
+  ],
+  "aliases": [
+    {"alias": " otp-sms ", "targets": [{"submissionTarget": "sms.v2", "weight": 5}, {"submissionTarget": "sms.v1", "weight": 95}]}
+  ]
+}
+`
+	registry, err := LoadRegistry(writeTempConfig(t, config))
+	if err != nil {
+		t.Fatalf("load registry: %v", err)
+	}
+	alias, ok := registry.AliasFor("otp-sms")
+	if !ok {
+		t.Fatal("expected otp-sms alias")
+	}
+	want := []WeightedTarget{{SubmissionTarget: "sms.v2", Weight: 5}, {SubmissionTarget: "sms.v1", Weight: 95}}
+	if len(alias.Routes) != len(want) || alias.Routes[0] != want[0] || alias.Routes[1] != want[1] {
+		t.Fatalf("unexpected routes %+v", alias.Routes)
+	}
+	if _, ok := registry.ContractFor("otp-sms"); ok {
+		t.Fatal("alias must not be a contract")
+	}
+}
+
+func TestLoadRegistryRejectsInvalidAliases(t *testing.T) {
+	cases := []struct {
+		name        string
+		aliases     string
+		wantContain string
+	}{
+		{name: "missing name", aliases: `{"targets": [{"submissionTarget": "sms.v1", "weight": 1}]}`, wantContain: "alias is required"},
+		{name: "shadows target", aliases: `{"alias": "sms.v1", "targets": [{"submissionTarget": "sms.v2", "weight": 1}]}`, wantContain: "already a submissionTarget"},
+		{name: "duplicate alias", aliases: `{"alias": "otp", "targets": [{"submissionTarget": "sms.v1", "weight": 1}]}, {"alias": "otp", "targets": [{"submissionTarget": "sms.v2", "weight": 1}]}`, wantContain: "is duplicated"},
+		{name: "no targets", aliases: `{"alias": "otp", "targets": []}`, wantContain: "targets is required"},
+		{name: "unknown target", aliases: `{"alias": "otp", "targets": [{"submissionTarget": "sms.v3", "weight": 1}]}`, wantContain: "not a known submissionTarget"},
+		{name: "duplicate target", aliases: `{"alias": "otp", "targets": [{"submissionTarget": "sms.v1", "weight": 1}, {"submissionTarget": "sms.v1", "weight": 1}]}`, wantContain: "is duplicated"},
+		{name: "mixed gateway types", aliases: `{"alias": "otp", "targets": [{"submissionTarget": "sms.v1", "weight": 1}, {"submissionTarget": "push.v1", "weight": 1}]}`, wantContain: "must share one"},
+		{name: "negative weight", aliases: `{"alias": "otp", "targets": [{"submissionTarget": "sms.v1", "weight": -1}]}`, wantContain: "weight must be zero or greater"},
+		{name: "zero total", aliases: `{"alias": "otp", "targets": [{"submissionTarget": "sms.v1", "weight": 0}]}`, wantContain: "weight greater than zero"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := `{
+  "targets": [` + aliasTargetsConfig + `
+  ],
+  "aliases": [` + tc.aliases + `]
+}
+`
+			_, err := LoadRegistry(writeTempConfig(t, config))
+			if err == nil || !strings.Contains(err.Error(), tc.wantContain) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantContain, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package submission
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	// Non-obvious constraint: windows name IANA zones, and minimal containers ship no zoneinfo.
+	_ "time/tzdata"
+)
+
+// ZoneFromRecipientCountry derives the window's time zone from the country
+// calling code of the payload's "to" number.
+const ZoneFromRecipientCountry = "recipient_country"
+
+// DeliveryWindow restricts when attempts of a submissionTarget may run, e.g.
+// to keep promotional SMS out of the night. It is frozen into the contract
+// snapshot, hence the JSON tags.
+type DeliveryWindow struct {
+	// TimeZone is the IANA zone the spans are evaluated in. With
+	// ZoneFromRecipient it is the fallback for recipients whose zone is unknown.
+	TimeZone          string `json:"timeZone"`
+	ZoneFromRecipient bool   `json:"zoneFromRecipient,omitempty"`
+	// CountryTimeZones maps calling codes to zones, overriding the built-in
+	// table. Countries spanning several zones are only resolved through it.
+	CountryTimeZones map[string]string `json:"countryTimeZones,omitempty"`
+	Spans            []WindowSpan      `json:"spans"`
+	// ExcludeDeferralFromDeadline extends the acceptance deadline by the time
+	// attempts spend deferred. Otherwise deferral counts against it.
+	ExcludeDeferralFromDeadline bool `json:"excludeDeferralFromDeadline,omitempty"`
+}
+
+// WindowSpan is one allowed local time range, [StartMinute, EndMinute) minutes
+// after midnight, on the listed days. No days means every day.
+type WindowSpan struct {
+	Days        []time.Weekday `json:"days,omitempty"`
+	StartMinute int            `json:"startMinute"`
+	EndMinute   int            `json:"endMinute"`
+}
+
+type deliveryWindowConfig struct {
+	TimeZone                    string             `json:"timeZone"`
+	TimeZoneFrom                string             `json:"timeZoneFrom"`
+	CountryTimeZones            map[string]string  `json:"countryTimeZones"`
+	Spans                       []windowSpanConfig `json:"spans"`
+	ExcludeDeferralFromDeadline bool               `json:"excludeDeferralFromDeadline"`
+}
+
+type windowSpanConfig struct {
+	Days  []string `json:"days"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// callingCodeZones maps country calling codes to the zone of countries that
+// (almost) entirely use one. Calling codes are prefix-free, so the longest
+// matching prefix is the country.
+var callingCodeZones = map[string]string{
+	"20":  "Africa/Cairo",
+	"27":  "Africa/Johannesburg",
+	"30":  "Europe/Athens",
+	"31":  "Europe/Amsterdam",
+	"32":  "Europe/Brussels",
+	"33":  "Europe/Paris",
+	"34":  "Europe/Madrid",
+	"36":  "Europe/Budapest",
+	"39":  "Europe/Rome",
+	"40":  "Europe/Bucharest",
+	"41":  "Europe/Zurich",
+	"43":  "Europe/Vienna",
+	"44":  "Europe/London",
+	"45":  "Europe/Copenhagen",
+	"46":  "Europe/Stockholm",
+	"47":  "Europe/Oslo",
+	"48":  "Europe/Warsaw",
+	"49":  "Europe/Berlin",
+	"60":  "Asia/Kuala_Lumpur",
+	"63":  "Asia/Manila",
+	"64":  "Pacific/Auckland",
+	"65":  "Asia/Singapore",
+	"66":  "Asia/Bangkok",
+	"81":  "Asia/Tokyo",
+	"82":  "Asia/Seoul",
+	"84":  "Asia/Ho_Chi_Minh",
+	"86":  "Asia/Shanghai",
+	"90":  "Europe/Istanbul",
+	"91":  "Asia/Kolkata",
+	"92":  "Asia/Karachi",
+	"94":  "Asia/Colombo",
+	"234": "Africa/Lagos",
+	"254": "Africa/Nairobi",
+	"351": "Europe/Lisbon",
+	"353": "Europe/Dublin",
+	"358": "Europe/Helsinki",
+	"852": "Asia/Hong_Kong",
+	"880": "Asia/Dhaka",
+	"966": "Asia/Riyadh",
+	"971": "Asia/Dubai",
+	"972": "Asia/Jerusalem",
+	"977": "Asia/Kathmandu",
+}
+
+// Location returns the zone the window applies to for payload. Unknown
+// recipients, and zones that fail to load, fall back to TimeZone and then UTC.
+func (w *DeliveryWindow) Location(payload []byte) *time.Location {
+	name := w.TimeZone
+	if w.ZoneFromRecipient {
+		if zone, ok := w.recipientZone(payload); ok {
+			name = zone
+		}
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (w *DeliveryWindow) recipientZone(payload []byte) (string, bool) {
+	var recipient struct {
+		To string `json:"to"`
+	}
+	if err := json.Unmarshal(payload, &recipient); err != nil {
+		return "", false
+	}
+	digits := strings.TrimPrefix(strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, recipient.To), "00")
+	for length := 3; length >= 1; length-- {
+		if len(digits) <= length {
+			continue
+		}
+		code := digits[:length]
+		if zone, ok := w.CountryTimeZones[code]; ok {
+			return zone, true
+		}
+		if zone, ok := callingCodeZones[code]; ok {
+			return zone, true
+		}
+	}
+	return "", false
+}
+
+// NextOpening returns t when t is inside a span, otherwise the next span start
+// after t. Spans are evaluated in loc, so openings follow daylight saving.
+// When nothing opens within the eight days scanned, it returns the end of the
+// scan, so the attempt stays deferred instead of running outside the window.
+func (w *DeliveryWindow) NextOpening(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	var next time.Time
+	for offset := 0; offset <= 7; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		for _, span := range w.Spans {
+			if !span.allows(day.Weekday()) {
+				continue
+			}
+			open := time.Date(day.Year(), day.Month(), day.Day(), 0, span.StartMinute, 0, 0, loc)
+			closing := time.Date(day.Year(), day.Month(), day.Day(), 0, span.EndMinute, 0, 0, loc)
+			if !local.Before(open) && local.Before(closing) {
+				return t
+			}
+			if open.After(local) && (next.IsZero() || open.Before(next)) {
+				next = open
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+	return time.Date(local.Year(), local.Month(), local.Day()+8, 0, 0, 0, 0, loc)
+}
+
+func (s WindowSpan) allows(day time.Weekday) bool {
+	if len(s.Days) == 0 {
+		return true
+	}
+	for _, allowed := range s.Days {
+		if allowed == day {
+			return true
+		}
+	}
+	return false
+}
+
+func validateDeliveryWindow(cfg *deliveryWindowConfig, gatewayType GatewayType, idx int) (*DeliveryWindow, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	window := &DeliveryWindow{ExcludeDeferralFromDeadline: cfg.ExcludeDeferralFromDeadline}
+
+	window.TimeZone = strings.TrimSpace(cfg.TimeZone)
+	if window.TimeZone == "" {
+		return nil, fmt.Errorf("targets[%d].deliveryWindow.timeZone is required", idx)
+	}
+	if _, err := time.LoadLocation(window.TimeZone); err != nil {
+		return nil, fmt.Errorf("targets[%d].deliveryWindow.timeZone %q is not a known zone", idx, window.TimeZone)
+	}
+	switch from := strings.TrimSpace(cfg.TimeZoneFrom); from {
+	case "":
+	case ZoneFromRecipientCountry:
+		if gatewayType != GatewaySMS {
+			return nil, fmt.Errorf("targets[%d].deliveryWindow.timeZoneFrom %s requires gatewayType sms", idx, ZoneFromRecipientCountry)
+		}
+		window.ZoneFromRecipient = true
+	default:
+		return nil, fmt.Errorf("targets[%d].deliveryWindow.timeZoneFrom must be empty or %s", idx, ZoneFromRecipientCountry)
+	}
+	if len(cfg.CountryTimeZones) > 0 {
+		if !window.ZoneFromRecipient {
+			return nil, fmt.Errorf("targets[%d].deliveryWindow.countryTimeZones requires timeZoneFrom %s", idx, ZoneFromRecipientCountry)
+		}
+		window.CountryTimeZones = make(map[string]string, len(cfg.CountryTimeZones))
+		for code, zone := range cfg.CountryTimeZones {
+			code = strings.TrimPrefix(strings.TrimSpace(code), "+")
+			if len(code) < 1 || len(code) > 3 || strings.Trim(code, "0123456789") != "" {
+				return nil, fmt.Errorf("targets[%d].deliveryWindow.countryTimeZones key %q must be a 1-3 digit calling code", idx, code)
+			}
+			zone = strings.TrimSpace(zone)
+			if _, err := time.LoadLocation(zone); err != nil {
+				return nil, fmt.Errorf("targets[%d].deliveryWindow.countryTimeZones.%s %q is not a known zone", idx, code, zone)
+			}
+			window.CountryTimeZones[code] = zone
+		}
+	}
+
+	if len(cfg.Spans) == 0 {
+		return nil, fmt.Errorf("targets[%d].deliveryWindow.spans is required", idx)
+	}
+	for spanIdx, rawSpan := range cfg.Spans {
+		field := fmt.Sprintf("targets[%d].deliveryWindow.spans[%d]", idx, spanIdx)
+		span := WindowSpan{}
+		for _, name := range rawSpan.Days {
+			day, ok := weekdayNames[strings.ToLower(strings.TrimSpace(name))]
+			if !ok {
+				return nil, fmt.Errorf("%s.days contains unknown day %q", field, name)
+			}
+			span.Days = append(span.Days, day)
+		}
+		start, err := parseClockMinute(rawSpan.Start)
+		if err != nil {
+			return nil, fmt.Errorf("%s.start %v", field, err)
+		}
+		end, err := parseClockMinute(rawSpan.End)
+		if err != nil {
+			return nil, fmt.Errorf("%s.end %v", field, err)
+		}
+		if start >= end {
+			return nil, fmt.Errorf("%s.start must be before end; split windows that cross midnight", field)
+		}
+		span.StartMinute = start
+		span.EndMinute = end
+		window.Spans = append(window.Spans, span)
+	}
+	return window, nil
+}
+
+// parseClockMinute parses "HH:MM" (00:00 to 24:00) into minutes after midnight.
+func parseClockMinute(raw string) (int, error) {
+	var hour, minute int
+	trimmed := strings.TrimSpace(raw)
+	if len(trimmed) != 5 {
+		return 0, errors.New("must be HH:MM")
+	}
+	if _, err := fmt.Sscanf(trimmed, "%02d:%02d", &hour, &minute); err != nil {
+		return 0, errors.New("must be HH:MM")
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, errors.New("must be between 00:00 and 24:00")
+	}
+	return hour*60 + minute, nil
+}
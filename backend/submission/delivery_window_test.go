@@ -0,0 +1,168 @@
+package submission
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeliveryWindowNextOpening(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("load zone: %v", err)
+	}
+	window := &DeliveryWindow{
+		TimeZone: "Europe/Berlin",
+		Spans: []WindowSpan{
+			{Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, StartMinute: 8 * 60, EndMinute: 20 * 60},
+			{Days: []time.Weekday{time.Saturday}, StartMinute: 10 * 60, EndMinute: 14 * 60},
+		},
+	}
+	cases := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		// 2026-03-25 is a Wednesday.
+		{name: "inside span", at: time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC), want: time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)},
+		{name: "before opening", at: time.Date(2026, 3, 25, 5, 0, 0, 0, time.UTC), want: time.Date(2026, 3, 25, 7, 0, 0, 0, time.UTC)},
+		{name: "end is exclusive", at: time.Date(2026, 3, 25, 19, 0, 0, 0, time.UTC), want: time.Date(2026, 3, 26, 7, 0, 0, 0, time.UTC)},
+		{name: "weekday filter", at: time.Date(2026, 3, 27, 20, 0, 0, 0, time.UTC), want: time.Date(2026, 3, 28, 9, 0, 0, 0, time.UTC)},
+		// Sunday 2026-03-29 switches Berlin to CEST; Monday opens at 06:00 UTC instead of 07:00.
+		{name: "across daylight saving", at: time.Date(2026, 3, 28, 13, 0, 0, 0, time.UTC), want: time.Date(2026, 3, 30, 6, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := window.NextOpening(tc.at, berlin); !got.Equal(tc.want) {
+				t.Fatalf("NextOpening(%s) = %s, want %s", tc.at, got.UTC(), tc.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryWindowWithoutOpeningDefersToScanEnd(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("load zone: %v", err)
+	}
+	// A snapshot whose spans never open must not send: the attempt waits for the end of the scan.
+	window := &DeliveryWindow{TimeZone: "Europe/Berlin"}
+	at := time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 4, 1, 22, 0, 0, 0, time.UTC)
+	if got := window.NextOpening(at, berlin); !got.Equal(want) {
+		t.Fatalf("NextOpening(%s) = %s, want %s", at, got.UTC(), want)
+	}
+}
+
+func TestDeliveryWindowLocation(t *testing.T) {
+	window := &DeliveryWindow{
+		TimeZone:          "Europe/Berlin",
+		ZoneFromRecipient: true,
+		CountryTimeZones:  map[string]string{"1": "America/New_York"},
+	}
+	cases := []struct {
+		payload string
+		want    string
+	}{
+		{payload: `{"to":"+44 7700 900123"}`, want: "Europe/London"},
+		{payload: `{"to":"00353851234567"}`, want: "Europe/Dublin"},
+		{payload: `{"to":"+12025550123"}`, want: "America/New_York"},
+		{payload: `{"to":"+7 912 345 67 89"}`, want: "Europe/Berlin"},
+		{payload: `not json`, want: "Europe/Berlin"},
+	}
+	for _, tc := range cases {
+		if got := window.Location([]byte(tc.payload)).String(); got != tc.want {
+			t.Fatalf("Location(%s) = %s, want %s", tc.payload, got, tc.want)
+		}
+	}
+}
+
+func TestLoadRegistryDeliveryWindow(t *testing.T) {
+	config := `{
+  "targets": [
+    {
+      "submissionTarget": "sms.promo",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "deadline",
+      "maxAcceptanceSeconds": 86400,
+      "terminalOutcomes": ["invalid_request"],
+      "deliveryWindow": {
+        "timeZone": "Europe/Berlin",
+        "timeZoneFrom": "recipient_country",
+        "countryTimeZones": {"+1": "America/Chicago"},
+        "excludeDeferralFromDeadline": true,
+        "spans": [{"days": ["mon", "Fri"], "start": "08:00", "end": "21:30"}]
+      }
+    }
+  ]
+}
+`
+	registry, err := LoadRegistry(writeTempConfig(t, config))
+	if err != nil {
+		t.Fatalf("load registry: %v", err)
+	}
+	contract, _ := registry.ContractFor("sms.promo")
+	window := contract.DeliveryWindow
+	if window == nil {
+		t.Fatal("expected delivery window")
+	}
+	if !window.ZoneFromRecipient || !window.ExcludeDeferralFromDeadline || window.CountryTimeZones["1"] != "America/Chicago" {
+		t.Fatalf("unexpected window %+v", window)
+	}
+	if len(window.Spans) != 1 || window.Spans[0].StartMinute != 480 || window.Spans[0].EndMinute != 1290 {
+		t.Fatalf("unexpected spans %+v", window.Spans)
+	}
+	if days := window.Spans[0].Days; len(days) != 2 || days[0] != time.Monday || days[1] != time.Friday {
+		t.Fatalf("unexpected days %v", days)
+	}
+}
+
+func TestLoadRegistryRejectsInvalidDeliveryWindow(t *testing.T) {
+	cases := []struct {
+		name        string
+		gatewayType string
+		policy      string
+		window      string
+		wantContain string
+	}{
+		{name: "missing zone", window: `{"spans": [{"start": "08:00", "end": "20:00"}]}`, wantContain: "timeZone is required"},
+		{name: "unknown zone", window: `{"timeZone": "Mars/Olympus", "spans": [{"start": "08:00", "end": "20:00"}]}`, wantContain: "not a known zone"},
+		{name: "no spans", window: `{"timeZone": "UTC"}`, wantContain: "spans is required"},
+		{name: "crosses midnight", window: `{"timeZone": "UTC", "spans": [{"start": "22:00", "end": "06:00"}]}`, wantContain: "start must be before end"},
+		{name: "bad clock", window: `{"timeZone": "UTC", "spans": [{"start": "8:00", "end": "20:00"}]}`, wantContain: "must be HH:MM"},
+		{name: "unknown day", window: `{"timeZone": "UTC", "spans": [{"days": ["someday"], "start": "08:00", "end": "20:00"}]}`, wantContain: "unknown day"},
+		{name: "recipient zone on push", gatewayType: "push", window: `{"timeZone": "UTC", "timeZoneFrom": "recipient_country", "spans": [{"start": "08:00", "end": "20:00"}]}`, wantContain: "requires gatewayType sms"},
+		{name: "country zones without recipient zone", window: `{"timeZone": "UTC", "countryTimeZones": {"1": "America/Chicago"}, "spans": [{"start": "08:00", "end": "20:00"}]}`, wantContain: "requires timeZoneFrom"},
+		{name: "exclude deferral without deadline", policy: `"policy": "max_attempts", "maxAttempts": 3`, window: `{"timeZone": "UTC", "excludeDeferralFromDeadline": true, "spans": [{"start": "08:00", "end": "20:00"}]}`, wantContain: "requires a policy with a deadline"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatewayType := tc.gatewayType
+			if gatewayType == "" {
+				gatewayType = "sms"
+			}
+			policy := tc.policy
+			if policy == "" {
+				policy = `"policy": "deadline", "maxAcceptanceSeconds": 30`
+			}
+			config := `{
+  "targets": [
+    {
+      "submissionTarget": "target",
+      "gatewayType": "` + gatewayType + `",
+      "gatewayUrl": "http://localhost:8080",
+      ` + policy + `,
+      "terminalOutcomes": ["invalid_request"],
+      "deliveryWindow": ` + tc.window + `
+    }
+  ]
+}
+`
+			_, err := LoadRegistry(writeTempConfig(t, config))
+			if err == nil || !strings.Contains(err.Error(), tc.wantContain) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantContain, err)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"path"
 	"strings"
 )
 
@@ -32,62 +33,291 @@ const (
 	PolicyMaxAttempts ContractPolicy = "max_attempts"
 	// PolicyOneShot allows only a single attempt.
 	PolicyOneShot ContractPolicy = "one_shot"
+	// PolicyDeadlineOrMaxAttempts exhausts at the acceptance deadline or the
+	// attempt limit, whichever is reached first.
+	PolicyDeadlineOrMaxAttempts ContractPolicy = "deadline_or_max_attempts"
 )
 
+// HasDeadline reports whether the policy bounds retries by maxAcceptanceSeconds.
+func (p ContractPolicy) HasDeadline() bool {
+	return p == PolicyDeadline || p == PolicyDeadlineOrMaxAttempts
+}
+
+// UnresolvedSend selects what happens after an attempt timed out, when the
+// gateway may still have accepted the send.
+type UnresolvedSend string
+
+const (
+	// UnresolvedSendReview exhausts the intent with reason attempt_unresolved,
+	// so nothing is sent again until an operator has checked the provider.
+	UnresolvedSendReview UnresolvedSend = "review"
+	// UnresolvedSendRetry resends with the same referenceId under the policy.
+	// Gateway dedup only catches the duplicate while the first send is in flight.
+	UnresolvedSendRetry UnresolvedSend = "retry"
+)
+
+// RuleAction is what a retry rule does with an attempt that matches it.
+type RuleAction string
+
+const (
+	// RuleRetry retries under the policy, optionally after a rule-specific delay.
+	RuleRetry RuleAction = "retry"
+	// RuleAccept treats the matching gateway rejection as an acceptance.
+	RuleAccept RuleAction = "accept"
+	// RuleTerminal ends the intent: rejected for a gateway outcome, exhausted
+	// for an attempt error class.
+	RuleTerminal RuleAction = "terminal"
+)
+
+// EndpointStrategy selects how the executor orders a target's gateway endpoints.
+type EndpointStrategy string
+
+const (
+	// EndpointFailover tries endpoints in listed order.
+	EndpointFailover EndpointStrategy = "failover"
+	// EndpointRoundRobin starts each attempt at the next endpoint in turn.
+	EndpointRoundRobin EndpointStrategy = "round_robin"
+)
+
+// maxRuleRetryAfterSeconds matches the cap on gateway Retry-After delays.
+const maxRuleRetryAfterSeconds = 3600
+
+// RetryRule decides how a rejection reason or attempt error class is handled.
+// It is frozen into the contract snapshot, hence the JSON tags.
+type RetryRule struct {
+	Action RuleAction `json:"action"`
+	// RetryAfterSeconds replaces the default retry delay for RuleRetry. Zero
+	// keeps the default.
+	RetryAfterSeconds int `json:"retryAfterSeconds,omitempty"`
+}
+
+// AttemptErrorClass classifies why an attempt ended without a usable gateway outcome.
+type AttemptErrorClass string
+
+const (
+	// ErrorClassTransport covers connection, DNS, and other request failures.
+	ErrorClassTransport AttemptErrorClass = "transport"
+	// ErrorClassTimeout means the attempt exceeded attemptTimeoutSeconds.
+	ErrorClassTimeout AttemptErrorClass = "timeout"
+	// ErrorClassGateway5xx means the gateway (or proxy) returned a 5xx status.
+	ErrorClassGateway5xx AttemptErrorClass = "gateway_5xx"
+	// ErrorClassGateway4xx means the gateway (or proxy) returned a non-2xx status below 500.
+	ErrorClassGateway4xx AttemptErrorClass = "gateway_4xx"
+	// ErrorClassDecode means the gateway response body could not be decoded.
+	ErrorClassDecode AttemptErrorClass = "decode"
+	// ErrorClassContractViolation means the decoded response broke the gateway contract.
+	ErrorClassContractViolation AttemptErrorClass = "contract_violation"
+	// ErrorClassLeaseLost means leadership ended while the attempt was running.
+	ErrorClassLeaseLost AttemptErrorClass = "lease_lost"
+)
+
+var knownErrorClasses = map[AttemptErrorClass]struct{}{
+	ErrorClassTransport:         {},
+	ErrorClassTimeout:           {},
+	ErrorClassGateway5xx:        {},
+	ErrorClassGateway4xx:        {},
+	ErrorClassDecode:            {},
+	ErrorClassContractViolation: {},
+	ErrorClassLeaseLost:         {},
+}
+
+// DefaultMaxExemptAttempts caps budget-exempt attempts when a target does not
+// set maxExemptAttempts.
+const DefaultMaxExemptAttempts = 10
+
 // TargetContract is the resolved contract snapshot for a submissionTarget.
 type TargetContract struct {
 	SubmissionTarget string
 	GatewayType      GatewayType
-	GatewayURL       string
+	// GatewayURL is the only endpoint, or the first of GatewayURLs.
+	GatewayURL string
+	// GatewayURLs lists every endpoint when the target has several; the
+	// executor moves to the next one when an endpoint cannot be connected
+	// to. Nil for a single gatewayUrl.
+	GatewayURLs      []string
+	EndpointStrategy EndpointStrategy
 	Policy           ContractPolicy
 	// MaxAcceptanceSeconds is the cumulative wall-clock deadline from intent
 	// creation within which the submission must be accepted. It is not a
-	// per-attempt timeout. It is required when policy is "deadline".
+	// per-attempt timeout. It is required when policy is "deadline" or
+	// "deadline_or_max_attempts".
 	MaxAcceptanceSeconds int
-	// MaxAttempts is required when policy is "max_attempts".
+	// MaxAttempts is required when policy is "max_attempts" or
+	// "deadline_or_max_attempts".
 	MaxAttempts int
+	// AttemptTimeoutSeconds bounds a single gateway attempt. Zero means the
+	// attempt is bounded only by the gateway connection.
+	AttemptTimeoutSeconds int
+	// UnresolvedSend decides whether a timed-out send is retried (the default)
+	// or left for review. Empty, as in snapshots written before it existed,
+	// means retry.
+	UnresolvedSend UnresolvedSend
+	// BudgetExemptErrorClasses lists attempt error classes that do not count
+	// against the attempt budget of max_attempts and one_shot policies.
+	BudgetExemptErrorClasses []AttemptErrorClass
+	// BudgetExemptBackpressure stops attempts answered with gateway
+	// backpressure (429 or 503) from counting against the attempt budget.
+	BudgetExemptBackpressure bool
+	// MaxExemptAttempts caps the budget-exempt attempts of max_attempts and
+	// one_shot policies, which have no deadline to bound them. Zero means
+	// DefaultMaxExemptAttempts.
+	MaxExemptAttempts int
 	// TerminalOutcomes lists gateway-reported outcomes that, under this
 	// submission contract, must immediately complete the intent without
 	// further attempts.
 	TerminalOutcomes []string
-	Webhook          *WebhookConfig
+	// OutcomeRules overrides the handling of gateway rejection reasons.
+	// Reasons without a rule are terminal if listed in TerminalOutcomes and
+	// retried with the default delay otherwise.
+	OutcomeRules map[string]RetryRule
+	// ErrorClassRules overrides the handling of attempt error classes, which
+	// are otherwise retried with the default delay.
+	ErrorClassRules map[AttemptErrorClass]RetryRule
+	// DeliveryWindow restricts attempts to allowed local times. Nil means
+	// attempts may run at any time.
+	DeliveryWindow *DeliveryWindow
+	// MaxPendingIntents caps the pending intents of this target; SubmitIntent
+	// refuses new intents above it. Zero means no per-target cap.
+	MaxPendingIntents int
+	// AdmissionExempt excludes this target from the global pending-intent
+	// cap, for high-priority traffic. MaxPendingIntents still applies.
+	AdmissionExempt bool
+	Webhook         *WebhookConfig
 }
 
 // Registry maps submissionTarget identifiers to validated TargetContracts.
 type Registry struct {
 	Targets map[string]TargetContract
+	// Aliases maps alias names to weighted concrete submissionTargets.
+	Aliases map[string]TargetAlias
 }
 
 type fileConfig struct {
 	// Non-obvious constraint: unsigned webhooks are rejected unless this is true.
 	AllowUnsignedWebhooks bool           `json:"allowUnsignedWebhooks"`
 	Targets               []targetConfig `json:"targets"`
+	Aliases               []aliasConfig  `json:"aliases"`
 }
 
 type targetConfig struct {
-	SubmissionTarget     string         `json:"submissionTarget"`
-	GatewayType          string         `json:"gatewayType"`
-	GatewayURL           string         `json:"gatewayUrl"`
-	Policy               string         `json:"policy"`
-	MaxAcceptanceSeconds int            `json:"maxAcceptanceSeconds"`
-	MaxAttempts          int            `json:"maxAttempts"`
-	TerminalOutcomes     []string       `json:"terminalOutcomes"`
-	Webhook              *webhookConfig `json:"webhook"`
+	SubmissionTarget         string                     `json:"submissionTarget"`
+	GatewayType              string                     `json:"gatewayType"`
+	GatewayURL               string                     `json:"gatewayUrl"`
+	GatewayURLs              []string                   `json:"gatewayUrls"`
+	EndpointStrategy         string                     `json:"endpointStrategy"`
+	Policy                   string                     `json:"policy"`
+	MaxAcceptanceSeconds     int                        `json:"maxAcceptanceSeconds"`
+	MaxAttempts              int                        `json:"maxAttempts"`
+	AttemptTimeoutSeconds    int                        `json:"attemptTimeoutSeconds"`
+	UnresolvedSend           string                     `json:"unresolvedSend"`
+	BudgetExemptErrorClasses []string                   `json:"budgetExemptErrorClasses"`
+	BudgetExemptBackpressure bool                       `json:"budgetExemptBackpressure"`
+	MaxExemptAttempts        int                        `json:"maxExemptAttempts"`
+	TerminalOutcomes         []string                   `json:"terminalOutcomes"`
+	OutcomeRules             map[string]retryRuleConfig `json:"outcomeRules"`
+	ErrorClassRules          map[string]retryRuleConfig `json:"errorClassRules"`
+	DeliveryWindow           *deliveryWindowConfig      `json:"deliveryWindow"`
+	MaxPendingIntents        int                        `json:"maxPendingIntents"`
+	AdmissionExempt          bool                       `json:"admissionExempt"`
+	Webhook                  *webhookConfig             `json:"webhook"`
+}
+
+type retryRuleConfig struct {
+	Action            string `json:"action"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds"`
 }
 
+// WebhookFormat selects how the terminal webhook event is encoded on the wire.
+type WebhookFormat string
+
+const (
+	// WebhookFormatSetu is the Setu JSON envelope (the default).
+	WebhookFormatSetu WebhookFormat = "setu"
+	// WebhookFormatCloudEventsStructured is CloudEvents 1.0 structured JSON mode.
+	WebhookFormatCloudEventsStructured WebhookFormat = "cloudevents_structured"
+	// WebhookFormatCloudEventsBinary is CloudEvents 1.0 binary HTTP mode.
+	WebhookFormatCloudEventsBinary WebhookFormat = "cloudevents_binary"
+)
+
 // WebhookConfig defines the terminal webhook callback for a submissionTarget.
 type WebhookConfig struct {
 	URL        string
+	Format     WebhookFormat
 	Headers    map[string]string
 	HeadersEnv map[string]string
 	SecretEnv  string
+	// AllowedCallbackURLs lists URL prefixes an intent may use as its own callbackUrl.
+	// Empty means intents cannot override URL.
+	AllowedCallbackURLs []string
+	// AllowedCallbackSecretEnvs lists secret env names an intent may reference for its callback.
+	AllowedCallbackSecretEnvs []string
 }
 
 type webhookConfig struct {
-	URL        string            `json:"url"`
-	Headers    map[string]string `json:"headers"`
-	HeadersEnv map[string]string `json:"headersEnv"`
-	SecretEnv  string            `json:"secretEnv"`
+	URL                       string            `json:"url"`
+	Format                    string            `json:"format"`
+	Headers                   map[string]string `json:"headers"`
+	HeadersEnv                map[string]string `json:"headersEnv"`
+	SecretEnv                 string            `json:"secretEnv"`
+	AllowedCallbackURLs       []string          `json:"allowedCallbackUrls"`
+	AllowedCallbackSecretEnvs []string          `json:"allowedCallbackSecretEnvs"`
+}
+
+// AllowsCallbackURL reports whether raw matches one of the allowed callback URL prefixes.
+// Scheme and host must match exactly; the path must start with the allowed path.
+func (w *WebhookConfig) AllowsCallbackURL(raw string) bool {
+	if w == nil || validateWebhookURL(raw) != nil {
+		return false
+	}
+	candidate, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	// Non-obvious constraint: the receiver resolves dot segments and escapes, so /hooks/../admin or
+	// /hooks/%2e%2e/admin would pass a prefix check on the raw path yet reach a path outside it.
+	if candidate.RawPath != "" || !isCleanPath(candidate.Path) {
+		return false
+	}
+	for _, allowed := range w.AllowedCallbackURLs {
+		prefix, err := url.Parse(allowed)
+		if err != nil {
+			continue
+		}
+		if !strings.EqualFold(candidate.Scheme, prefix.Scheme) || !strings.EqualFold(candidate.Host, prefix.Host) {
+			continue
+		}
+		// Non-obvious constraint: a prefix ending without "/" must not match a sibling path such as /hooks-evil.
+		if prefix.Path == "" || candidate.Path == prefix.Path ||
+			strings.HasPrefix(candidate.Path, strings.TrimSuffix(prefix.Path, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isCleanPath reports whether p is unchanged by path.Clean, apart from a trailing slash.
+func isCleanPath(p string) bool {
+	if p == "" {
+		return true
+	}
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned == p
+}
+
+// AllowsCallbackSecretEnv reports whether an intent may sign its callback with secretEnv.
+func (w *WebhookConfig) AllowsCallbackSecretEnv(secretEnv string) bool {
+	if w == nil {
+		return false
+	}
+	for _, allowed := range w.AllowedCallbackSecretEnvs {
+		if allowed == secretEnv {
+			return true
+		}
+	}
+	return false
 }
 
 var allowedOutcomes = map[GatewayType]map[string]struct{}{
@@ -201,12 +431,9 @@ func buildRegistry(cfg fileConfig) (Registry, error) {
 			return Registry{}, fmt.Errorf("targets[%d].gatewayType must be one of: sms, push", i)
 		}
 
-		gatewayURL := strings.TrimSpace(target.GatewayURL)
-		if gatewayURL == "" {
-			return Registry{}, fmt.Errorf("targets[%d].gatewayUrl is required", i)
-		}
-		if err := validateGatewayURL(gatewayURL); err != nil {
-			return Registry{}, fmt.Errorf("targets[%d].gatewayUrl %v", i, err)
+		gatewayURL, gatewayURLs, endpointStrategy, err := buildGatewayEndpoints(target, i)
+		if err != nil {
+			return Registry{}, err
 		}
 
 		if target.MaxAcceptanceSeconds < 0 {
@@ -215,6 +442,15 @@ func buildRegistry(cfg fileConfig) (Registry, error) {
 		if target.MaxAttempts < 0 {
 			return Registry{}, fmt.Errorf("targets[%d].maxAttempts must be zero or greater", i)
 		}
+		if target.AttemptTimeoutSeconds < 0 {
+			return Registry{}, fmt.Errorf("targets[%d].attemptTimeoutSeconds must be zero or greater", i)
+		}
+		if target.MaxPendingIntents < 0 {
+			return Registry{}, fmt.Errorf("targets[%d].maxPendingIntents must be zero or greater", i)
+		}
+		if target.MaxExemptAttempts < 0 {
+			return Registry{}, fmt.Errorf("targets[%d].maxExemptAttempts must be zero or greater", i)
+		}
 
 		policyValue := strings.TrimSpace(target.Policy)
 		if policyValue == "" {
@@ -246,8 +482,31 @@ func buildRegistry(cfg fileConfig) (Registry, error) {
 			if target.MaxAcceptanceSeconds > 0 {
 				return Registry{}, fmt.Errorf("targets[%d].maxAcceptanceSeconds must be empty when policy is one_shot", i)
 			}
+		case string(PolicyDeadlineOrMaxAttempts):
+			policy = PolicyDeadlineOrMaxAttempts
+			if target.MaxAcceptanceSeconds <= 0 {
+				return Registry{}, fmt.Errorf("targets[%d].maxAcceptanceSeconds must be greater than zero", i)
+			}
+			if target.MaxAttempts <= 0 {
+				return Registry{}, fmt.Errorf("targets[%d].maxAttempts must be greater than zero", i)
+			}
+		default:
+			return Registry{}, fmt.Errorf("targets[%d].policy must be one of: deadline, max_attempts, one_shot, deadline_or_max_attempts", i)
+		}
+		if policy.HasDeadline() && target.MaxExemptAttempts > 0 {
+			return Registry{}, fmt.Errorf("targets[%d].maxExemptAttempts must be empty when policy has a deadline", i)
+		}
+		if policy.HasDeadline() && target.AttemptTimeoutSeconds > target.MaxAcceptanceSeconds {
+			return Registry{}, fmt.Errorf("targets[%d].attemptTimeoutSeconds must not exceed maxAcceptanceSeconds", i)
+		}
+		var unresolvedSend UnresolvedSend
+		switch strings.TrimSpace(target.UnresolvedSend) {
+		case "", string(UnresolvedSendRetry):
+			unresolvedSend = UnresolvedSendRetry
+		case string(UnresolvedSendReview):
+			unresolvedSend = UnresolvedSendReview
 		default:
-			return Registry{}, fmt.Errorf("targets[%d].policy must be one of: deadline, max_attempts, one_shot", i)
+			return Registry{}, fmt.Errorf("targets[%d].unresolvedSend must be one of: review, retry", i)
 		}
 
 		if len(target.TerminalOutcomes) == 0 {
@@ -272,26 +531,121 @@ func buildRegistry(cfg fileConfig) (Registry, error) {
 			outcomes = append(outcomes, trimmed)
 		}
 
+		var exemptClasses []AttemptErrorClass
+		seenClasses := make(map[AttemptErrorClass]struct{}, len(target.BudgetExemptErrorClasses))
+		for _, raw := range target.BudgetExemptErrorClasses {
+			class := AttemptErrorClass(strings.TrimSpace(raw))
+			if _, ok := knownErrorClasses[class]; !ok {
+				return Registry{}, fmt.Errorf("targets[%d].budgetExemptErrorClasses contains unknown class %q", i, class)
+			}
+			if _, ok := seenClasses[class]; ok {
+				return Registry{}, fmt.Errorf("targets[%d].budgetExemptErrorClasses contains duplicate value %q", i, class)
+			}
+			seenClasses[class] = struct{}{}
+			exemptClasses = append(exemptClasses, class)
+		}
+
+		outcomeRules, err := buildOutcomeRules(target.OutcomeRules, allowed, seen, i)
+		if err != nil {
+			return Registry{}, err
+		}
+		errorClassRules, err := buildErrorClassRules(target.ErrorClassRules, i)
+		if err != nil {
+			return Registry{}, err
+		}
+
+		deliveryWindow, err := validateDeliveryWindow(target.DeliveryWindow, gatewayType, i)
+		if err != nil {
+			return Registry{}, err
+		}
+		if deliveryWindow != nil && deliveryWindow.ExcludeDeferralFromDeadline && !policy.HasDeadline() {
+			return Registry{}, fmt.Errorf("targets[%d].deliveryWindow.excludeDeferralFromDeadline requires a policy with a deadline", i)
+		}
+
 		webhook, err := validateWebhook(target.Webhook, cfg.AllowUnsignedWebhooks, i)
 		if err != nil {
 			return Registry{}, err
 		}
 
 		registry.Targets[submissionTarget] = TargetContract{
-			SubmissionTarget:     submissionTarget,
-			GatewayType:          gatewayType,
-			GatewayURL:           gatewayURL,
-			Policy:               policy,
-			MaxAcceptanceSeconds: target.MaxAcceptanceSeconds,
-			MaxAttempts:          target.MaxAttempts,
-			TerminalOutcomes:     outcomes,
-			Webhook:              webhook,
+			SubmissionTarget:         submissionTarget,
+			GatewayType:              gatewayType,
+			GatewayURL:               gatewayURL,
+			GatewayURLs:              gatewayURLs,
+			EndpointStrategy:         endpointStrategy,
+			Policy:                   policy,
+			MaxAcceptanceSeconds:     target.MaxAcceptanceSeconds,
+			MaxAttempts:              target.MaxAttempts,
+			AttemptTimeoutSeconds:    target.AttemptTimeoutSeconds,
+			UnresolvedSend:           unresolvedSend,
+			BudgetExemptErrorClasses: exemptClasses,
+			BudgetExemptBackpressure: target.BudgetExemptBackpressure,
+			MaxExemptAttempts:        target.MaxExemptAttempts,
+			TerminalOutcomes:         outcomes,
+			OutcomeRules:             outcomeRules,
+			ErrorClassRules:          errorClassRules,
+			DeliveryWindow:           deliveryWindow,
+			MaxPendingIntents:        target.MaxPendingIntents,
+			AdmissionExempt:          target.AdmissionExempt,
+			Webhook:                  webhook,
 		}
 	}
 
+	aliases, err := buildAliases(cfg.Aliases, registry.Targets)
+	if err != nil {
+		return Registry{}, err
+	}
+	registry.Aliases = aliases
+
 	return registry, nil
 }
 
+// buildGatewayEndpoints validates gatewayUrl, or gatewayUrls and endpointStrategy, of which a
+// target sets exactly one form.
+func buildGatewayEndpoints(target targetConfig, idx int) (string, []string, EndpointStrategy, error) {
+	gatewayURL := strings.TrimSpace(target.GatewayURL)
+	if len(target.GatewayURLs) == 0 {
+		if gatewayURL == "" {
+			return "", nil, "", fmt.Errorf("targets[%d].gatewayUrl is required", idx)
+		}
+		if err := validateGatewayURL(gatewayURL); err != nil {
+			return "", nil, "", fmt.Errorf("targets[%d].gatewayUrl %v", idx, err)
+		}
+		if strings.TrimSpace(target.EndpointStrategy) != "" {
+			return "", nil, "", fmt.Errorf("targets[%d].endpointStrategy requires gatewayUrls", idx)
+		}
+		return gatewayURL, nil, "", nil
+	}
+	if gatewayURL != "" {
+		return "", nil, "", fmt.Errorf("targets[%d] must set gatewayUrl or gatewayUrls, not both", idx)
+	}
+
+	urls := make([]string, 0, len(target.GatewayURLs))
+	seen := make(map[string]struct{}, len(target.GatewayURLs))
+	for j, raw := range target.GatewayURLs {
+		trimmed := strings.TrimRight(strings.TrimSpace(raw), "/")
+		if err := validateGatewayURL(trimmed); err != nil {
+			return "", nil, "", fmt.Errorf("targets[%d].gatewayUrls[%d] %v", idx, j, err)
+		}
+		if _, ok := seen[trimmed]; ok {
+			return "", nil, "", fmt.Errorf("targets[%d].gatewayUrls contains duplicate value %q", idx, trimmed)
+		}
+		seen[trimmed] = struct{}{}
+		urls = append(urls, trimmed)
+	}
+
+	var strategy EndpointStrategy
+	switch strings.TrimSpace(target.EndpointStrategy) {
+	case "", string(EndpointFailover):
+		strategy = EndpointFailover
+	case string(EndpointRoundRobin):
+		strategy = EndpointRoundRobin
+	default:
+		return "", nil, "", fmt.Errorf("targets[%d].endpointStrategy must be one of: failover, round_robin", idx)
+	}
+	return urls[0], urls, strategy, nil
+}
+
 func validateGatewayURL(raw string) error {
 	parsed, err := url.Parse(raw)
 	if err != nil {
@@ -319,6 +673,18 @@ func validateWebhook(cfg *webhookConfig, allowUnsigned bool, idx int) (*WebhookC
 		return nil, fmt.Errorf("targets[%d].webhook.url %v", idx, err)
 	}
 
+	var format WebhookFormat
+	switch strings.TrimSpace(cfg.Format) {
+	case "", string(WebhookFormatSetu):
+		format = WebhookFormatSetu
+	case string(WebhookFormatCloudEventsStructured):
+		format = WebhookFormatCloudEventsStructured
+	case string(WebhookFormatCloudEventsBinary):
+		format = WebhookFormatCloudEventsBinary
+	default:
+		return nil, fmt.Errorf("targets[%d].webhook.format must be one of: setu, cloudevents_structured, cloudevents_binary", idx)
+	}
+
 	headers, err := normalizeHeaderMap(cfg.Headers, fmt.Sprintf("targets[%d].webhook.headers", idx))
 	if err != nil {
 		return nil, err
@@ -342,11 +708,31 @@ func validateWebhook(cfg *webhookConfig, allowUnsigned bool, idx int) (*WebhookC
 		return nil, fmt.Errorf("targets[%d].webhook.secretEnv is required unless allowUnsignedWebhooks is true", idx)
 	}
 
+	var allowedCallbackURLs []string
+	for j, raw := range cfg.AllowedCallbackURLs {
+		trimmed := strings.TrimSpace(raw)
+		if err := validateWebhookURL(trimmed); err != nil {
+			return nil, fmt.Errorf("targets[%d].webhook.allowedCallbackUrls[%d] %v", idx, j, err)
+		}
+		allowedCallbackURLs = append(allowedCallbackURLs, trimmed)
+	}
+	var allowedCallbackSecretEnvs []string
+	for j, raw := range cfg.AllowedCallbackSecretEnvs {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			return nil, fmt.Errorf("targets[%d].webhook.allowedCallbackSecretEnvs[%d] must not be empty", idx, j)
+		}
+		allowedCallbackSecretEnvs = append(allowedCallbackSecretEnvs, trimmed)
+	}
+
 	return &WebhookConfig{
-		URL:        urlValue,
-		Headers:    headers,
-		HeadersEnv: headersEnv,
-		SecretEnv:  secretEnv,
+		URL:                       urlValue,
+		Format:                    format,
+		Headers:                   headers,
+		HeadersEnv:                headersEnv,
+		SecretEnv:                 secretEnv,
+		AllowedCallbackURLs:       allowedCallbackURLs,
+		AllowedCallbackSecretEnvs: allowedCallbackSecretEnvs,
 	}, nil
 }
 
@@ -385,3 +771,77 @@ func normalizeHeaderMap(input map[string]string, field string) (map[string]strin
 	}
 	return normalized, nil
 }
+
+// buildOutcomeRules validates outcomeRules against the gatewayType's known reasons. A reason may
+// not have both a rule and a terminalOutcomes entry.
+func buildOutcomeRules(raw map[string]retryRuleConfig, allowed, terminal map[string]struct{}, index int) (map[string]RetryRule, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	rules := make(map[string]RetryRule, len(raw))
+	for key, value := range raw {
+		reason := strings.TrimSpace(key)
+		if _, ok := allowed[reason]; !ok {
+			return nil, fmt.Errorf("targets[%d].outcomeRules contains unknown outcome %q", index, reason)
+		}
+		if _, ok := terminal[reason]; ok {
+			return nil, fmt.Errorf("targets[%d].outcomeRules.%s conflicts with terminalOutcomes", index, reason)
+		}
+		if _, ok := rules[reason]; ok {
+			return nil, fmt.Errorf("targets[%d].outcomeRules contains duplicate value %q", index, reason)
+		}
+		rule, err := buildRetryRule(value, true, fmt.Sprintf("targets[%d].outcomeRules.%s", index, reason))
+		if err != nil {
+			return nil, err
+		}
+		rules[reason] = rule
+	}
+	return rules, nil
+}
+
+// buildErrorClassRules validates errorClassRules. Error classes carry no gateway outcome, so they
+// cannot be accepted, and lease_lost attempts are never recorded, so they cannot have a rule.
+func buildErrorClassRules(raw map[string]retryRuleConfig, index int) (map[AttemptErrorClass]RetryRule, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	rules := make(map[AttemptErrorClass]RetryRule, len(raw))
+	for key, value := range raw {
+		class := AttemptErrorClass(strings.TrimSpace(key))
+		if _, ok := knownErrorClasses[class]; !ok || class == ErrorClassLeaseLost {
+			return nil, fmt.Errorf("targets[%d].errorClassRules contains unknown class %q", index, class)
+		}
+		if _, ok := rules[class]; ok {
+			return nil, fmt.Errorf("targets[%d].errorClassRules contains duplicate value %q", index, class)
+		}
+		rule, err := buildRetryRule(value, false, fmt.Sprintf("targets[%d].errorClassRules.%s", index, class))
+		if err != nil {
+			return nil, err
+		}
+		rules[class] = rule
+	}
+	return rules, nil
+}
+
+func buildRetryRule(raw retryRuleConfig, allowAccept bool, field string) (RetryRule, error) {
+	action := RuleAction(strings.TrimSpace(raw.Action))
+	switch action {
+	case RuleRetry, RuleTerminal:
+	case RuleAccept:
+		if !allowAccept {
+			return RetryRule{}, fmt.Errorf("%s.action must be one of: retry, terminal", field)
+		}
+	default:
+		if allowAccept {
+			return RetryRule{}, fmt.Errorf("%s.action must be one of: retry, accept, terminal", field)
+		}
+		return RetryRule{}, fmt.Errorf("%s.action must be one of: retry, terminal", field)
+	}
+	if raw.RetryAfterSeconds < 0 || raw.RetryAfterSeconds > maxRuleRetryAfterSeconds {
+		return RetryRule{}, fmt.Errorf("%s.retryAfterSeconds must be between 0 and %d", field, maxRuleRetryAfterSeconds)
+	}
+	if raw.RetryAfterSeconds > 0 && action != RuleRetry {
+		return RetryRule{}, fmt.Errorf("%s.retryAfterSeconds must be empty unless action is retry", field)
+	}
+	return RetryRule{Action: action, RetryAfterSeconds: raw.RetryAfterSeconds}, nil
+}
@@ -32,6 +32,8 @@ func TestLoadRegistryValidConfig(t *testing.T) {
       "gatewayUrl": "http://localhost:8081",
       "policy": "max_attempts",
       "maxAttempts": 3,
+      "maxPendingIntents": 500,
+      "admissionExempt": true,
       "terminalOutcomes": ["invalid_request", "unregistered_token"]
     }
   ]
@@ -72,6 +74,9 @@ func TestLoadRegistryValidConfig(t *testing.T) {
 	if contract.Webhook.URL != "http://localhost:9999/webhook" {
 		t.Fatalf("expected webhook url, got %q", contract.Webhook.URL)
 	}
+	if contract.Webhook.Format != WebhookFormatSetu {
+		t.Fatalf("expected default webhook format setu, got %q", contract.Webhook.Format)
+	}
 
 	pushContract, ok := registry.ContractFor("push.realtime")
 	if !ok {
@@ -83,6 +88,207 @@ func TestLoadRegistryValidConfig(t *testing.T) {
 	if pushContract.MaxAttempts != 3 {
 		t.Fatalf("expected maxAttempts 3, got %d", pushContract.MaxAttempts)
 	}
+	if pushContract.MaxPendingIntents != 500 || !pushContract.AdmissionExempt {
+		t.Fatalf("expected maxPendingIntents 500 and admissionExempt, got %d %v", pushContract.MaxPendingIntents, pushContract.AdmissionExempt)
+	}
+	if contract.MaxPendingIntents != 0 || contract.AdmissionExempt {
+		t.Fatalf("expected no admission settings for sms.realtime, got %d %v", contract.MaxPendingIntents, contract.AdmissionExempt)
+	}
+}
+
+func TestLoadRegistryDeadlineOrMaxAttempts(t *testing.T) {
+	config := `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "deadline_or_max_attempts",
+      "maxAcceptanceSeconds": 30,
+      "maxAttempts": 3,
+      "attemptTimeoutSeconds": 10,
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`
+	registry, err := LoadRegistry(writeTempConfig(t, config))
+	if err != nil {
+		t.Fatalf("load registry: %v", err)
+	}
+	contract, ok := registry.ContractFor("sms.realtime")
+	if !ok {
+		t.Fatal("expected sms.realtime contract")
+	}
+	if contract.Policy != PolicyDeadlineOrMaxAttempts || !contract.Policy.HasDeadline() {
+		t.Fatalf("expected policy deadline_or_max_attempts, got %q", contract.Policy)
+	}
+	if contract.MaxAcceptanceSeconds != 30 || contract.MaxAttempts != 3 {
+		t.Fatalf("expected both limits, got %d seconds and %d attempts", contract.MaxAcceptanceSeconds, contract.MaxAttempts)
+	}
+}
+
+func TestLoadRegistryRetryRules(t *testing.T) {
+	config := `{
+  "targets": [
+    {
+      "submissionTarget": "push.realtime",
+      "gatewayType": "push",
+      "gatewayUrl": "http://localhost:8081",
+      "policy": "max_attempts",
+      "maxAttempts": 3,
+      "terminalOutcomes": ["invalid_request"],
+      "outcomeRules": {
+        "provider_failure": {"action": "retry", "retryAfterSeconds": 30},
+        "duplicate_reference": {"action": "accept"},
+        "unregistered_token": {"action": "terminal"}
+      },
+      "errorClassRules": {
+        "decode": {"action": "terminal"},
+        "transport": {"action": "retry", "retryAfterSeconds": 2}
+      }
+    }
+  ]
+}
+`
+	registry, err := LoadRegistry(writeTempConfig(t, config))
+	if err != nil {
+		t.Fatalf("load registry: %v", err)
+	}
+	contract, ok := registry.ContractFor("push.realtime")
+	if !ok {
+		t.Fatal("expected push.realtime contract")
+	}
+	wantOutcomes := map[string]RetryRule{
+		"provider_failure":    {Action: RuleRetry, RetryAfterSeconds: 30},
+		"duplicate_reference": {Action: RuleAccept},
+		"unregistered_token":  {Action: RuleTerminal},
+	}
+	if len(contract.OutcomeRules) != len(wantOutcomes) {
+		t.Fatalf("expected %d outcome rules, got %v", len(wantOutcomes), contract.OutcomeRules)
+	}
+	for reason, want := range wantOutcomes {
+		if got := contract.OutcomeRules[reason]; got != want {
+			t.Fatalf("outcomeRules[%s] = %+v, want %+v", reason, got, want)
+		}
+	}
+	if got := contract.ErrorClassRules[ErrorClassDecode]; got.Action != RuleTerminal {
+		t.Fatalf("expected decode to be terminal, got %+v", got)
+	}
+	if got := contract.ErrorClassRules[ErrorClassTransport]; got.Action != RuleRetry || got.RetryAfterSeconds != 2 {
+		t.Fatalf("expected transport to retry after 2s, got %+v", got)
+	}
+}
+
+func TestLoadRegistryRejectsInvalidRetryRules(t *testing.T) {
+	cases := []struct {
+		name        string
+		rules       string
+		wantContain string
+	}{
+		{name: "unknown outcome", rules: `"outcomeRules": {"unregistered_token": {"action": "terminal"}}`, wantContain: "unknown outcome"},
+		{name: "conflicts with terminalOutcomes", rules: `"outcomeRules": {"invalid_request": {"action": "retry"}}`, wantContain: "conflicts with terminalOutcomes"},
+		{name: "unknown action", rules: `"outcomeRules": {"provider_failure": {"action": "drop"}}`, wantContain: "action must be one of"},
+		{name: "delay without retry", rules: `"outcomeRules": {"provider_failure": {"action": "terminal", "retryAfterSeconds": 5}}`, wantContain: "retryAfterSeconds"},
+		{name: "delay above cap", rules: `"outcomeRules": {"provider_failure": {"action": "retry", "retryAfterSeconds": 7200}}`, wantContain: "retryAfterSeconds"},
+		{name: "unknown error class", rules: `"errorClassRules": {"dns": {"action": "retry"}}`, wantContain: "unknown class"},
+		{name: "lease_lost error class", rules: `"errorClassRules": {"lease_lost": {"action": "terminal"}}`, wantContain: "unknown class"},
+		{name: "accept error class", rules: `"errorClassRules": {"transport": {"action": "accept"}}`, wantContain: "action must be one of: retry, terminal"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "deadline",
+      "maxAcceptanceSeconds": 30,
+      "terminalOutcomes": ["invalid_request"],
+      ` + tc.rules + `
+    }
+  ]
+}
+`
+			_, err := LoadRegistry(writeTempConfig(t, config))
+			if err == nil || !strings.Contains(err.Error(), tc.wantContain) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantContain, err)
+			}
+		})
+	}
+}
+
+func TestLoadRegistryGatewayURLs(t *testing.T) {
+	config := `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrls": ["http://zone-a:8080/", " http://zone-b:8080 "],
+      "endpointStrategy": "round_robin",
+      "policy": "one_shot",
+      "terminalOutcomes": ["invalid_request"]
+    },
+    {
+      "submissionTarget": "push.realtime",
+      "gatewayType": "push",
+      "gatewayUrls": ["http://zone-a:8081"],
+      "policy": "one_shot",
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`
+	registry, err := LoadRegistry(writeTempConfig(t, config))
+	if err != nil {
+		t.Fatalf("load registry: %v", err)
+	}
+	sms, _ := registry.ContractFor("sms.realtime")
+	if sms.GatewayURL != "http://zone-a:8080" || strings.Join(sms.GatewayURLs, ",") != "http://zone-a:8080,http://zone-b:8080" {
+		t.Fatalf("unexpected endpoints %q %v", sms.GatewayURL, sms.GatewayURLs)
+	}
+	if sms.EndpointStrategy != EndpointRoundRobin {
+		t.Fatalf("expected round_robin, got %q", sms.EndpointStrategy)
+	}
+	push, _ := registry.ContractFor("push.realtime")
+	if push.EndpointStrategy != EndpointFailover {
+		t.Fatalf("expected failover by default, got %q", push.EndpointStrategy)
+	}
+}
+
+func TestLoadRegistryRejectsInvalidGatewayURLs(t *testing.T) {
+	cases := []struct {
+		name        string
+		endpoints   string
+		wantContain string
+	}{
+		{name: "both forms", endpoints: `"gatewayUrl": "http://a", "gatewayUrls": ["http://b"]`, wantContain: "not both"},
+		{name: "invalid url", endpoints: `"gatewayUrls": ["http://a", "ftp://b"]`, wantContain: "gatewayUrls[1] must use http or https"},
+		{name: "duplicate url", endpoints: `"gatewayUrls": ["http://a", "http://a/"]`, wantContain: "duplicate value"},
+		{name: "unknown strategy", endpoints: `"gatewayUrls": ["http://a"], "endpointStrategy": "random"`, wantContain: "endpointStrategy must be one of"},
+		{name: "strategy without urls", endpoints: `"gatewayUrl": "http://a", "endpointStrategy": "failover"`, wantContain: "endpointStrategy requires gatewayUrls"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      ` + tc.endpoints + `,
+      "policy": "one_shot",
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`
+			_, err := LoadRegistry(writeTempConfig(t, config))
+			if err == nil || !strings.Contains(err.Error(), tc.wantContain) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantContain, err)
+			}
+		})
+	}
 }
 
 func TestLoadRegistryRejectsUnsignedWebhookByDefault(t *testing.T) {
@@ -211,6 +417,58 @@ func TestLoadRegistryRejectsInvalidConfig(t *testing.T) {
 `,
 			wantContain: "duplicated",
 		},
+		{
+			name: "deadline_or_max_attempts without maxAttempts",
+			config: `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "deadline_or_max_attempts",
+      "maxAcceptanceSeconds": 30,
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`,
+			wantContain: "maxAttempts",
+		},
+		{
+			name: "deadline_or_max_attempts without maxAcceptanceSeconds",
+			config: `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "deadline_or_max_attempts",
+      "maxAttempts": 3,
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`,
+			wantContain: "maxAcceptanceSeconds",
+		},
+		{
+			name: "negative maxPendingIntents",
+			config: `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "deadline",
+      "maxAcceptanceSeconds": 30,
+      "maxPendingIntents": -1,
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`,
+			wantContain: "maxPendingIntents",
+		},
 		{
 			name: "non-positive maxAcceptanceSeconds",
 			config: `{
@@ -348,6 +606,122 @@ func TestLoadRegistryRejectsInvalidConfig(t *testing.T) {
 `,
 			wantContain: "maxAttempts",
 		},
+		{
+			name: "attempt timeout beyond deadline",
+			config: `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "deadline",
+      "maxAcceptanceSeconds": 30,
+      "attemptTimeoutSeconds": 45,
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`,
+			wantContain: "attemptTimeoutSeconds",
+		},
+		{
+			name: "unknown budget exempt error class",
+			config: `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "max_attempts",
+      "maxAttempts": 3,
+      "budgetExemptErrorClasses": ["gateway_5xx", "provider_failure"],
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`,
+			wantContain: "budgetExemptErrorClasses",
+		},
+		{
+			name: "max exempt attempts with deadline policy",
+			config: `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "deadline",
+      "maxAcceptanceSeconds": 30,
+      "budgetExemptErrorClasses": ["gateway_5xx"],
+      "maxExemptAttempts": 5,
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`,
+			wantContain: "maxExemptAttempts must be empty",
+		},
+		{
+			name: "negative max exempt attempts",
+			config: `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "one_shot",
+      "maxExemptAttempts": -1,
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`,
+			wantContain: "maxExemptAttempts must be zero or greater",
+		},
+		{
+			name: "unknown webhook format",
+			config: `{
+  "allowUnsignedWebhooks": true,
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "deadline",
+      "maxAcceptanceSeconds": 30,
+      "terminalOutcomes": ["invalid_request"],
+      "webhook": {
+        "url": "http://localhost:9999/webhook",
+        "format": "cloudevents"
+      }
+    }
+  ]
+}
+`,
+			wantContain: "webhook.format",
+		},
+		{
+			name: "invalid allowed callback url",
+			config: `{
+  "allowUnsignedWebhooks": true,
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "deadline",
+      "maxAcceptanceSeconds": 30,
+      "terminalOutcomes": ["invalid_request"],
+      "webhook": {
+        "url": "http://localhost:9999/webhook",
+        "allowedCallbackUrls": ["hooks.example.com/team-a"]
+      }
+    }
+  ]
+}
+`,
+			wantContain: "webhook.allowedCallbackUrls[0]",
+		},
 		{
 			name: "missing terminalOutcomes",
 			config: `{
@@ -381,6 +755,59 @@ func TestLoadRegistryRejectsInvalidConfig(t *testing.T) {
 	}
 }
 
+func TestLoadRegistryUnresolvedSend(t *testing.T) {
+	config := `{
+  "targets": [
+    {
+      "submissionTarget": "sms.realtime",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "max_attempts",
+      "maxAttempts": 3,
+      "attemptTimeoutSeconds": 5,
+      "terminalOutcomes": ["invalid_request"]
+    },
+    {
+      "submissionTarget": "sms.bulk",
+      "gatewayType": "sms",
+      "gatewayUrl": "http://localhost:8080",
+      "policy": "max_attempts",
+      "maxAttempts": 3,
+      "attemptTimeoutSeconds": 5,
+      "unresolvedSend": "review",
+      "terminalOutcomes": ["invalid_request"]
+    },
+    {
+      "submissionTarget": "push.realtime",
+      "gatewayType": "push",
+      "gatewayUrl": "http://localhost:8081",
+      "policy": "one_shot",
+      "terminalOutcomes": ["invalid_request"]
+    }
+  ]
+}
+`
+	registry, err := LoadRegistry(writeTempConfig(t, config))
+	if err != nil {
+		t.Fatalf("load registry: %v", err)
+	}
+	for target, want := range map[string]UnresolvedSend{
+		"sms.realtime":  UnresolvedSendRetry,
+		"sms.bulk":      UnresolvedSendReview,
+		"push.realtime": UnresolvedSendRetry,
+	} {
+		contract, _ := registry.ContractFor(target)
+		if contract.UnresolvedSend != want {
+			t.Fatalf("%s: expected unresolvedSend %q, got %q", target, want, contract.UnresolvedSend)
+		}
+	}
+
+	invalid := strings.Replace(config, `"unresolvedSend": "review"`, `"unresolvedSend": "ignore"`, 1)
+	if _, err := LoadRegistry(writeTempConfig(t, invalid)); err == nil || !strings.Contains(err.Error(), "unresolvedSend must be one of") {
+		t.Fatalf("expected unresolvedSend error, got %v", err)
+	}
+}
+
 func writeTempConfig(t *testing.T, contents string) string {
 	t.Helper()
 
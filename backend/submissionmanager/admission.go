@@ -0,0 +1,234 @@
+package submissionmanager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"gateway/submission"
+)
+
+const (
+	admissionScopeGlobal = "global"
+	admissionScopeTarget = "target"
+
+	// Retry-After bounds for refused intents. The fallback applies while no drain rate has been
+	// observed yet, e.g. right after start or while nothing completes.
+	minAdmissionRetryAfter      = time.Second
+	maxAdmissionRetryAfter      = 5 * time.Minute
+	fallbackAdmissionRetryAfter = 30 * time.Second
+)
+
+// AdmissionRejectedError reports a new intent refused because the pending backlog of its scope
+// (global or its submissionTarget) is at the configured limit. RetryAfter estimates when the
+// backlog will have drained below the limit.
+type AdmissionRejectedError struct {
+	IntentID         string
+	SubmissionTarget string
+	Scope            string
+	Pending          int
+	Limit            int
+	RetryAfter       time.Duration
+}
+
+func (e AdmissionRejectedError) Error() string {
+	return fmt.Sprintf("intent %q refused: %d pending intents at %s limit %d for submissionTarget %q", e.IntentID, e.Pending, e.Scope, e.Limit, e.SubmissionTarget)
+}
+
+// admissionState is the pending backlog SubmitIntent checks against. Counts come from SQL on every
+// refresh; intents admitted by this instance in between are added locally so a burst cannot run
+// past the limit until the next refresh. Rates are the intents per second that left the backlog
+// during the last refresh interval.
+type admissionState struct {
+	maxPending  int
+	loaded      bool
+	refreshedAt time.Time
+	pending     map[string]int
+	admitted    map[string]int
+	rates       map[string]float64
+}
+
+// SetAdmissionLimit sets the global pending-intent limit across all submissionTargets except the
+// admission-exempt ones. Zero disables the global limit.
+func (m *Manager) SetAdmissionLimit(maxPending int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.admission.maxPending = max(maxPending, 0)
+}
+
+// admissionEnabled reports whether any global or per-target limit is configured.
+func (m *Manager) admissionEnabled() bool {
+	m.mu.Lock()
+	maxPending := m.admission.maxPending
+	m.mu.Unlock()
+	if maxPending > 0 {
+		return true
+	}
+	for _, contract := range m.reg.Targets {
+		if contract.MaxPendingIntents > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshAdmission reloads the pending backlog per submissionTarget from SQL and updates the
+// observed drain rates.
+func (m *Manager) RefreshAdmission(ctx context.Context) error {
+	depths, err := m.store.loadQueueDepths(ctx)
+	if err != nil {
+		return err
+	}
+	now := m.clock.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := &m.admission
+	rates := make(map[string]float64)
+	if state.loaded {
+		if elapsed := now.Sub(state.refreshedAt).Seconds(); elapsed > 0 {
+			targets := make(map[string]struct{})
+			for target := range state.pending {
+				targets[target] = struct{}{}
+			}
+			for target := range state.admitted {
+				targets[target] = struct{}{}
+			}
+			for target := range targets {
+				if left := state.pending[target] + state.admitted[target] - depths[target]; left > 0 {
+					rates[target] = float64(left) / elapsed
+				}
+			}
+		}
+	}
+	state.loaded = true
+	state.refreshedAt = now
+	state.pending = depths
+	state.admitted = make(map[string]int)
+	state.rates = rates
+	return nil
+}
+
+// RunAdmissionRefresh reloads the pending backlog every interval until ctx is done. It returns at
+// once when no admission limit is configured. A failed load keeps the last known backlog.
+func (m *Manager) RunAdmissionRefresh(ctx context.Context, interval time.Duration) {
+	if !m.admissionEnabled() {
+		return
+	}
+	for {
+		if err := m.RefreshAdmission(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("admission_refresh_failed", "error", err)
+		}
+		if !sleepWithContext(ctx, interval) {
+			return
+		}
+	}
+}
+
+// checkAdmission refuses intentID when its target's backlog or, unless the target is exempt, the
+// global backlog is at its limit. Existing intents pass so that idempotent replays keep their
+// answer. Until the first refresh the backlog is unknown and every intent is admitted.
+func (m *Manager) checkAdmission(ctx context.Context, intentID string, contract submission.TargetContract) error {
+	rejected, ok := m.admissionExceeded(contract)
+	if !ok {
+		return nil
+	}
+	_, found, err := m.store.loadIntent(ctx, intentID)
+	if err != nil {
+		return err
+	}
+	if found {
+		return nil
+	}
+	if m.metrics != nil {
+		m.metrics.ObserveAdmissionRejected(rejected.Scope)
+	}
+	rejected.IntentID = intentID
+	return rejected
+}
+
+func (m *Manager) admissionExceeded(contract submission.TargetContract) (AdmissionRejectedError, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := &m.admission
+	if !state.loaded {
+		return AdmissionRejectedError{}, false
+	}
+	target := contract.SubmissionTarget
+	if limit := contract.MaxPendingIntents; limit > 0 {
+		pending := state.pending[target] + state.admitted[target]
+		if pending >= limit {
+			return AdmissionRejectedError{
+				SubmissionTarget: target,
+				Scope:            admissionScopeTarget,
+				Pending:          pending,
+				Limit:            limit,
+				RetryAfter:       admissionRetryAfter(pending-limit+1, state.rates[target]),
+			}, true
+		}
+	}
+	if limit := state.maxPending; limit > 0 && !contract.AdmissionExempt {
+		pending, rate := m.globalBacklogLocked()
+		if pending >= limit {
+			return AdmissionRejectedError{
+				SubmissionTarget: target,
+				Scope:            admissionScopeGlobal,
+				Pending:          pending,
+				Limit:            limit,
+				RetryAfter:       admissionRetryAfter(pending-limit+1, rate),
+			}, true
+		}
+	}
+	return AdmissionRejectedError{}, false
+}
+
+// globalBacklogLocked sums the backlog and drain rate of every non-exempt target. m.mu must be held.
+func (m *Manager) globalBacklogLocked() (int, float64) {
+	state := &m.admission
+	pending := 0
+	rate := 0.0
+	counted := func(target string) bool {
+		contract, ok := m.reg.ContractFor(target)
+		return !ok || !contract.AdmissionExempt
+	}
+	for target, count := range state.pending {
+		if counted(target) {
+			pending += count
+		}
+	}
+	for target, count := range state.admitted {
+		if counted(target) {
+			pending += count
+		}
+	}
+	for target, r := range state.rates {
+		if counted(target) {
+			rate += r
+		}
+	}
+	return pending, rate
+}
+
+// noteAdmitted counts an intent inserted by this instance toward the backlog until the next refresh.
+func (m *Manager) noteAdmitted(target string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.admission.loaded {
+		return
+	}
+	m.admission.admitted[target]++
+}
+
+// admissionRetryAfter estimates how long excess intents take to drain at rate intents per second.
+func admissionRetryAfter(excess int, rate float64) time.Duration {
+	if rate <= 0 {
+		return fallbackAdmissionRetryAfter
+	}
+	seconds := math.Ceil(float64(excess) / rate)
+	if seconds >= maxAdmissionRetryAfter.Seconds() {
+		return maxAdmissionRetryAfter
+	}
+	return max(time.Duration(seconds)*time.Second, minAdmissionRetryAfter)
+}
@@ -0,0 +1,86 @@
+package submissionmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gateway/submission"
+)
+
+func TestAdmissionRefusesNewIntentsAboveLimits(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	limited := baseContract(submission.PolicyOneShot)
+	limited.MaxPendingIntents = 2
+	priority := baseContract(submission.PolicyOneShot)
+	priority.SubmissionTarget = "sms.priority"
+	priority.AdmissionExempt = true
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{
+		limited.SubmissionTarget:  limited,
+		priority.SubmissionTarget: priority,
+	}}
+	stub := newStubExecutor(clock, nil)
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	if err := manager.RefreshAdmission(context.Background()); err != nil {
+		t.Fatalf("refresh admission: %v", err)
+	}
+
+	existing := Intent{IntentID: "intent-1", SubmissionTarget: limited.SubmissionTarget, Payload: []byte(`{"a":1}`)}
+	for _, intent := range []Intent{existing, {IntentID: "intent-2", SubmissionTarget: limited.SubmissionTarget}} {
+		if _, err := manager.SubmitIntent(context.Background(), intent); err != nil {
+			t.Fatalf("submit %s: %v", intent.IntentID, err)
+		}
+	}
+	_, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-3", SubmissionTarget: limited.SubmissionTarget})
+	var rejected AdmissionRejectedError
+	if !errors.As(err, &rejected) || rejected.Scope != admissionScopeTarget || rejected.Pending != 2 || rejected.Limit != 2 {
+		t.Fatalf("expected a per-target admission error, got %v", err)
+	}
+	if rejected.RetryAfter != fallbackAdmissionRetryAfter {
+		t.Fatalf("expected the fallback Retry-After without a drain rate, got %s", rejected.RetryAfter)
+	}
+	if replayed, err := manager.SubmitIntent(context.Background(), existing); err != nil || replayed.IntentID != existing.IntentID {
+		t.Fatalf("expected the replay to be answered above the limit: %+v err=%v", replayed, err)
+	}
+
+	// The global limit counts only non-exempt targets and spares exempt ones.
+	manager.SetAdmissionLimit(1)
+	if _, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-4", SubmissionTarget: priority.SubmissionTarget}); err != nil {
+		t.Fatalf("expected the exempt target to be admitted: %v", err)
+	}
+	unlimited := baseContract(submission.PolicyOneShot)
+	peerReg := submission.Registry{Targets: map[string]submission.TargetContract{
+		unlimited.SubmissionTarget: unlimited,
+		priority.SubmissionTarget:  priority,
+	}}
+	peer := newManager(t, peerReg, stub.Exec, clock, db)
+	peer.SetAdmissionLimit(1)
+	if err := peer.RefreshAdmission(context.Background()); err != nil {
+		t.Fatalf("refresh admission: %v", err)
+	}
+	_, err = peer.SubmitIntent(context.Background(), Intent{IntentID: "intent-5", SubmissionTarget: limited.SubmissionTarget})
+	if !errors.As(err, &rejected) || rejected.Scope != admissionScopeGlobal || rejected.Pending != 2 {
+		t.Fatalf("expected a global admission error counting 2 pending intents, got %v", err)
+	}
+}
+
+func TestAdmissionRetryAfter(t *testing.T) {
+	cases := []struct {
+		excess int
+		rate   float64
+		want   time.Duration
+	}{
+		{excess: 10, rate: 0, want: fallbackAdmissionRetryAfter},
+		{excess: 10, rate: 2, want: 5 * time.Second},
+		{excess: 1, rate: 100, want: minAdmissionRetryAfter},
+		{excess: 3, rate: 2, want: 2 * time.Second},
+		{excess: 100000, rate: 1, want: maxAdmissionRetryAfter},
+	}
+	for _, tc := range cases {
+		if got := admissionRetryAfter(tc.excess, tc.rate); got != tc.want {
+			t.Fatalf("admissionRetryAfter(%d, %v) = %s, want %s", tc.excess, tc.rate, got, tc.want)
+		}
+	}
+}
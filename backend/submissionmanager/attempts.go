@@ -2,33 +2,53 @@ package submissionmanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 
+	"gateway/logging"
 	"gateway/submission"
+	"gateway/tracing"
 )
 
 const retryDelay = 5 * time.Second
 
+// exhaustedTerminalError is the exhausted reason when an errorClassRules entry makes an attempt
+// error terminal.
+const exhaustedTerminalError = "terminal_error"
+
+// maxBackpressureDelay caps a gateway Retry-After so a misconfigured gateway cannot park an intent indefinitely.
+const maxBackpressureDelay = time.Hour
+
 const (
 	gatewayAccepted = "accepted"
 	gatewayRejected = "rejected"
 )
 
-func (m *Manager) executeAttempt(ctx context.Context, intentID string, due time.Time) {
+const (
+	outcomeDuplicateReference = "duplicate_reference"
+	attemptOutcomeTimeout     = "attempt_timeout"
+)
+
+// exhaustedAttemptUnresolved is the exhausted reason when a send whose outcome is unknown is left for
+// an operator instead of being resent.
+const exhaustedAttemptUnresolved = "attempt_unresolved"
+
+// exhaustedExemptAttempts is the exhausted reason when a policy without a deadline reaches
+// maxExemptAttempts budget-exempt attempts.
+const exhaustedExemptAttempts = "exempt_attempts"
+
+func (m *Manager) executeAttempt(ctx context.Context, fence LeaseFence, intentID string, due time.Time) {
 	// Flow intent: load intent, call gateway, apply policy, save result.
-	fence, ok := m.currentFence()
-	if !ok {
-		return
-	}
 	start := m.clock.Now()
 
 	intent, attemptCount, ok, err := m.store.loadIntentForExecution(ctx, intentID)
 	if err != nil {
 		if ctx == nil || ctx.Err() == nil {
-			m.notifyLeaseLoss()
+			m.notifyLeaseLoss(fence.Partition)
 		}
 		return
 	}
@@ -38,23 +58,32 @@ func (m *Manager) executeAttempt(ctx context.Context, intentID string, due time.
 	if m.metrics != nil {
 		m.metrics.ObserveQueueDelay(start.Sub(due))
 	}
-	log.Printf("intentId=%q attempt=%d gatewayType=%s action=start", intentID, attemptCount+1, intent.Contract.GatewayType)
-	if intent.Contract.Policy == submission.PolicyDeadline {
-		deadline := intent.CreatedAt.Add(time.Duration(intent.Contract.MaxAcceptanceSeconds) * time.Second)
+	slog.Info("attempt start", logging.IntentID(intentID), logging.Attempt(attemptCount+1), "gatewayType", string(intent.Contract.GatewayType))
+	if deadline, ok := acceptanceDeadline(intent); ok {
 		// Policy vs outcome: do not execute attempts after the acceptance deadline.
 		if !start.Before(deadline) {
 			applied, err := m.store.markExhausted(ctx, fence, intentID, "deadline_exceeded", start)
 			if err != nil || !applied {
 				if ctx == nil || ctx.Err() == nil {
-					m.notifyLeaseLoss()
+					m.notifyLeaseLoss(fence.Partition)
 				}
 				return
 			}
+			m.waiters.notify(intentID)
 			if m.metrics != nil {
 				m.metrics.ObserveIntentTerminal(IntentExhausted, start.Sub(intent.CreatedAt))
+				m.metrics.ObserveAliasTerminal(intent.TargetAlias, intent.SubmissionTarget, IntentExhausted)
 				m.metrics.ObserveExhausted("deadline_exceeded")
 			}
-			log.Printf("intentId=%q status=%s exhaustedReason=%s", intentID, IntentExhausted, "deadline_exceeded")
+			slog.Info("intent exhausted", logging.IntentID(intentID), "status", string(IntentExhausted), "exhaustedReason", "deadline_exceeded")
+			return
+		}
+	}
+
+	if window := intent.Contract.DeliveryWindow; window != nil {
+		// Policy vs outcome: outside the window the attempt is moved to the next opening, not sent.
+		if opening := window.NextOpening(start, window.Location(intent.Payload)); opening.After(start) {
+			m.deferAttempt(ctx, fence, intent, opening, start)
 			return
 		}
 	}
@@ -68,14 +97,42 @@ func (m *Manager) executeAttempt(ctx context.Context, intentID string, due time.
 		defer m.metrics.DecInflight()
 	}
 
-	if !m.isLeader() {
+	if !m.holdsPartition(fence.Partition) {
+		return
+	}
+	// Non-obvious constraint: the claim commits before the gateway call, so a holder that dies before
+	// recording the attempt leaves a claim the next holder reconciles instead of silently resending.
+	// A claim that already exists is unresolved; dropping the partition makes the re-acquire reconcile it.
+	claimed, err := m.store.claimAttempt(ctx, fence, intentID, attemptNumber, start)
+	if err != nil || !claimed {
+		if ctx == nil || ctx.Err() == nil {
+			m.notifyLeaseLoss(fence.Partition)
+		}
 		return
 	}
 
-	outcome, err := m.exec(ctx, AttemptInput{
-		GatewayType: contract.GatewayType,
-		GatewayURL:  contract.GatewayURL,
-		Payload:     payload,
+	// Flow intent: attempts continue the trace started at submit, which may have been on another instance.
+	traceCtx := ctx
+	if parent, ok := tracing.ParseTraceparent(intent.TraceParent, intent.TraceState); ok {
+		traceCtx = tracing.ContextWithSpanContext(ctx, parent)
+	}
+	if !intent.ScheduledAt.IsZero() {
+		_, waitSpan := m.tracer.StartAt(traceCtx, "submission.schedule_wait", intent.ScheduledAt)
+		waitSpan.SetAttribute("intentId", intentID)
+		waitSpan.EndAt(start, nil)
+	}
+	attemptCtx, attemptSpan := m.tracer.Start(traceCtx, "submission.attempt")
+	attemptSpan.SetAttribute("intentId", intentID)
+	attemptSpan.SetAttribute("submissionTarget", intent.SubmissionTarget)
+	attemptSpan.SetAttribute("attempt", strconv.Itoa(attemptNumber))
+
+	outcome, err := m.exec(attemptCtx, AttemptInput{
+		GatewayType:      contract.GatewayType,
+		GatewayURL:       contract.GatewayURL,
+		GatewayURLs:      contract.GatewayURLs,
+		EndpointStrategy: contract.EndpointStrategy,
+		Payload:          payload,
+		Timeout:          time.Duration(contract.AttemptTimeoutSeconds) * time.Second,
 	})
 	finish := m.clock.Now()
 
@@ -86,6 +143,12 @@ func (m *Manager) executeAttempt(ctx context.Context, intentID string, due time.
 	}
 	if err != nil {
 		attempt.Error = err.Error()
+		attempt.ErrorClass = classifyAttemptError(ctx, err)
+		var backpressure GatewayBackpressureError
+		if errors.As(err, &backpressure) {
+			attempt.Backpressure = true
+			attempt.RetryAfter = backpressure.RetryAfter
+		}
 	} else {
 		attempt.GatewayOutcome = GatewayOutcome{
 			Status: strings.TrimSpace(outcome.Status),
@@ -93,26 +156,66 @@ func (m *Manager) executeAttempt(ctx context.Context, intentID string, due time.
 		}
 	}
 
-	retry, due := m.evaluateAttempt(&intent, &attempt)
+	var retry bool
+	if attempt.ErrorClass == submission.ErrorClassTimeout && contract.UnresolvedSend == submission.UnresolvedSendReview {
+		// Non-obvious constraint: the gateway may have accepted the timed-out send, and its dedup only
+		// sees sends still in flight, so under review nothing is resent until an operator has checked.
+		intent.ChargedAttempts++
+		intent.PriorSendUnresolved = true
+		intent.Status = IntentExhausted
+		intent.ExhaustedReason = exhaustedAttemptUnresolved
+	} else {
+		retry, due = m.evaluateAttempt(&intent, &attempt)
+	}
+	attemptSpan.SetAttribute("outcomeStatus", attempt.GatewayOutcome.Status)
+	attemptSpan.SetAttribute("outcomeReason", attempt.GatewayOutcome.Reason)
+	attemptSpan.SetAttribute("errorClass", string(attempt.ErrorClass))
+	var spanErr error
+	if attempt.Error != "" {
+		spanErr = errors.New(attempt.Error)
+	}
+	attemptSpan.End(spanErr)
 	nextDue := ""
 	if retry {
 		nextDue = due.UTC().Format(time.RFC3339Nano)
 	}
-	log.Printf("intentId=%q attempt=%d outcomeStatus=%q outcomeReason=%q error=%q status=%s retry=%t nextDue=%s", intentID, attempt.Number, attempt.GatewayOutcome.Status, attempt.GatewayOutcome.Reason, attempt.Error, intent.Status, retry, nextDue)
+	slog.Info(
+		"attempt result",
+		logging.IntentID(intentID),
+		logging.Attempt(attempt.Number),
+		"outcomeStatus", attempt.GatewayOutcome.Status,
+		"outcomeReason", attempt.GatewayOutcome.Reason,
+		"error", attempt.Error,
+		"errorClass", string(attempt.ErrorClass),
+		"status", string(intent.Status),
+		"retry", retry,
+		"nextDue", nextDue,
+	)
 	var nextAttemptAt *time.Time
 	if retry {
 		nextAttemptAt = &due
 	}
-	applied, err := m.store.recordAttempt(ctx, fence, intentID, attempt, intent.Status, intent.FinalOutcome, intent.ExhaustedReason, nextAttemptAt, finish)
+	applied, err := m.store.recordAttempt(ctx, fence, intent, attempt, nextAttemptAt, finish)
 	if err != nil || !applied {
 		if ctx == nil || ctx.Err() == nil {
-			m.notifyLeaseLoss()
+			m.notifyLeaseLoss(fence.Partition)
 		}
 		return
 	}
+	m.waiters.notify(intentID)
 	if m.metrics != nil {
+		// Non-obvious constraint: error metrics follow the fenced write so an attempt discarded on lease
+		// loss is not counted.
+		if attempt.ErrorClass != "" {
+			m.metrics.ObserveAttemptError(attempt.ErrorClass)
+		}
+		if attempt.Backpressure {
+			m.metrics.ObserveBackpressure()
+		}
 		m.metrics.ObserveAttemptDuration(finish.Sub(start))
-		if attempt.Error != "" {
+		if attempt.ErrorClass == submission.ErrorClassTimeout {
+			m.metrics.ObserveAttemptOutcome(attemptOutcomeTimeout)
+		} else if attempt.Error != "" {
 			m.metrics.ObserveAttemptOutcome("error")
 		} else if attempt.GatewayOutcome.Status != "" {
 			m.metrics.ObserveAttemptOutcome(attempt.GatewayOutcome.Status)
@@ -124,6 +227,7 @@ func (m *Manager) executeAttempt(ctx context.Context, intentID string, due time.
 		}
 		if intent.Status == IntentAccepted || intent.Status == IntentRejected || intent.Status == IntentExhausted {
 			m.metrics.ObserveIntentTerminal(intent.Status, finish.Sub(intent.CreatedAt))
+			m.metrics.ObserveAliasTerminal(intent.TargetAlias, intent.SubmissionTarget, intent.Status)
 			if intent.Status == IntentExhausted {
 				m.metrics.ObserveExhausted(intent.ExhaustedReason)
 			}
@@ -131,39 +235,86 @@ func (m *Manager) executeAttempt(ctx context.Context, intentID string, due time.
 	}
 	if intent.Status == IntentAccepted || intent.Status == IntentRejected || intent.Status == IntentExhausted {
 		intent.CompletedAt = finish
-		m.dispatchWebhook(ctx, intent, finish)
+		m.dispatchWebhook(ctx, fence, intent, finish)
 	}
 	if retry {
-		m.enqueueAttempt(intentID, due)
+		m.enqueueAttempt(intentID, fence.Partition, due)
 	}
 }
 
+// deferAttempt moves the intent's next attempt to the delivery window opening without sending. When
+// deferral counts against the deadline and the opening is past it, the intent is exhausted instead.
+func (m *Manager) deferAttempt(ctx context.Context, fence LeaseFence, intent Intent, opening time.Time, start time.Time) {
+	intentID := intent.IntentID
+	if intent.Contract.DeliveryWindow.ExcludeDeferralFromDeadline {
+		intent.DeferredFor += opening.Sub(start)
+	} else if deadline, ok := acceptanceDeadline(intent); ok && !opening.Before(deadline) {
+		applied, err := m.store.markExhausted(ctx, fence, intentID, "deadline_exceeded", start)
+		if err != nil || !applied {
+			if ctx == nil || ctx.Err() == nil {
+				m.notifyLeaseLoss(fence.Partition)
+			}
+			return
+		}
+		m.waiters.notify(intentID)
+		if m.metrics != nil {
+			m.metrics.ObserveIntentTerminal(IntentExhausted, start.Sub(intent.CreatedAt))
+			m.metrics.ObserveAliasTerminal(intent.TargetAlias, intent.SubmissionTarget, IntentExhausted)
+			m.metrics.ObserveExhausted("deadline_exceeded")
+		}
+		slog.Info("intent exhausted", logging.IntentID(intentID), "status", string(IntentExhausted), "exhaustedReason", "deadline_exceeded", "windowOpening", opening.UTC().Format(time.RFC3339Nano))
+		return
+	}
+	applied, err := m.store.deferAttempt(ctx, fence, intentID, opening, intent.DeferredFor, start)
+	if err != nil || !applied {
+		if ctx == nil || ctx.Err() == nil {
+			m.notifyLeaseLoss(fence.Partition)
+		}
+		return
+	}
+	if m.metrics != nil {
+		m.metrics.ObserveAttemptDeferred()
+	}
+	slog.Info("attempt deferred", logging.IntentID(intentID), "nextDue", opening.UTC().Format(time.RFC3339Nano))
+	m.enqueueAttempt(intentID, fence.Partition, opening)
+}
+
 func (m *Manager) evaluateAttempt(intent *Intent, attempt *Attempt) (bool, time.Time) {
 	// Flow intent: read outcome and decide terminal or retry.
+	priorUnresolved := intent.PriorSendUnresolved
+	// Count the attempt against the budget up front; applyPolicy refunds it for exempt error classes.
+	intent.ChargedAttempts++
+	intent.PriorSendUnresolved = attempt.ErrorClass == submission.ErrorClassTimeout
 	if attempt.Error != "" {
 		return m.applyPolicy(intent, attempt)
 	}
 
 	switch attempt.GatewayOutcome.Status {
 	case gatewayAccepted:
-		if intent.Contract.Policy == submission.PolicyDeadline {
-			deadline := intent.CreatedAt.Add(time.Duration(intent.Contract.MaxAcceptanceSeconds) * time.Second)
-			if !attempt.FinishedAt.Before(deadline) {
-				intent.Status = IntentExhausted
-				intent.ExhaustedReason = "deadline_exceeded"
-				return false, time.Time{}
-			}
-		}
-		intent.Status = IntentAccepted
-		intent.FinalOutcome = attempt.GatewayOutcome
-		return false, time.Time{}
+		return acceptAttempt(intent, attempt)
 	case gatewayRejected:
 		reason := attempt.GatewayOutcome.Reason
 		if reason == "" {
 			attempt.Error = "gateway outcome rejection reason is required"
+			attempt.ErrorClass = submission.ErrorClassContractViolation
+			return m.applyPolicy(intent, attempt)
+		}
+		rule, hasRule := intent.Contract.OutcomeRules[reason]
+		// An accept rule is the operator's statement that this rejection means delivered, so it
+		// also wins over the in-flight duplicate_reference guard below.
+		if hasRule && rule.Action == submission.RuleAccept {
+			return acceptAttempt(intent, attempt)
+		}
+		// Non-obvious constraint: the payload (and its referenceId) is identical on every attempt, so a
+		// duplicate_reference after a timeout means the timed-out send is still in flight. Its result is
+		// unknown, so it must not be treated as a terminal rejection.
+		if priorUnresolved && reason == outcomeDuplicateReference {
+			intent.PriorSendUnresolved = true
+			attempt.Error = "duplicate_reference after attempt_timeout: previous send still in flight"
+			attempt.ErrorClass = submission.ErrorClassTimeout
 			return m.applyPolicy(intent, attempt)
 		}
-		if isTerminalOutcome(intent.Contract.TerminalOutcomes, reason) {
+		if (hasRule && rule.Action == submission.RuleTerminal) || isTerminalOutcome(intent.Contract.TerminalOutcomes, reason) {
 			intent.Status = IntentRejected
 			intent.FinalOutcome = attempt.GatewayOutcome
 			return false, time.Time{}
@@ -171,35 +322,101 @@ func (m *Manager) evaluateAttempt(intent *Intent, attempt *Attempt) (bool, time.
 		return m.applyPolicy(intent, attempt)
 	case "":
 		attempt.Error = "gateway outcome status is required"
+		attempt.ErrorClass = submission.ErrorClassContractViolation
 		return m.applyPolicy(intent, attempt)
 	default:
 		attempt.Error = fmt.Sprintf("unknown gateway outcome status %q", attempt.GatewayOutcome.Status)
+		attempt.ErrorClass = submission.ErrorClassContractViolation
 		return m.applyPolicy(intent, attempt)
 	}
 }
 
+// acceptAttempt completes the intent as accepted unless the acceptance came after the deadline.
+// FinalOutcome keeps the gateway's answer, which is a rejection when an accept rule matched.
+func acceptAttempt(intent *Intent, attempt *Attempt) (bool, time.Time) {
+	if deadline, ok := acceptanceDeadline(*intent); ok {
+		if !attempt.FinishedAt.Before(deadline) {
+			intent.Status = IntentExhausted
+			intent.ExhaustedReason = "deadline_exceeded"
+			return false, time.Time{}
+		}
+	}
+	intent.Status = IntentAccepted
+	intent.FinalOutcome = attempt.GatewayOutcome
+	return false, time.Time{}
+}
+
 func (m *Manager) applyPolicy(intent *Intent, attempt *Attempt) (bool, time.Time) {
 	// Flow intent: apply policy to decide retry or exhausted.
+	charged := !isBudgetExempt(intent.Contract.BudgetExemptErrorClasses, attempt.ErrorClass) &&
+		!(attempt.Backpressure && intent.Contract.BudgetExemptBackpressure)
+	if !charged {
+		intent.ChargedAttempts--
+	}
+	rule, hasRule := retryRuleFor(intent.Contract, attempt)
+	if hasRule && attempt.Error != "" && rule.Action == submission.RuleTerminal {
+		intent.Status = IntentExhausted
+		intent.ExhaustedReason = exhaustedTerminalError
+		return false, time.Time{}
+	}
+	// Non-obvious constraint: exempt attempts are otherwise unbounded under policies without a deadline,
+	// so a persistent exempt error would retry forever.
+	if !charged && !intent.Contract.Policy.HasDeadline() &&
+		attempt.Number-intent.ChargedAttempts >= exemptAttemptLimit(intent.Contract) {
+		intent.Status = IntentExhausted
+		intent.ExhaustedReason = exhaustedExemptAttempts
+		return false, time.Time{}
+	}
+	delay := retryDelayFor(attempt)
+	if hasRule && rule.Action == submission.RuleRetry && rule.RetryAfterSeconds > 0 {
+		delay = ruleRetryDelay(rule, attempt)
+	}
 	switch intent.Contract.Policy {
 	case submission.PolicyOneShot:
+		if !charged {
+			return true, attempt.FinishedAt.Add(delay)
+		}
 		intent.Status = IntentExhausted
 		intent.ExhaustedReason = "one_shot"
 		return false, time.Time{}
 	case submission.PolicyMaxAttempts:
-		if attempt.Number >= intent.Contract.MaxAttempts {
+		if !charged {
+			return true, attempt.FinishedAt.Add(delay)
+		}
+		if intent.ChargedAttempts >= intent.Contract.MaxAttempts {
 			intent.Status = IntentExhausted
 			intent.ExhaustedReason = "max_attempts"
 			return false, time.Time{}
 		}
-		return true, attempt.FinishedAt.Add(retryDelay)
+		return true, attempt.FinishedAt.Add(delay)
 	case submission.PolicyDeadline:
-		deadline := intent.CreatedAt.Add(time.Duration(intent.Contract.MaxAcceptanceSeconds) * time.Second)
+		deadline, _ := acceptanceDeadline(*intent)
 		if !attempt.FinishedAt.Before(deadline) {
 			intent.Status = IntentExhausted
 			intent.ExhaustedReason = "deadline_exceeded"
 			return false, time.Time{}
 		}
-		nextDue := attempt.FinishedAt.Add(retryDelay)
+		nextDue := attempt.FinishedAt.Add(delay)
+		if !nextDue.Before(deadline) {
+			intent.Status = IntentExhausted
+			intent.ExhaustedReason = "deadline_exceeded"
+			return false, time.Time{}
+		}
+		return true, nextDue
+	case submission.PolicyDeadlineOrMaxAttempts:
+		// The deadline is checked first: it passed while the attempt was still running.
+		deadline, _ := acceptanceDeadline(*intent)
+		if !attempt.FinishedAt.Before(deadline) {
+			intent.Status = IntentExhausted
+			intent.ExhaustedReason = "deadline_exceeded"
+			return false, time.Time{}
+		}
+		if charged && intent.ChargedAttempts >= intent.Contract.MaxAttempts {
+			intent.Status = IntentExhausted
+			intent.ExhaustedReason = "max_attempts"
+			return false, time.Time{}
+		}
+		nextDue := attempt.FinishedAt.Add(delay)
 		if !nextDue.Before(deadline) {
 			intent.Status = IntentExhausted
 			intent.ExhaustedReason = "deadline_exceeded"
@@ -213,6 +430,88 @@ func (m *Manager) applyPolicy(intent *Intent, attempt *Attempt) (bool, time.Time
 	}
 }
 
+// exemptAttemptLimit returns the contract's cap on budget-exempt attempts; snapshots written before
+// the cap existed store zero and get the default.
+func exemptAttemptLimit(contract submission.TargetContract) int {
+	if contract.MaxExemptAttempts > 0 {
+		return contract.MaxExemptAttempts
+	}
+	return submission.DefaultMaxExemptAttempts
+}
+
+// acceptanceDeadline returns the acceptance deadline of policies bounded by maxAcceptanceSeconds,
+// extended by the time spent deferred when the delivery window excludes deferral from it.
+func acceptanceDeadline(intent Intent) (time.Time, bool) {
+	contract := intent.Contract
+	if !contract.Policy.HasDeadline() {
+		return time.Time{}, false
+	}
+	deadline := intent.CreatedAt.Add(time.Duration(contract.MaxAcceptanceSeconds) * time.Second)
+	if contract.DeliveryWindow != nil && contract.DeliveryWindow.ExcludeDeferralFromDeadline {
+		deadline = deadline.Add(intent.DeferredFor)
+	}
+	return deadline, true
+}
+
+// retryDelayFor returns the fixed retry delay, extended to the gateway's Retry-After under backpressure.
+func retryDelayFor(attempt *Attempt) time.Duration {
+	if attempt.RetryAfter <= retryDelay {
+		return retryDelay
+	}
+	if attempt.RetryAfter > maxBackpressureDelay {
+		return maxBackpressureDelay
+	}
+	return attempt.RetryAfter
+}
+
+// retryRuleFor returns the contract rule for the attempt's error class, or for its rejection reason
+// when the attempt has no error.
+func retryRuleFor(contract submission.TargetContract, attempt *Attempt) (submission.RetryRule, bool) {
+	if attempt.Error != "" {
+		rule, ok := contract.ErrorClassRules[attempt.ErrorClass]
+		return rule, ok
+	}
+	rule, ok := contract.OutcomeRules[attempt.GatewayOutcome.Reason]
+	return rule, ok
+}
+
+// ruleRetryDelay returns the rule's delay; a longer gateway Retry-After under backpressure still wins.
+func ruleRetryDelay(rule submission.RetryRule, attempt *Attempt) time.Duration {
+	delay := time.Duration(rule.RetryAfterSeconds) * time.Second
+	if attempt.Backpressure && attempt.RetryAfter > delay {
+		return min(attempt.RetryAfter, maxBackpressureDelay)
+	}
+	return delay
+}
+
+func isBudgetExempt(exempt []submission.AttemptErrorClass, class submission.AttemptErrorClass) bool {
+	if class == "" {
+		return false
+	}
+	for _, candidate := range exempt {
+		if candidate == class {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyAttemptError maps an executor error to its failure class.
+func classifyAttemptError(ctx context.Context, err error) submission.AttemptErrorClass {
+	if ctx != nil && ctx.Err() != nil {
+		return submission.ErrorClassLeaseLost
+	}
+	var timeout AttemptTimeoutError
+	if errors.As(err, &timeout) || errors.Is(err, context.DeadlineExceeded) {
+		return submission.ErrorClassTimeout
+	}
+	var classified AttemptError
+	if errors.As(err, &classified) && classified.Class != "" {
+		return classified.Class
+	}
+	return submission.ErrorClassTransport
+}
+
 func isTerminalOutcome(outcomes []string, reason string) bool {
 	for _, outcome := range outcomes {
 		if outcome == reason {
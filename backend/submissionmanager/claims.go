@@ -0,0 +1,141 @@
+package submissionmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gateway/logging"
+	"gateway/submission"
+)
+
+// ClaimStrategy selects how a new partition holder resolves attempt claims left by the previous
+// holder, whose gateway call may or may not have been sent.
+type ClaimStrategy string
+
+const (
+	// ClaimRetry records the claimed attempt as timed out and lets the policy decide whether to send
+	// again. The payload, and so its referenceId, is unchanged, so the gateway can deduplicate.
+	ClaimRetry ClaimStrategy = "retry"
+	// ClaimReview records the claimed attempt and exhausts the intent with reason attempt_unresolved,
+	// so nothing is sent again until an operator has checked the provider.
+	ClaimReview ClaimStrategy = "review"
+)
+
+// ParseClaimStrategy parses a claim strategy; an empty value means ClaimRetry.
+func ParseClaimStrategy(raw string) (ClaimStrategy, error) {
+	switch strategy := ClaimStrategy(strings.TrimSpace(raw)); strategy {
+	case "", ClaimRetry:
+		return ClaimRetry, nil
+	case ClaimReview:
+		return ClaimReview, nil
+	default:
+		return "", fmt.Errorf("claim strategy must be %s or %s", ClaimRetry, ClaimReview)
+	}
+}
+
+// SetClaimStrategy assigns how unresolved attempt claims are reconciled on partition takeover.
+func (m *Manager) SetClaimStrategy(strategy ClaimStrategy) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.claimStrategy = strategy
+	m.mu.Unlock()
+}
+
+// reconcileClaims resolves the partition's unresolved attempt claims. It runs after the lease is
+// acquired and before the schedule is rebuilt, so a reconciled retry is picked up by the rebuild.
+func (m *Manager) reconcileClaims(ctx context.Context, fence LeaseFence) error {
+	if !m.holdsPartition(fence.Partition) {
+		return errors.New("claim reconciliation requires the partition lease")
+	}
+	m.mu.Lock()
+	count := m.partitionCount
+	strategy := m.claimStrategy
+	m.mu.Unlock()
+
+	claims, err := m.store.loadAttemptClaims(ctx, fence.Partition, count)
+	if err != nil {
+		return err
+	}
+	for _, claim := range claims {
+		if err := m.resolveClaim(ctx, fence, claim, strategy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveClaim records the claimed attempt with an unknown outcome. Recording it deletes the claim
+// in the same transaction.
+func (m *Manager) resolveClaim(ctx context.Context, fence LeaseFence, claim attemptClaim, strategy ClaimStrategy) error {
+	intent, _, found, err := m.store.loadIntentRow(ctx, claim.intentID)
+	if err != nil {
+		return err
+	}
+	if !found || intent.Status != IntentPending {
+		return nil
+	}
+
+	now := m.clock.Now()
+	attempt := Attempt{
+		Number:     claim.attemptNumber,
+		StartedAt:  claim.claimedAt,
+		FinishedAt: now,
+		Error:      fmt.Sprintf("attempt outcome unknown: holder %s (lease epoch %d) did not record it", claim.holderID, claim.leaseEpoch),
+		ErrorClass: submission.ErrorClassTimeout,
+	}
+	var (
+		retry bool
+		due   time.Time
+	)
+	if strategy == ClaimReview {
+		intent.ChargedAttempts++
+		intent.PriorSendUnresolved = true
+		intent.Status = IntentExhausted
+		intent.ExhaustedReason = exhaustedAttemptUnresolved
+	} else {
+		// Non-obvious constraint: the timeout class marks the send unresolved, so a duplicate_reference
+		// on the retry is not taken as a terminal rejection.
+		retry, due = m.evaluateAttempt(&intent, &attempt)
+	}
+	var nextAttemptAt *time.Time
+	if retry {
+		nextAttemptAt = &due
+	}
+	applied, err := m.store.recordAttempt(ctx, fence, intent, attempt, nextAttemptAt, now)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return errors.New("lease lost while reconciling attempt claims")
+	}
+	m.waiters.notify(intent.IntentID)
+	slog.Warn(
+		"attempt_claim_reconciled",
+		logging.IntentID(intent.IntentID),
+		logging.Attempt(claim.attemptNumber),
+		"claimHolderId", claim.holderID,
+		"claimLeaseEpoch", claim.leaseEpoch,
+		"strategy", string(strategy),
+		"status", string(intent.Status),
+		"retry", retry,
+	)
+	if m.metrics != nil {
+		m.metrics.ObserveClaimReconciled(strategy)
+		if intent.Status == IntentExhausted {
+			m.metrics.ObserveIntentTerminal(IntentExhausted, now.Sub(intent.CreatedAt))
+			m.metrics.ObserveAliasTerminal(intent.TargetAlias, intent.SubmissionTarget, IntentExhausted)
+			m.metrics.ObserveExhausted(intent.ExhaustedReason)
+		}
+	}
+	if intent.Status == IntentExhausted {
+		intent.CompletedAt = now
+		m.dispatchWebhook(ctx, fence, intent, now)
+	}
+	return nil
+}
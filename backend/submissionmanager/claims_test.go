@@ -0,0 +1,118 @@
+package submissionmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gateway/submission"
+)
+
+func TestParseClaimStrategy(t *testing.T) {
+	cases := map[string]ClaimStrategy{"": ClaimRetry, "retry": ClaimRetry, " review ": ClaimReview}
+	for raw, want := range cases {
+		got, err := ParseClaimStrategy(raw)
+		if err != nil || got != want {
+			t.Fatalf("parse %q: got %q err=%v, want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseClaimStrategy("resend"); err == nil {
+		t.Fatalf("expected unknown strategy to fail")
+	}
+}
+
+// claimAndTakeOver leaves an unresolved claim from a holder that died mid-attempt, then hands its
+// lease to a new holder and returns the new fence.
+func claimAndTakeOver(t *testing.T, db *testDB, manager *Manager, intentID string) LeaseFence {
+	t.Helper()
+	dead := activateLeader(t, manager)
+	if claimed, err := manager.store.claimAttempt(context.Background(), dead, intentID, 1, manager.clock.Now()); err != nil || !claimed {
+		t.Fatalf("claim attempt: claimed=%v err=%v", claimed, err)
+	}
+	manager.releasePartition(0)
+	db.rotateLease(t, dead.LeaseName, "holder-b")
+	fence := LeaseFence{LeaseName: dead.LeaseName, HolderID: "holder-b", LeaseEpoch: dead.LeaseEpoch + 1}
+	manager.holdPartition(fence, func() {})
+	return fence
+}
+
+func TestReconcileClaimRetriesWithSamePayload(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := baseContract(submission.PolicyMaxAttempts)
+	contract.MaxAttempts = 3
+	// The claim strategy, not the target's unresolvedSend, decides for claims left by a previous holder.
+	contract.UnresolvedSend = submission.UnresolvedSendReview
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, []execResult{{outcome: GatewayOutcome{Status: "rejected", Reason: outcomeDuplicateReference}}})
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	payload := []byte(`{"referenceId":"ref-1","to":"+1","message":"123456"}`)
+	if _, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget, Payload: payload}); err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+
+	fence := claimAndTakeOver(t, db, manager, "intent-1")
+	if err := manager.reconcileClaims(context.Background(), fence); err != nil {
+		t.Fatalf("reconcile claims: %v", err)
+	}
+	intent, _ := manager.GetIntent("intent-1")
+	if intent.Status != IntentPending || len(intent.Attempts) != 1 {
+		t.Fatalf("expected a pending intent with the unknown attempt recorded, got %q with %d", intent.Status, len(intent.Attempts))
+	}
+	if intent.Attempts[0].ErrorClass != submission.ErrorClassTimeout || !intent.PriorSendUnresolved {
+		t.Fatalf("expected the claimed attempt to be recorded as an unresolved send, got %+v", intent.Attempts[0])
+	}
+	claims, err := manager.store.loadAttemptClaims(context.Background(), 0, 1)
+	if err != nil || len(claims) != 0 {
+		t.Fatalf("expected the claim to be resolved: claims=%d err=%v", len(claims), err)
+	}
+
+	if _, err := manager.rebuildSchedule(context.Background(), 0); err != nil {
+		t.Fatalf("rebuild schedule: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		manager.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	clock.Advance(retryDelay)
+	call := waitForCall(t, stub.calls)
+	if string(call.Payload) != string(payload) {
+		t.Fatalf("expected the retry to resend the same payload, got %s", call.Payload)
+	}
+	intent = waitForAttempts(t, manager, "intent-1", 2)
+	if intent.Status != IntentPending {
+		t.Fatalf("expected duplicate_reference after an unresolved send not to be terminal, got %q", intent.Status)
+	}
+}
+
+func TestReconcileClaimReviewExhaustsWithoutSending(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := baseContract(submission.PolicyMaxAttempts)
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, nil)
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	manager.SetClaimStrategy(ClaimReview)
+	if _, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget}); err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+
+	fence := claimAndTakeOver(t, db, manager, "intent-1")
+	if err := manager.reconcileClaims(context.Background(), fence); err != nil {
+		t.Fatalf("reconcile claims: %v", err)
+	}
+	intent, _ := manager.GetIntent("intent-1")
+	if intent.Status != IntentExhausted || intent.ExhaustedReason != exhaustedAttemptUnresolved {
+		t.Fatalf("expected exhausted %s, got %q %q", exhaustedAttemptUnresolved, intent.Status, intent.ExhaustedReason)
+	}
+	if len(intent.Attempts) != 1 {
+		t.Fatalf("expected the unknown attempt in history, got %d attempts", len(intent.Attempts))
+	}
+	assertNoCall(t, stub.calls)
+}
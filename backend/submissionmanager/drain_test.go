@@ -0,0 +1,54 @@
+package submissionmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gateway/submission"
+)
+
+func TestDrainRefusesNewIntentsButAnswersReplays(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := baseContract(submission.PolicyOneShot)
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, nil)
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	existing := Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget, Payload: []byte(`{"a":1}`)}
+	if _, err := manager.SubmitIntent(context.Background(), existing); err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+
+	state, err := manager.SetDrain(context.Background(), true, " schema migration ")
+	if err != nil {
+		t.Fatalf("set drain: %v", err)
+	}
+	if !state.Draining || state.Reason != "schema migration" || state.UpdatedAt.IsZero() {
+		t.Fatalf("unexpected drain state %+v", state)
+	}
+	_, err = manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-2", SubmissionTarget: contract.SubmissionTarget})
+	var draining DrainingError
+	if !errors.As(err, &draining) || draining.IntentID != "intent-2" {
+		t.Fatalf("expected a draining error for a new intent, got %v", err)
+	}
+	if replayed, err := manager.SubmitIntent(context.Background(), existing); err != nil || replayed.IntentID != existing.IntentID {
+		t.Fatalf("expected the replay to be answered while draining: %+v err=%v", replayed, err)
+	}
+
+	// Another instance sees the switch on its next refresh.
+	peer := newManager(t, reg, stub.Exec, clock, db)
+	if err := peer.RefreshDrain(context.Background()); err != nil {
+		t.Fatalf("refresh drain: %v", err)
+	}
+	if !peer.DrainState().Draining {
+		t.Fatalf("expected the peer to see drain mode")
+	}
+	if _, err := manager.SetDrain(context.Background(), false, ""); err != nil {
+		t.Fatalf("clear drain: %v", err)
+	}
+	if _, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-2", SubmissionTarget: contract.SubmissionTarget}); err != nil {
+		t.Fatalf("submit after drain: %v", err)
+	}
+}
@@ -3,30 +3,39 @@ package submissionmanager
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
+	"sort"
 	"sync"
 	"time"
 )
 
 type LeaderRunner struct {
-	store   *sqlStore
+	store   Store
 	manager *Manager
 	cfg     LeaseConfig
 
-	mu     sync.Mutex
-	status LeaseStatus
+	mu            sync.Mutex
+	sessions      map[int]*partitionSession
+	stepDownUntil time.Time
+
+	// handoffMu serializes rebalance ticks with step-downs so a partition is never acquired while
+	// the runner is giving its partitions away.
+	handoffMu sync.Mutex
 }
 
-func NewLeaderRunner(store *sqlStore, manager *Manager, cfg LeaseConfig) *LeaderRunner {
-	status := LeaseStatus{
-		Mode:     leaseModeFollower,
-		HolderID: cfg.HolderID,
-	}
+// partitionSession is one held partition lease and the goroutine that renews it.
+type partitionSession struct {
+	lease  leaseRow
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewLeaderRunner(store Store, manager *Manager, cfg LeaseConfig) *LeaderRunner {
 	return &LeaderRunner{
-		store:   store,
-		manager: manager,
-		cfg:     cfg,
-		status:  status,
+		store:    store,
+		manager:  manager,
+		cfg:      cfg,
+		sessions: make(map[int]*partitionSession),
 	}
 }
 
@@ -41,29 +50,29 @@ func (r *LeaderRunner) Run(ctx context.Context) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	r.setStatus(LeaseStatus{Mode: leaseModeFollower, HolderID: r.cfg.HolderID})
+	// Flow intent: one executor loop serves every partition this instance holds; each acquire tick
+	// heartbeats membership and moves the held set toward this instance's share.
+	r.manager.setPartitionCount(r.cfg.partitionCount())
+	executorDone := make(chan struct{})
+	go func() {
+		r.manager.Run(ctx)
+		close(executorDone)
+	}()
 
 	for {
 		select {
 		case <-ctx.Done():
-			r.manager.setFollower()
-			r.setStatus(LeaseStatus{Mode: leaseModeFollower, HolderID: r.cfg.HolderID})
+			r.stopAll()
+			<-executorDone
 			return
 		default:
 		}
 
-		lease, acquired, err := r.store.acquireLease(ctx, r.cfg)
-		if err != nil {
-			log.Printf("leader_acquire_failed holder_id=%s sql_error=%v", r.cfg.HolderID, err)
-		} else if !acquired {
-			log.Printf("leader_acquire_failed holder_id=%s", r.cfg.HolderID)
-		} else {
-			r.runLeader(ctx, lease)
-		}
+		r.rebalance(ctx)
 
 		if !sleepWithContext(ctx, r.cfg.AcquireInterval) {
-			r.manager.setFollower()
-			r.setStatus(LeaseStatus{Mode: leaseModeFollower, HolderID: r.cfg.HolderID})
+			r.stopAll()
+			<-executorDone
 			return
 		}
 	}
@@ -72,7 +81,24 @@ func (r *LeaderRunner) Run(ctx context.Context) {
 func (r *LeaderRunner) Status() LeaseStatus {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	return r.status
+	status := LeaseStatus{Mode: leaseModeFollower, HolderID: r.cfg.HolderID}
+	if len(r.sessions) == 0 {
+		return status
+	}
+	status.Mode = leaseModeLeader
+	for partition, session := range r.sessions {
+		status.Partitions = append(status.Partitions, PartitionLease{
+			Partition:  partition,
+			LeaseEpoch: session.lease.leaseEpoch,
+			ExpiresAt:  session.lease.expiresAt,
+		})
+	}
+	sort.Slice(status.Partitions, func(i, j int) bool {
+		return status.Partitions[i].Partition < status.Partitions[j].Partition
+	})
+	status.LeaseEpoch = status.Partitions[0].LeaseEpoch
+	status.ExpiresAt = status.Partitions[0].ExpiresAt
+	return status
 }
 
 func (r *LeaderRunner) IsLeader() bool {
@@ -88,7 +114,228 @@ func (r *LeaderRunner) CurrentLease() (holderID string, epoch int64, ok bool) {
 	return status.HolderID, status.LeaseEpoch, true
 }
 
-func (r *LeaderRunner) runLeader(ctx context.Context, lease leaseRow) {
+// rebalance keeps between floor(N/live) and ceil(N/live) partitions. Free partitions are taken up
+// to the ceiling so none stay orphaned; a partition is shed only while another live member is below
+// the floor, one per tick, so a single-partition deployment never flips leadership between healthy
+// instances.
+func (r *LeaderRunner) rebalance(ctx context.Context) {
+	r.handoffMu.Lock()
+	defer r.handoffMu.Unlock()
+	r.mu.Lock()
+	holdingOff := time.Now().Before(r.stepDownUntil)
+	r.mu.Unlock()
+	if holdingOff {
+		return
+	}
+
+	count := r.cfg.partitionCount()
+	members, err := r.store.heartbeatMember(ctx, r.cfg)
+	if err != nil {
+		slog.Error("member_heartbeat_failed", "holderId", r.cfg.HolderID, "error", err)
+		return
+	}
+	// Non-obvious constraint: lease names depend on the partition count, so instances with different
+	// counts would hold different leases over the same intents and run them twice.
+	for _, member := range members {
+		if member.partitions != count {
+			slog.Error("partition_count_mismatch", "holderId", r.cfg.HolderID, "partitions", count, "memberId", member.holderID, "memberPartitions", member.partitions)
+			return
+		}
+	}
+	live := max(len(members), 1)
+	floor := count / live
+	ceiling := (count + live - 1) / live
+
+	held := r.heldPartitions()
+	if len(held) > floor {
+		holders, err := r.store.countPartitionHolders(ctx, r.cfg)
+		if err != nil {
+			slog.Error("leader_rebalance_failed", "holderId", r.cfg.HolderID, "error", err)
+			return
+		}
+		if memberBelow(members, holders, floor, r.cfg.HolderID) || len(held) > ceiling {
+			r.handOff(ctx, held[len(held)-1:], r.cfg.LeaseDuration, "rebalance")
+			return
+		}
+	}
+
+	for partition := 0; partition < count && len(held) < ceiling; partition++ {
+		if r.holdsPartition(partition) {
+			continue
+		}
+		cfg := r.cfg.partitionLease(partition)
+		lease, acquired, err := r.store.acquireLease(ctx, cfg)
+		if err != nil {
+			slog.Error("leader_acquire_failed", "holderId", r.cfg.HolderID, "partition", partition, "error", err)
+			continue
+		}
+		if !acquired {
+			continue
+		}
+		r.startPartition(ctx, partition, lease)
+		held = append(held, partition)
+	}
+}
+
+func memberBelow(members []leaseMember, holders map[string]int, floor int, self string) bool {
+	for _, member := range members {
+		if member.holderID != self && holders[member.holderID] < floor {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *LeaderRunner) heldPartitions() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	held := make([]int, 0, len(r.sessions))
+	for partition := range r.sessions {
+		held = append(held, partition)
+	}
+	sort.Ints(held)
+	return held
+}
+
+func (r *LeaderRunner) holdsPartition(partition int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.sessions[partition]
+	return ok
+}
+
+func (r *LeaderRunner) startPartition(ctx context.Context, partition int, lease leaseRow) {
+	sessionCtx, cancel := context.WithCancel(ctx)
+	session := &partitionSession{lease: lease, cancel: cancel, done: make(chan struct{})}
+	r.mu.Lock()
+	r.sessions[partition] = session
+	r.mu.Unlock()
+	go func() {
+		defer close(session.done)
+		r.runPartition(sessionCtx, partition, lease)
+		r.mu.Lock()
+		if r.sessions[partition] == session {
+			delete(r.sessions, partition)
+		}
+		r.mu.Unlock()
+	}()
+}
+
+// StepDown gives up every held partition without stopping the process: dequeuing stops, in-flight
+// attempts get up to wait to finish while the leases are still renewed, then the leases are
+// released in SQL and the member row is removed. The runner does not acquire again for one lease
+// duration so a follower takes over on its next acquire tick. It returns the released partitions.
+func (r *LeaderRunner) StepDown(ctx context.Context, wait time.Duration) []int {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r.handoffMu.Lock()
+	defer r.handoffMu.Unlock()
+	r.mu.Lock()
+	r.stepDownUntil = time.Now().Add(r.cfg.LeaseDuration)
+	r.mu.Unlock()
+
+	released := r.handOff(ctx, r.heldPartitions(), wait, "step_down")
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), leaseReleaseTimeout)
+	defer cancel()
+	if err := r.store.removeMember(releaseCtx, r.cfg); err != nil {
+		slog.Error("member_remove_failed", "holderId", r.cfg.HolderID, "error", err)
+	}
+	slog.Info("leader_stepped_down", "holderId", r.cfg.HolderID, "partitions", released)
+	return released
+}
+
+// leaseReleaseTimeout bounds the SQL release after a handoff, which may run after ctx is done.
+const leaseReleaseTimeout = 5 * time.Second
+
+// handOff releases partitions gracefully: stop dequeuing, let in-flight attempts finish for up to
+// wait while the leases are still renewed and membership heartbeated, then expire the leases in SQL so another instance can
+// take them without waiting for expiry.
+func (r *LeaderRunner) handOff(ctx context.Context, partitions []int, wait time.Duration, reason string) []int {
+	type handoff struct {
+		partition int
+		session   *partitionSession
+		idle      <-chan struct{}
+	}
+	handoffs := make([]handoff, 0, len(partitions))
+	for _, partition := range partitions {
+		r.mu.Lock()
+		session, ok := r.sessions[partition]
+		r.mu.Unlock()
+		if !ok {
+			continue
+		}
+		handoffs = append(handoffs, handoff{partition: partition, session: session, idle: r.manager.releasePartition(partition)})
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	heartbeat := time.NewTicker(r.memberHeartbeatInterval())
+	defer heartbeat.Stop()
+	waiting := true
+	for i := 0; i < len(handoffs) && waiting; {
+		select {
+		case <-handoffs[i].idle:
+			i++
+		case <-heartbeat.C:
+			// Non-obvious constraint: the rebalance tick that normally heartbeats membership is blocked
+			// here, and the member row expires after one lease duration, the same bound as a rebalance wait.
+			if _, err := r.store.heartbeatMember(ctx, r.cfg); err != nil && ctx.Err() == nil {
+				slog.Error("member_heartbeat_failed", "holderId", r.cfg.HolderID, "error", err)
+			}
+		case <-timer.C:
+			waiting = false
+		case <-ctx.Done():
+			waiting = false
+		}
+	}
+
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), leaseReleaseTimeout)
+	defer cancel()
+	released := make([]int, 0, len(handoffs))
+	for _, h := range handoffs {
+		h.session.cancel()
+		<-h.session.done
+
+		r.mu.Lock()
+		epoch := h.session.lease.leaseEpoch
+		r.mu.Unlock()
+		cfg := r.cfg.partitionLease(h.partition)
+		if _, err := r.store.releaseLease(releaseCtx, cfg, epoch); err != nil {
+			slog.Error("leader_release_failed", "holderId", r.cfg.HolderID, "partition", h.partition, "leaseEpoch", epoch, "error", err)
+			continue
+		}
+		released = append(released, h.partition)
+		slog.Info("leader_released", "holderId", r.cfg.HolderID, "partition", h.partition, "leaseEpoch", epoch, "reason", reason)
+		r.recordLeaseEvent(releaseCtx, h.partition, epoch, leaseEventReleased, reason)
+	}
+	return released
+}
+
+// memberHeartbeatInterval is how often a handoff wait heartbeats membership: the renew interval,
+// which keeps leases alive under the same expiry.
+func (r *LeaderRunner) memberHeartbeatInterval() time.Duration {
+	if r.cfg.RenewInterval > 0 {
+		return r.cfg.RenewInterval
+	}
+	return max(r.cfg.LeaseDuration/3, time.Millisecond)
+}
+
+func (r *LeaderRunner) stopAll() {
+	r.mu.Lock()
+	sessions := make([]*partitionSession, 0, len(r.sessions))
+	for _, session := range r.sessions {
+		sessions = append(sessions, session)
+	}
+	r.mu.Unlock()
+	for _, session := range sessions {
+		session.cancel()
+		<-session.done
+	}
+	r.manager.setFollower()
+}
+
+func (r *LeaderRunner) runPartition(ctx context.Context, partition int, lease leaseRow) {
 	lostCh := make(chan error, 1)
 	var lostOnce sync.Once
 	signalLoss := func(err error) {
@@ -97,72 +344,84 @@ func (r *LeaderRunner) runLeader(ctx context.Context, lease leaseRow) {
 		})
 	}
 
+	cfg := r.cfg.partitionLease(partition)
 	fence := LeaseFence{
-		LeaseName:  r.cfg.LeaseName,
-		HolderID:   r.cfg.HolderID,
+		LeaseName:  cfg.LeaseName,
+		HolderID:   cfg.HolderID,
 		LeaseEpoch: lease.leaseEpoch,
+		Partition:  partition,
 	}
-	r.manager.setLeader(fence, func() {
+	r.manager.holdPartition(fence, func() {
 		signalLoss(errors.New("lease lost"))
 	})
-	r.setStatus(LeaseStatus{
-		Mode:       leaseModeLeader,
-		HolderID:   r.cfg.HolderID,
-		LeaseEpoch: lease.leaseEpoch,
-		ExpiresAt:  lease.expiresAt,
-	})
-	log.Printf("leader_acquired holder_id=%s lease_epoch=%d expires_at=%s", r.cfg.HolderID, lease.leaseEpoch, lease.expiresAt.UTC().Format(time.RFC3339Nano))
+	slog.Info("leader_acquired", "holderId", r.cfg.HolderID, "partition", partition, "leaseEpoch", lease.leaseEpoch, "expiresAt", lease.expiresAt.UTC().Format(time.RFC3339Nano))
+	r.recordLeaseEvent(ctx, partition, lease.leaseEpoch, leaseEventAcquired, "")
 
-	cursor, err := r.manager.rebuildSchedule(ctx)
+	var cursor scheduleCursor
+	err := r.manager.reconcileClaims(ctx, fence)
+	if err == nil {
+		cursor, err = r.manager.rebuildSchedule(ctx, partition)
+	}
 	if err != nil {
 		signalLoss(err)
 	}
 
 	if err == nil {
-		renewed, ok, err := r.store.renewLease(ctx, r.cfg, lease.leaseEpoch)
-		if err != nil || !ok {
-			if err != nil {
-				log.Printf("leader_renew_failed holder_id=%s lease_epoch=%d sql_error=%v", r.cfg.HolderID, lease.leaseEpoch, err)
-			} else {
-				log.Printf("leader_renew_failed holder_id=%s lease_epoch=%d", r.cfg.HolderID, lease.leaseEpoch)
-			}
-			signalLoss(err)
-		} else {
-			r.setStatus(LeaseStatus{
-				Mode:       leaseModeLeader,
-				HolderID:   r.cfg.HolderID,
-				LeaseEpoch: renewed.leaseEpoch,
-				ExpiresAt:  renewed.expiresAt,
-			})
-			log.Printf("leader_renewed holder_id=%s lease_epoch=%d expires_at=%s", r.cfg.HolderID, renewed.leaseEpoch, renewed.expiresAt.UTC().Format(time.RFC3339Nano))
+		if !r.renew(ctx, partition, cfg, lease.leaseEpoch) {
+			signalLoss(nil)
 		}
 	}
 
 	select {
 	case err := <-lostCh:
-		r.dropLeadership(err)
+		r.dropPartition(ctx, partition, lease.leaseEpoch, err)
 		return
 	default:
 	}
 
-	leaderCtx, cancel := context.WithCancel(ctx)
+	partitionCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	go r.manager.Run(leaderCtx)
-	go r.runRenewLoop(leaderCtx, lease.leaseEpoch, signalLoss)
-	go r.runRefreshLoop(leaderCtx, cursor, signalLoss)
+	go r.runRenewLoop(partitionCtx, partition, cfg, lease.leaseEpoch, signalLoss)
+	go r.runRefreshLoop(partitionCtx, cursor, signalLoss)
 
 	select {
 	case <-ctx.Done():
 		cancel()
-		r.dropLeadership(nil)
+		r.manager.releasePartition(partition)
 	case err := <-lostCh:
 		cancel()
-		r.dropLeadership(err)
+		r.dropPartition(ctx, partition, lease.leaseEpoch, err)
 	}
 }
 
-func (r *LeaderRunner) runRenewLoop(ctx context.Context, epoch int64, signalLoss func(error)) {
+// renew extends the partition lease and records the new expiry; false means the lease is lost.
+func (r *LeaderRunner) renew(ctx context.Context, partition int, cfg LeaseConfig, epoch int64) bool {
+	renewed, ok, err := r.store.renewLease(ctx, cfg, epoch)
+	if err != nil || !ok {
+		if ctx.Err() != nil {
+			return false
+		}
+		detail := ""
+		if err != nil {
+			detail = err.Error()
+			slog.Error("leader_renew_failed", "holderId", r.cfg.HolderID, "partition", partition, "leaseEpoch", epoch, "error", err)
+		} else {
+			slog.Error("leader_renew_failed", "holderId", r.cfg.HolderID, "partition", partition, "leaseEpoch", epoch)
+		}
+		r.recordLeaseEvent(ctx, partition, epoch, leaseEventRenewFailed, detail)
+		return false
+	}
+	r.mu.Lock()
+	if session, ok := r.sessions[partition]; ok {
+		session.lease = renewed
+	}
+	r.mu.Unlock()
+	slog.Info("leader_renewed", "holderId", r.cfg.HolderID, "partition", partition, "leaseEpoch", renewed.leaseEpoch, "expiresAt", renewed.expiresAt.UTC().Format(time.RFC3339Nano))
+	return true
+}
+
+func (r *LeaderRunner) runRenewLoop(ctx context.Context, partition int, cfg LeaseConfig, epoch int64, signalLoss func(error)) {
 	ticker := time.NewTicker(r.cfg.RenewInterval)
 	defer ticker.Stop()
 	for {
@@ -170,23 +429,12 @@ func (r *LeaderRunner) runRenewLoop(ctx context.Context, epoch int64, signalLoss
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			renewed, ok, err := r.store.renewLease(ctx, r.cfg, epoch)
-			if err != nil || !ok {
-				if err != nil {
-					log.Printf("leader_renew_failed holder_id=%s lease_epoch=%d sql_error=%v", r.cfg.HolderID, epoch, err)
-				} else {
-					log.Printf("leader_renew_failed holder_id=%s lease_epoch=%d", r.cfg.HolderID, epoch)
+			if !r.renew(ctx, partition, cfg, epoch) {
+				if ctx.Err() == nil {
+					signalLoss(nil)
 				}
-				signalLoss(err)
 				return
 			}
-			r.setStatus(LeaseStatus{
-				Mode:       leaseModeLeader,
-				HolderID:   r.cfg.HolderID,
-				LeaseEpoch: renewed.leaseEpoch,
-				ExpiresAt:  renewed.expiresAt,
-			})
-			log.Printf("leader_renewed holder_id=%s lease_epoch=%d expires_at=%s", r.cfg.HolderID, renewed.leaseEpoch, renewed.expiresAt.UTC().Format(time.RFC3339Nano))
 		}
 	}
 }
@@ -199,9 +447,16 @@ func (r *LeaderRunner) runRefreshLoop(ctx context.Context, cursor scheduleCursor
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			// A partition being handed off is no longer scheduled but keeps its lease until in-flight
+			// attempts finish.
+			if !r.manager.holdsPartition(cursor.partition) {
+				continue
+			}
 			next, err := r.manager.refreshSchedule(ctx, cursor)
 			if err != nil {
-				signalLoss(err)
+				if ctx.Err() == nil {
+					signalLoss(err)
+				}
 				return
 			}
 			cursor = next
@@ -209,20 +464,16 @@ func (r *LeaderRunner) runRefreshLoop(ctx context.Context, cursor scheduleCursor
 	}
 }
 
-func (r *LeaderRunner) dropLeadership(err error) {
-	r.manager.setFollower()
-	r.setStatus(LeaseStatus{Mode: leaseModeFollower, HolderID: r.cfg.HolderID})
+func (r *LeaderRunner) dropPartition(ctx context.Context, partition int, epoch int64, err error) {
+	r.manager.releasePartition(partition)
+	detail := ""
 	if err != nil {
-		log.Printf("leader_lost holder_id=%s sql_error=%v", r.cfg.HolderID, err)
+		detail = err.Error()
+		slog.Warn("leader_lost", "holderId", r.cfg.HolderID, "partition", partition, "error", err)
 	} else {
-		log.Printf("leader_lost holder_id=%s", r.cfg.HolderID)
+		slog.Warn("leader_lost", "holderId", r.cfg.HolderID, "partition", partition)
 	}
-}
-
-func (r *LeaderRunner) setStatus(status LeaseStatus) {
-	r.mu.Lock()
-	r.status = status
-	r.mu.Unlock()
+	r.recordLeaseEvent(ctx, partition, epoch, leaseEventLost, detail)
 }
 
 func sleepWithContext(ctx context.Context, delay time.Duration) bool {
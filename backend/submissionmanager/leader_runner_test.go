@@ -2,8 +2,8 @@ package submissionmanager
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
+	"slices"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -51,11 +51,11 @@ func (b *blockingExecutor) Unblock() {
 	}
 }
 
-func newLiveManager(t *testing.T, db *sql.DB, exec AttemptExecutor) *Manager {
+func newLiveManager(t *testing.T, db *testDB, exec AttemptExecutor) *Manager {
 	t.Helper()
 	contract := baseContract(submission.PolicyOneShot)
 	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
-	manager, err := NewManager(reg, exec, Clock{}, db)
+	manager, err := NewManagerWithStore(reg, exec, Clock{}, db.store(t))
 	if err != nil {
 		t.Fatalf("new manager: %v", err)
 	}
@@ -224,16 +224,8 @@ func TestLeaderStopsOnLeaseLoss(t *testing.T) {
 
 	waitForCall(t, exec.calls)
 
-	_, err := db.ExecContext(
-		context.Background(),
-		`UPDATE dbo.submission_manager_leases
-     SET expires_at = DATEADD(SECOND, -1, SYSUTCDATETIME())
-     WHERE lease_name = @p1`,
-		cfg.LeaseName,
-	)
-	if err != nil {
-		t.Fatalf("expire lease: %v", err)
-	}
+	// Another holder takes over; an expired lease would be re-acquired by holder-a on its next tick.
+	db.rotateLease(t, cfg.LeaseName, "holder-b")
 
 	exec.Unblock()
 	assertNoCallFor(t, exec.calls, 500*time.Millisecond)
@@ -279,3 +271,162 @@ func TestLeaderRefreshesFollowerIntents(t *testing.T) {
 	waitForCall(t, leaderExec.calls)
 	assertNoCall(t, followerExec.calls)
 }
+
+func TestPartitionsRebalanceAcrossInstances(t *testing.T) {
+	db := newTestDB(t)
+	managerA := newLiveManager(t, db, newCallRecorder().Exec)
+	managerB := newLiveManager(t, db, newCallRecorder().Exec)
+
+	cfgA := newLeaseConfig("holder-a", 800*time.Millisecond)
+	cfgA.Partitions = 2
+	cfgB := newLeaseConfig("holder-b", 800*time.Millisecond)
+	cfgB.Partitions = 2
+
+	runnerA := NewLeaderRunnerFromManager(managerA, cfgA)
+	ctxA, cancelA := context.WithCancel(context.Background())
+	defer cancelA()
+	go runnerA.Run(ctxA)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && len(runnerA.Status().Partitions) != 2 {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := len(runnerA.Status().Partitions); got != 2 {
+		t.Fatalf("expected a single instance to hold both partitions, got %d", got)
+	}
+
+	runnerB := NewLeaderRunnerFromManager(managerB, cfgB)
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	go runnerB.Run(ctxB)
+
+	deadline = time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if len(runnerA.Status().Partitions) == 1 && len(runnerB.Status().Partitions) == 1 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("expected one partition each; a=%v b=%v", runnerA.Status().Partitions, runnerB.Status().Partitions)
+}
+
+func TestPartitionCountMismatchRefusesToAcquire(t *testing.T) {
+	db := newTestDB(t)
+	execA := newCallRecorder()
+	execB := newCallRecorder()
+	managerA := newLiveManager(t, db, execA.Exec)
+	managerB := newLiveManager(t, db, execB.Exec)
+
+	cfgA := newLeaseConfig("holder-a", 800*time.Millisecond)
+	cfgB := newLeaseConfig("holder-b", 800*time.Millisecond)
+	cfgB.Partitions = 2
+
+	runnerA := NewLeaderRunnerFromManager(managerA, cfgA)
+	ctxA, cancelA := context.WithCancel(context.Background())
+	defer cancelA()
+	go runnerA.Run(ctxA)
+	waitForLeaderOnly(t, runnerA)
+
+	// holder-b would take the leases submission-manager-executor/0 and /1, which holder-a's bare
+	// lease does not exclude.
+	runnerB := NewLeaderRunnerFromManager(managerB, cfgB)
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	go runnerB.Run(ctxB)
+
+	for i := range 4 {
+		intentID := fmt.Sprintf("intent-mismatch-%d", i)
+		if _, err := managerB.SubmitIntent(context.Background(), Intent{IntentID: intentID, SubmissionTarget: "sms.realtime"}); err != nil {
+			t.Fatalf("submit intent: %v", err)
+		}
+		waitForCall(t, execA.calls)
+	}
+	assertNoCallFor(t, execB.calls, 3*cfgB.AcquireInterval)
+	if runnerB.IsLeader() {
+		t.Fatalf("expected the instance with a different partition count not to acquire, got %v", runnerB.Status().Partitions)
+	}
+}
+
+func TestStepDownHandsLeaseToFollower(t *testing.T) {
+	db := newTestDB(t)
+	managerA := newLiveManager(t, db, newCallRecorder().Exec)
+	managerB := newLiveManager(t, db, newCallRecorder().Exec)
+
+	// A long lease with a short acquire interval: the follower can only lead in time if the lease
+	// is released explicitly.
+	cfgA := newLeaseConfig("holder-a", 6*time.Second)
+	cfgA.AcquireInterval = 100 * time.Millisecond
+	cfgB := newLeaseConfig("holder-b", 6*time.Second)
+	cfgB.AcquireInterval = 100 * time.Millisecond
+
+	runnerA := NewLeaderRunnerFromManager(managerA, cfgA)
+	runnerB := NewLeaderRunnerFromManager(managerB, cfgB)
+	ctxA, cancelA := context.WithCancel(context.Background())
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelA()
+	defer cancelB()
+	go runnerA.Run(ctxA)
+	go runnerB.Run(ctxB)
+
+	leaderRunner, followerRunner := waitForLeader(t, runnerA, runnerB)
+	released := leaderRunner.StepDown(context.Background(), time.Second)
+	if len(released) != 1 || released[0] != 0 {
+		t.Fatalf("expected partition 0 released, got %v", released)
+	}
+	if leaderRunner.IsLeader() {
+		t.Fatalf("expected stepped-down runner to be follower")
+	}
+
+	waitForLeaderOnly(t, followerRunner)
+	if leaderRunner.IsLeader() {
+		t.Fatalf("expected stepped-down runner to hold off re-acquiring")
+	}
+}
+
+func TestHandOffKeepsMembershipWhileWaiting(t *testing.T) {
+	db := newTestDB(t)
+	manager := newLiveManager(t, db, newCallRecorder().Exec)
+	cfg := newLeaseConfig("holder-a", 600*time.Millisecond)
+	runner := NewLeaderRunnerFromManager(manager, cfg)
+	manager.setPartitionCount(cfg.partitionCount())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner.rebalance(ctx)
+	waitForLeaderOnly(t, runner)
+	deadline := time.Now().Add(2 * time.Second)
+	for !manager.holdsPartition(0) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	manager.mu.Lock()
+	held, ok := manager.held[0]
+	manager.mu.Unlock()
+	if !ok {
+		t.Fatalf("expected the manager to hold partition 0")
+	}
+	// An attempt stays in flight for longer than the member TTL.
+	held.inflight.Add(1)
+	handedOff := make(chan []int, 1)
+	go func() {
+		handedOff <- runner.handOff(ctx, []int{0}, 3*cfg.LeaseDuration, "rebalance")
+	}()
+
+	time.Sleep(2 * cfg.LeaseDuration)
+	members, err := manager.store.heartbeatMember(ctx, newLeaseConfig("holder-b", cfg.LeaseDuration))
+	if err != nil {
+		t.Fatalf("heartbeat member: %v", err)
+	}
+	if !slices.Contains(members, leaseMember{holderID: "holder-a", partitions: 1}) {
+		t.Fatalf("expected holder-a to stay live during the handoff, got %v", members)
+	}
+
+	held.inflight.Done()
+	select {
+	case released := <-handedOff:
+		if len(released) != 1 || released[0] != 0 {
+			t.Fatalf("expected partition 0 released, got %v", released)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("handoff did not finish after the in-flight attempt")
+	}
+}
@@ -1,53 +1,136 @@
 package submissionmanager
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
-func (m *Manager) setLeader(fence LeaseFence, onLoss func()) {
+// heldPartition is a partition this instance currently executes.
+type heldPartition struct {
+	fence    LeaseFence
+	onLoss   func()
+	inflight *sync.WaitGroup
+}
+
+// setPartitionCount sets how many partitions intents are spread across.
+func (m *Manager) setPartitionCount(count int) {
+	m.mu.Lock()
+	m.partitionCount = max(count, 1)
+	m.mu.Unlock()
+}
+
+// partitionOf returns the partition that executes intentID.
+func (m *Manager) partitionOf(intentID string) int {
+	m.mu.Lock()
+	count := m.partitionCount
+	m.mu.Unlock()
+	return partitionFor(partitionKey(intentID), count)
+}
+
+// holdPartition starts executing intents in fence.Partition. onLoss is called when a fenced write fails.
+func (m *Manager) holdPartition(fence LeaseFence, onLoss func()) {
 	m.mu.Lock()
-	m.leader = true
-	m.leaseFence = fence
-	m.leaseLossFn = onLoss
+	if m.held == nil {
+		m.held = make(map[int]heldPartition)
+	}
+	m.held[fence.Partition] = heldPartition{fence: fence, onLoss: onLoss, inflight: &sync.WaitGroup{}}
+	held := len(m.held)
 	m.mu.Unlock()
+	if m.metrics != nil {
+		m.metrics.SetPartitionsHeld(held)
+	}
+}
+
+// releasePartition stops dequeuing the partition and drops its scheduled attempts. The returned
+// channel closes once the partition's in-flight attempts have finished.
+func (m *Manager) releasePartition(partition int) <-chan struct{} {
+	idle := make(chan struct{})
+	m.mu.Lock()
+	held, ok := m.held[partition]
+	delete(m.held, partition)
+	m.clearPartitionLocked(partition)
+	count := len(m.held)
+	m.mu.Unlock()
+	if m.metrics != nil {
+		m.metrics.SetPartitionsHeld(count)
+	}
+	m.signalWake()
+	if !ok {
+		close(idle)
+		return idle
+	}
+	go func() {
+		held.inflight.Wait()
+		close(idle)
+	}()
+	return idle
 }
 
+// setFollower releases every partition without waiting for in-flight attempts.
 func (m *Manager) setFollower() {
 	m.mu.Lock()
-	m.leader = false
-	m.leaseFence = LeaseFence{}
-	m.leaseLossFn = nil
+	for partition := range m.held {
+		delete(m.held, partition)
+	}
 	m.clearScheduleLocked()
 	m.mu.Unlock()
-	select {
-	case m.wake <- struct{}{}:
-	default:
+	if m.metrics != nil {
+		m.metrics.SetPartitionsHeld(0)
 	}
+	m.signalWake()
 }
 
 func (m *Manager) isLeader() bool {
 	m.mu.Lock()
-	leader := m.leader
+	leader := len(m.held) > 0
 	m.mu.Unlock()
 	return leader
 }
 
-func (m *Manager) currentFence() (LeaseFence, bool) {
+func (m *Manager) holdsPartition(partition int) bool {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-	if !m.leader {
-		return LeaseFence{}, false
+	_, ok := m.held[partition]
+	m.mu.Unlock()
+	return ok
+}
+
+func (m *Manager) notifyLeaseLoss(partition int) {
+	m.mu.Lock()
+	held, ok := m.held[partition]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	// Non-obvious constraint: dequeuing stops here rather than when the runner handles onLoss, so
+	// the executor loop cannot start another attempt of the partition in between.
+	m.releasePartition(partition)
+	if held.onLoss != nil {
+		held.onLoss()
 	}
-	return m.leaseFence, true
 }
 
-func (m *Manager) notifyLeaseLoss() {
+// notifyAllLeaseLoss reports loss for every held partition, used when SQL itself is unreachable.
+func (m *Manager) notifyAllLeaseLoss() {
 	m.mu.Lock()
-	callback := m.leaseLossFn
+	callbacks := make([]func(), 0, len(m.held))
+	for _, held := range m.held {
+		if held.onLoss != nil {
+			callbacks = append(callbacks, held.onLoss)
+		}
+	}
 	m.mu.Unlock()
-	if callback != nil {
+	for _, callback := range callbacks {
 		callback()
 	}
 }
 
+func (m *Manager) signalWake() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
 func normalizeLeaseExpiry(value time.Time) time.Time {
 	if value.IsZero() {
 		return value
@@ -29,19 +29,7 @@ func TestFenceRejectsStaleLeaderWrites(t *testing.T) {
 
 	waitForCall(t, exec.calls)
 
-	if _, err := db.ExecContext(
-		context.Background(),
-		`UPDATE dbo.submission_manager_leases
-     SET holder_id = @p1,
-         lease_epoch = lease_epoch + 1,
-         renewed_at = SYSUTCDATETIME(),
-         expires_at = DATEADD(SECOND, 10, SYSUTCDATETIME())
-     WHERE lease_name = @p2`,
-		"other-holder",
-		cfg.LeaseName,
-	); err != nil {
-		t.Fatalf("rotate lease: %v", err)
-	}
+	db.rotateLease(t, cfg.LeaseName, "other-holder")
 
 	exec.Unblock()
 
@@ -87,19 +75,10 @@ func TestScheduleRefreshHandlesEqualLastModifiedAt(t *testing.T) {
 	}
 
 	fixed := time.Date(2026, 2, 3, 12, 0, 0, 0, time.UTC)
-	if _, err := db.ExecContext(
-		context.Background(),
-		`UPDATE dbo.submission_intents
-     SET last_modified_at = @p1
-     WHERE intent_id = @p2`,
-		fixed,
-		intentA.IntentID,
-	); err != nil {
-		t.Fatalf("force last_modified_at intent A: %v", err)
-	}
+	db.setLastModified(t, intentA.IntentID, fixed)
 
 	activateLeader(t, leader)
-	cursor, err := leader.rebuildSchedule(context.Background())
+	cursor, err := leader.rebuildSchedule(context.Background(), 0)
 	if err != nil {
 		t.Fatalf("rebuild schedule: %v", err)
 	}
@@ -108,16 +87,7 @@ func TestScheduleRefreshHandlesEqualLastModifiedAt(t *testing.T) {
 	if _, err := follower.SubmitIntent(context.Background(), intentB); err != nil {
 		t.Fatalf("submit intent B: %v", err)
 	}
-	if _, err := db.ExecContext(
-		context.Background(),
-		`UPDATE dbo.submission_intents
-     SET last_modified_at = @p1
-     WHERE intent_id = @p2`,
-		fixed,
-		intentB.IntentID,
-	); err != nil {
-		t.Fatalf("force last_modified_at intent B: %v", err)
-	}
+	db.setLastModified(t, intentB.IntentID, fixed)
 
 	cursor, err = leader.refreshSchedule(context.Background(), cursor)
 	if err != nil {
@@ -144,7 +114,7 @@ func TestScheduleUsesSQLTimeWhenLocalAhead(t *testing.T) {
 	}
 	contract := baseContract(submission.PolicyOneShot)
 	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
-	manager, err := NewManager(reg, exec.Exec, clock, db)
+	manager, err := NewManagerWithStore(reg, exec.Exec, clock, db.store(t))
 	if err != nil {
 		t.Fatalf("new manager: %v", err)
 	}
@@ -172,7 +142,7 @@ func TestScheduleUsesSQLTimeWhenLocalBehind(t *testing.T) {
 	}
 	contract := baseContract(submission.PolicyOneShot)
 	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
-	manager, err := NewManager(reg, exec.Exec, clock, db)
+	manager, err := NewManagerWithStore(reg, exec.Exec, clock, db.store(t))
 	if err != nil {
 		t.Fatalf("new manager: %v", err)
 	}
@@ -181,19 +151,10 @@ func TestScheduleUsesSQLTimeWhenLocalBehind(t *testing.T) {
 	if _, err := manager.SubmitIntent(context.Background(), Intent{IntentID: intentID, SubmissionTarget: contract.SubmissionTarget}); err != nil {
 		t.Fatalf("submit intent: %v", err)
 	}
-	if _, err := db.ExecContext(
-		context.Background(),
-		`UPDATE dbo.submission_intents
-     SET next_attempt_at = SYSUTCDATETIME(),
-         last_modified_at = SYSUTCDATETIME()
-     WHERE intent_id = @p1`,
-		intentID,
-	); err != nil {
-		t.Fatalf("force next_attempt_at: %v", err)
-	}
+	db.makeDue(t, intentID)
 
 	activateLeader(t, manager)
-	if _, err := manager.rebuildSchedule(context.Background()); err != nil {
+	if _, err := manager.rebuildSchedule(context.Background(), 0); err != nil {
 		t.Fatalf("rebuild schedule: %v", err)
 	}
 
@@ -15,19 +15,52 @@ type LeaseConfig struct {
 	RenewInterval           time.Duration
 	AcquireInterval         time.Duration
 	ScheduleRefreshInterval time.Duration
+	// Partitions splits execution across this many leases; intents map to a partition by a hash of
+	// intent_id. Every instance sharing LeaseName must use the same value; an instance that sees a
+	// live member with another value acquires nothing. Zero means 1.
+	Partitions int
+}
+
+// leaseMember is a live instance sharing the lease name and the partition count it runs with.
+type leaseMember struct {
+	holderID   string
+	partitions int
 }
 
 // LeaseStatus captures the local view of leadership for readiness.
+// LeaseEpoch and ExpiresAt describe the lowest-numbered held partition.
 type LeaseStatus struct {
 	Mode       string
 	HolderID   string
 	LeaseEpoch int64
 	ExpiresAt  time.Time
+	Partitions []PartitionLease
+}
+
+// PartitionLease is one held partition lease.
+type PartitionLease struct {
+	Partition  int
+	LeaseEpoch int64
+	ExpiresAt  time.Time
 }
 
-// LeaseFence guards executor-side writes with the current lease token.
+// LeaseFence guards executor-side writes with the current lease token of the intent's partition.
 type LeaseFence struct {
 	LeaseName  string
 	HolderID   string
 	LeaseEpoch int64
+	Partition  int
+}
+
+func (cfg LeaseConfig) partitionCount() int {
+	if cfg.Partitions <= 1 {
+		return 1
+	}
+	return cfg.Partitions
+}
+
+func (cfg LeaseConfig) partitionLease(partition int) LeaseConfig {
+	partitioned := cfg
+	partitioned.LeaseName = partitionLeaseName(cfg.LeaseName, partition, cfg.partitionCount())
+	return partitioned
 }
@@ -132,3 +132,160 @@ func (s *sqlStore) readLease(ctx context.Context, leaseName string) (leaseRow, b
 	}
 	return leaseRow{holderID: holderID, leaseEpoch: epoch, expiresAt: normalizeDBTime(expiresAt)}, true, nil
 }
+
+// releaseLease expires a held lease immediately so a follower can acquire it on its next tick.
+func (s *sqlStore) releaseLease(ctx context.Context, cfg LeaseConfig, epoch int64) (bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE dbo.submission_manager_leases
+     SET renewed_at = SYSUTCDATETIME(),
+         expires_at = SYSUTCDATETIME()
+     WHERE lease_name = @p1
+       AND holder_id = @p2
+       AND lease_epoch = @p3
+       AND expires_at > SYSUTCDATETIME()`,
+		strings.TrimSpace(cfg.LeaseName),
+		strings.TrimSpace(cfg.HolderID),
+		epoch,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// heartbeatMember marks the holder live for one lease duration, recording its partition count, and
+// returns the live members ordered by holder id. Partition shares are computed from this list.
+func (s *sqlStore) heartbeatMember(ctx context.Context, cfg LeaseConfig) ([]leaseMember, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	leaseName := strings.TrimSpace(cfg.LeaseName)
+	holderID := strings.TrimSpace(cfg.HolderID)
+	if leaseName == "" || holderID == "" {
+		return nil, errors.New("lease name and holder id are required")
+	}
+	durationMs := cfg.LeaseDuration.Milliseconds()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE dbo.submission_manager_members
+     SET heartbeat_at = SYSUTCDATETIME(),
+         expires_at = DATEADD(MILLISECOND, @p1, SYSUTCDATETIME()),
+         partition_count = @p4
+     WHERE lease_name = @p2 AND holder_id = @p3`,
+		durationMs,
+		leaseName,
+		holderID,
+		cfg.partitionCount(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		_, err = s.db.ExecContext(
+			ctx,
+			`INSERT INTO dbo.submission_manager_members (lease_name, holder_id, heartbeat_at, expires_at, partition_count)
+       VALUES (@p1, @p2, SYSUTCDATETIME(), DATEADD(MILLISECOND, @p3, SYSUTCDATETIME()), @p4)`,
+			leaseName,
+			holderID,
+			durationMs,
+			cfg.partitionCount(),
+		)
+		if err != nil && !isUniqueViolation(err) {
+			return nil, err
+		}
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT holder_id, partition_count
+     FROM dbo.submission_manager_members
+     WHERE lease_name = @p1 AND expires_at > SYSUTCDATETIME()
+     ORDER BY holder_id`,
+		leaseName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var members []leaseMember
+	for rows.Next() {
+		var member leaseMember
+		if err := rows.Scan(&member.holderID, &member.partitions); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// removeMember drops the holder from the live member list so peers recompute their shares without it.
+func (s *sqlStore) removeMember(ctx context.Context, cfg LeaseConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM dbo.submission_manager_members
+     WHERE lease_name = @p1 AND holder_id = @p2`,
+		strings.TrimSpace(cfg.LeaseName),
+		strings.TrimSpace(cfg.HolderID),
+	)
+	return err
+}
+
+// countPartitionHolders returns how many unexpired partition leases each holder has.
+func (s *sqlStore) countPartitionHolders(ctx context.Context, cfg LeaseConfig) (map[string]int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	leaseName := strings.TrimSpace(cfg.LeaseName)
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT holder_id, COUNT(*)
+     FROM dbo.submission_manager_leases
+     WHERE (lease_name = @p1 OR lease_name LIKE @p2 ESCAPE '\')
+       AND expires_at > SYSUTCDATETIME()
+     GROUP BY holder_id`,
+		leaseName,
+		escapeLike(leaseName)+"/%",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var holderID string
+		var count int
+		if err := rows.Scan(&holderID, &count); err != nil {
+			return nil, err
+		}
+		counts[holderID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// escapeLike escapes LIKE wildcards for a pattern used with ESCAPE '\'.
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "[", `\[`)
+	return replacer.Replace(value)
+}
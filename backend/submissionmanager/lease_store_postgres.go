@@ -0,0 +1,284 @@
+package submissionmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// acquireLease takes the lease row with FOR UPDATE SKIP LOCKED: a contender never queues behind the
+// holder's renew or another acquire, it just fails this tick and retries on the next one.
+func (s *postgresStore) acquireLease(ctx context.Context, cfg LeaseConfig) (leaseRow, bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	leaseName := strings.TrimSpace(cfg.LeaseName)
+	holderID := strings.TrimSpace(cfg.HolderID)
+	if leaseName == "" || holderID == "" {
+		return leaseRow{}, false, errors.New("lease name and holder id are required")
+	}
+	durationMs := cfg.LeaseDuration.Milliseconds()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return leaseRow{}, false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var expired bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT expires_at <= utc_now()
+     FROM submission_manager_leases
+     WHERE lease_name = $1
+     FOR UPDATE SKIP LOCKED`,
+		leaseName,
+	).Scan(&expired)
+	var row *sql.Row
+	switch {
+	case err == nil:
+		if !expired {
+			return leaseRow{}, false, nil
+		}
+		row = tx.QueryRowContext(
+			ctx,
+			`UPDATE submission_manager_leases
+       SET holder_id = $1,
+           lease_epoch = lease_epoch + 1,
+           acquired_at = utc_now(),
+           renewed_at = utc_now(),
+           expires_at = utc_now() + $2::bigint * INTERVAL '1 millisecond'
+       WHERE lease_name = $3
+       RETURNING lease_epoch, expires_at`,
+			holderID,
+			durationMs,
+			leaseName,
+		)
+	case errors.Is(err, sql.ErrNoRows):
+		// Either the row does not exist yet or another instance holds its lock; only an insert can win.
+		row = tx.QueryRowContext(
+			ctx,
+			`INSERT INTO submission_manager_leases (
+        lease_name, holder_id, lease_epoch, acquired_at, renewed_at, expires_at
+      ) VALUES (
+        $1, $2, 1, utc_now(), utc_now(), utc_now() + $3::bigint * INTERVAL '1 millisecond'
+      )
+      ON CONFLICT (lease_name) DO NOTHING
+      RETURNING lease_epoch, expires_at`,
+			leaseName,
+			holderID,
+			durationMs,
+		)
+	default:
+		return leaseRow{}, false, err
+	}
+
+	var epoch int64
+	var expiresAt time.Time
+	if err := row.Scan(&epoch, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return leaseRow{}, false, nil
+		}
+		return leaseRow{}, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return leaseRow{}, false, err
+	}
+	return leaseRow{holderID: holderID, leaseEpoch: epoch, expiresAt: normalizeDBTime(expiresAt)}, true, nil
+}
+
+func (s *postgresStore) renewLease(ctx context.Context, cfg LeaseConfig, epoch int64) (leaseRow, bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	leaseName := strings.TrimSpace(cfg.LeaseName)
+	holderID := strings.TrimSpace(cfg.HolderID)
+	if leaseName == "" || holderID == "" {
+		return leaseRow{}, false, errors.New("lease name and holder id are required")
+	}
+
+	row := s.db.QueryRowContext(
+		ctx,
+		`UPDATE submission_manager_leases
+     SET renewed_at = utc_now(),
+         expires_at = utc_now() + $1::bigint * INTERVAL '1 millisecond'
+     WHERE lease_name = $2
+       AND holder_id = $3
+       AND lease_epoch = $4
+       AND expires_at > utc_now()
+     RETURNING lease_epoch, expires_at`,
+		cfg.LeaseDuration.Milliseconds(),
+		leaseName,
+		holderID,
+		epoch,
+	)
+	var newEpoch int64
+	var expiresAt time.Time
+	if err := row.Scan(&newEpoch, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return leaseRow{}, false, nil
+		}
+		return leaseRow{}, false, err
+	}
+	return leaseRow{holderID: holderID, leaseEpoch: newEpoch, expiresAt: normalizeDBTime(expiresAt)}, true, nil
+}
+
+func (s *postgresStore) readLease(ctx context.Context, leaseName string) (leaseRow, bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	name := strings.TrimSpace(leaseName)
+	if name == "" {
+		return leaseRow{}, false, errors.New("lease name is required")
+	}
+
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT holder_id, lease_epoch, expires_at
+     FROM submission_manager_leases
+     WHERE lease_name = $1`,
+		name,
+	)
+	var holderID string
+	var epoch int64
+	var expiresAt time.Time
+	if err := row.Scan(&holderID, &epoch, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return leaseRow{}, false, nil
+		}
+		return leaseRow{}, false, err
+	}
+	return leaseRow{holderID: holderID, leaseEpoch: epoch, expiresAt: normalizeDBTime(expiresAt)}, true, nil
+}
+
+func (s *postgresStore) releaseLease(ctx context.Context, cfg LeaseConfig, epoch int64) (bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_manager_leases
+     SET renewed_at = utc_now(),
+         expires_at = utc_now()
+     WHERE lease_name = $1
+       AND holder_id = $2
+       AND lease_epoch = $3
+       AND expires_at > utc_now()`,
+		strings.TrimSpace(cfg.LeaseName),
+		strings.TrimSpace(cfg.HolderID),
+		epoch,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *postgresStore) heartbeatMember(ctx context.Context, cfg LeaseConfig) ([]leaseMember, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	leaseName := strings.TrimSpace(cfg.LeaseName)
+	holderID := strings.TrimSpace(cfg.HolderID)
+	if leaseName == "" || holderID == "" {
+		return nil, errors.New("lease name and holder id are required")
+	}
+
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO submission_manager_members (lease_name, holder_id, heartbeat_at, expires_at, partition_count)
+     VALUES ($1, $2, utc_now(), utc_now() + $3::bigint * INTERVAL '1 millisecond', $4)
+     ON CONFLICT (lease_name, holder_id) DO UPDATE
+       SET heartbeat_at = EXCLUDED.heartbeat_at,
+           expires_at = EXCLUDED.expires_at,
+           partition_count = EXCLUDED.partition_count`,
+		leaseName,
+		holderID,
+		cfg.LeaseDuration.Milliseconds(),
+		cfg.partitionCount(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT holder_id, partition_count
+     FROM submission_manager_members
+     WHERE lease_name = $1 AND expires_at > utc_now()
+     ORDER BY holder_id`,
+		leaseName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var members []leaseMember
+	for rows.Next() {
+		var member leaseMember
+		if err := rows.Scan(&member.holderID, &member.partitions); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (s *postgresStore) removeMember(ctx context.Context, cfg LeaseConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM submission_manager_members
+     WHERE lease_name = $1 AND holder_id = $2`,
+		strings.TrimSpace(cfg.LeaseName),
+		strings.TrimSpace(cfg.HolderID),
+	)
+	return err
+}
+
+func (s *postgresStore) countPartitionHolders(ctx context.Context, cfg LeaseConfig) (map[string]int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	leaseName := strings.TrimSpace(cfg.LeaseName)
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT holder_id, COUNT(*)
+     FROM submission_manager_leases
+     WHERE (lease_name = $1 OR lease_name LIKE $2 ESCAPE '\')
+       AND expires_at > utc_now()
+     GROUP BY holder_id`,
+		leaseName,
+		escapeLike(leaseName)+"/%",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var holderID string
+		var count int
+		if err := rows.Scan(&holderID, &count); err != nil {
+			return nil, err
+		}
+		counts[holderID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,267 @@
+package submissionmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// acquireLease computes the expiry from the process clock and returns only the epoch from the
+// statement, so the lease row is never read back through an untyped RETURNING column.
+func (s *sqliteStore) acquireLease(ctx context.Context, cfg LeaseConfig) (leaseRow, bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	leaseName := strings.TrimSpace(cfg.LeaseName)
+	holderID := strings.TrimSpace(cfg.HolderID)
+	if leaseName == "" || holderID == "" {
+		return leaseRow{}, false, errors.New("lease name and holder id are required")
+	}
+	now := s.clock()
+	expiresAt := now.Add(cfg.LeaseDuration)
+
+	row := s.db.QueryRowContext(
+		ctx,
+		`UPDATE submission_manager_leases
+     SET holder_id = ?1,
+         lease_epoch = lease_epoch + 1,
+         acquired_at = ?2,
+         renewed_at = ?2,
+         expires_at = ?3
+     WHERE lease_name = ?4 AND expires_at <= ?2
+     RETURNING lease_epoch`,
+		holderID,
+		sqliteTime(now),
+		sqliteTime(expiresAt),
+		leaseName,
+	)
+	var epoch int64
+	if err := row.Scan(&epoch); err == nil {
+		return leaseRow{holderID: holderID, leaseEpoch: epoch, expiresAt: expiresAt}, true, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return leaseRow{}, false, err
+	}
+
+	row = s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO submission_manager_leases (
+      lease_name, holder_id, lease_epoch, acquired_at, renewed_at, expires_at
+    ) VALUES (
+      ?1, ?2, 1, ?3, ?3, ?4
+    )
+    ON CONFLICT (lease_name) DO NOTHING
+    RETURNING lease_epoch`,
+		leaseName,
+		holderID,
+		sqliteTime(now),
+		sqliteTime(expiresAt),
+	)
+	if err := row.Scan(&epoch); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return leaseRow{}, false, nil
+		}
+		return leaseRow{}, false, err
+	}
+	return leaseRow{holderID: holderID, leaseEpoch: epoch, expiresAt: expiresAt}, true, nil
+}
+
+func (s *sqliteStore) renewLease(ctx context.Context, cfg LeaseConfig, epoch int64) (leaseRow, bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	leaseName := strings.TrimSpace(cfg.LeaseName)
+	holderID := strings.TrimSpace(cfg.HolderID)
+	if leaseName == "" || holderID == "" {
+		return leaseRow{}, false, errors.New("lease name and holder id are required")
+	}
+	now := s.clock()
+	expiresAt := now.Add(cfg.LeaseDuration)
+
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_manager_leases
+     SET renewed_at = ?1,
+         expires_at = ?2
+     WHERE lease_name = ?3
+       AND holder_id = ?4
+       AND lease_epoch = ?5
+       AND expires_at > ?1`,
+		sqliteTime(now),
+		sqliteTime(expiresAt),
+		leaseName,
+		holderID,
+		epoch,
+	)
+	if err != nil {
+		return leaseRow{}, false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return leaseRow{}, false, err
+	}
+	if affected == 0 {
+		return leaseRow{}, false, nil
+	}
+	return leaseRow{holderID: holderID, leaseEpoch: epoch, expiresAt: expiresAt}, true, nil
+}
+
+func (s *sqliteStore) readLease(ctx context.Context, leaseName string) (leaseRow, bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	name := strings.TrimSpace(leaseName)
+	if name == "" {
+		return leaseRow{}, false, errors.New("lease name is required")
+	}
+
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT holder_id, lease_epoch, expires_at
+     FROM submission_manager_leases
+     WHERE lease_name = ?1`,
+		name,
+	)
+	var lease leaseRow
+	if err := row.Scan(&lease.holderID, &lease.leaseEpoch, &lease.expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return leaseRow{}, false, nil
+		}
+		return leaseRow{}, false, err
+	}
+	lease.expiresAt = normalizeDBTime(lease.expiresAt)
+	return lease, true, nil
+}
+
+func (s *sqliteStore) releaseLease(ctx context.Context, cfg LeaseConfig, epoch int64) (bool, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_manager_leases
+     SET renewed_at = ?1,
+         expires_at = ?1
+     WHERE lease_name = ?2
+       AND holder_id = ?3
+       AND lease_epoch = ?4
+       AND expires_at > ?1`,
+		sqliteTime(s.clock()),
+		strings.TrimSpace(cfg.LeaseName),
+		strings.TrimSpace(cfg.HolderID),
+		epoch,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *sqliteStore) heartbeatMember(ctx context.Context, cfg LeaseConfig) ([]leaseMember, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	leaseName := strings.TrimSpace(cfg.LeaseName)
+	holderID := strings.TrimSpace(cfg.HolderID)
+	if leaseName == "" || holderID == "" {
+		return nil, errors.New("lease name and holder id are required")
+	}
+	now := s.clock()
+
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO submission_manager_members (lease_name, holder_id, heartbeat_at, expires_at, partition_count)
+     VALUES (?1, ?2, ?3, ?4, ?5)
+     ON CONFLICT (lease_name, holder_id) DO UPDATE
+       SET heartbeat_at = excluded.heartbeat_at,
+           expires_at = excluded.expires_at,
+           partition_count = excluded.partition_count`,
+		leaseName,
+		holderID,
+		sqliteTime(now),
+		sqliteTime(now.Add(cfg.LeaseDuration)),
+		cfg.partitionCount(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT holder_id, partition_count
+     FROM submission_manager_members
+     WHERE lease_name = ?1 AND expires_at > ?2
+     ORDER BY holder_id`,
+		leaseName,
+		sqliteTime(now),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var members []leaseMember
+	for rows.Next() {
+		var member leaseMember
+		if err := rows.Scan(&member.holderID, &member.partitions); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (s *sqliteStore) removeMember(ctx context.Context, cfg LeaseConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM submission_manager_members
+     WHERE lease_name = ?1 AND holder_id = ?2`,
+		strings.TrimSpace(cfg.LeaseName),
+		strings.TrimSpace(cfg.HolderID),
+	)
+	return err
+}
+
+func (s *sqliteStore) countPartitionHolders(ctx context.Context, cfg LeaseConfig) (map[string]int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	leaseName := strings.TrimSpace(cfg.LeaseName)
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT holder_id, COUNT(*)
+     FROM submission_manager_leases
+     WHERE (lease_name = ?1 OR lease_name LIKE ?2 ESCAPE '\')
+       AND expires_at > ?3
+     GROUP BY holder_id`,
+		leaseName,
+		escapeLike(leaseName)+"/%",
+		sqliteTime(s.clock()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var holderID string
+		var count int
+		if err := rows.Scan(&holderID, &count); err != nil {
+			return nil, err
+		}
+		counts[holderID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -12,10 +12,9 @@ import (
 	"time"
 
 	"gateway/submission"
+	"gateway/tracing"
 )
 
-const waitPollInterval = 250 * time.Millisecond
-
 // IntentStatus represents the lifecycle state of a SubmissionIntent.
 type IntentStatus string
 
@@ -32,8 +31,11 @@ const (
 
 // Intent is the SubmissionManager record for a client submission.
 type Intent struct {
-	IntentID           string
-	SubmissionTarget   string
+	IntentID         string
+	SubmissionTarget string
+	// TargetAlias is the registry alias the intent was submitted to; SubmissionTarget is the
+	// concrete target it resolved to. Empty when submitted to a concrete target.
+	TargetAlias        string
 	Payload            json.RawMessage
 	CreatedAt          time.Time
 	CompletedAt        time.Time
@@ -46,6 +48,33 @@ type Intent struct {
 	WebhookAttemptedAt time.Time
 	WebhookDeliveredAt time.Time
 	WebhookError       string
+	// Manual redeliveries are recorded separately so the original delivery result is preserved.
+	WebhookRedeliveryCount       int
+	WebhookRedeliveryStatus      string
+	WebhookRedeliveryAttemptedAt time.Time
+	WebhookRedeliveryError       string
+	// CallbackURL and CallbackSecretEnv override the target's default webhook for this intent.
+	CallbackURL       string
+	CallbackSecretEnv string
+	// PriorSendUnresolved is set after a timed-out attempt whose send may still be in flight at the gateway.
+	PriorSendUnresolved bool
+	// ChargedAttempts counts attempts that consumed the policy's attempt budget.
+	ChargedAttempts int
+	// DeferredFor is the total time attempts were deferred to a delivery window opening.
+	DeferredFor time.Duration
+	// TraceParent and TraceState carry the submit span's W3C trace context to later attempts.
+	TraceParent string
+	TraceState  string
+	// ScheduledAt is when a pending intent was last scheduled (creation or the previous attempt).
+	ScheduledAt time.Time
+}
+
+// RequestedTarget returns the alias the intent was submitted to, or its submissionTarget.
+func (i Intent) RequestedTarget() string {
+	if i.TargetAlias != "" {
+		return i.TargetAlias
+	}
+	return i.SubmissionTarget
 }
 
 // Attempt captures a single gateway submission attempt.
@@ -55,6 +84,11 @@ type Attempt struct {
 	FinishedAt     time.Time
 	GatewayOutcome GatewayOutcome
 	Error          string
+	// ErrorClass is set whenever Error is set.
+	ErrorClass submission.AttemptErrorClass
+	// Backpressure is set when the gateway answered 429 or 503; RetryAfter is its requested delay.
+	Backpressure bool
+	RetryAfter   time.Duration
 }
 
 // GatewayOutcome is the normalized gateway response outcome.
@@ -67,7 +101,50 @@ type GatewayOutcome struct {
 type AttemptInput struct {
 	GatewayType submission.GatewayType
 	GatewayURL  string
-	Payload     json.RawMessage
+	// GatewayURLs lists every endpoint of a multi-endpoint target; the executor orders them by EndpointStrategy.
+	// Empty means GatewayURL is the only endpoint.
+	GatewayURLs      []string
+	EndpointStrategy submission.EndpointStrategy
+	Payload          json.RawMessage
+	// Timeout bounds the attempt; zero means no attempt-level timeout.
+	Timeout time.Duration
+}
+
+// AttemptTimeoutError reports an attempt that did not complete within the target's attempt timeout.
+type AttemptTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e AttemptTimeoutError) Error() string {
+	return fmt.Sprintf("attempt_timeout: gateway did not respond within %s", e.Timeout)
+}
+
+// GatewayBackpressureError reports a gateway that asked callers to slow down (429 or 503).
+// RetryAfter is zero when the gateway sent no usable Retry-After header.
+type GatewayBackpressureError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e GatewayBackpressureError) Error() string {
+	if e.RetryAfter <= 0 {
+		return fmt.Sprintf("gateway returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("gateway returned status %d (retry after %s)", e.StatusCode, e.RetryAfter)
+}
+
+// AttemptError is an executor error with its failure class.
+type AttemptError struct {
+	Class submission.AttemptErrorClass
+	Err   error
+}
+
+func (e AttemptError) Error() string {
+	return e.Err.Error()
+}
+
+func (e AttemptError) Unwrap() error {
+	return e.Err
 }
 
 // AttemptExecutor performs a single gateway submission attempt.
@@ -93,21 +170,26 @@ type Clock struct {
 
 // Manager orchestrates SubmissionIntents, attempts, and policy evaluation.
 type Manager struct {
-	reg           submission.Registry
-	exec          AttemptExecutor
-	store         *sqlStore
-	clock         Clock
-	mu            sync.Mutex
-	queue         attemptQueue
-	wake          chan struct{}
-	nextSeq       int
-	scheduled     map[string]time.Time
-	leader        bool
-	leaseFence    LeaseFence
-	leaseLossFn   func()
-	scheduleNow   func(context.Context) (time.Time, error)
-	metrics       *Metrics
-	webhookSender WebhookSender
+	reg       submission.Registry
+	exec      AttemptExecutor
+	store     Store
+	clock     Clock
+	mu        sync.Mutex
+	queue     attemptQueue
+	wake      chan struct{}
+	nextSeq   int
+	scheduled map[string]scheduledAttempt
+	// partitionCount and held track which intents this instance may execute; see leader_state.go.
+	partitionCount int
+	held           map[int]heldPartition
+	scheduleNow    func(context.Context) (time.Time, error)
+	metrics        *Metrics
+	tracer         *tracing.Tracer
+	webhookSender  WebhookSender
+	waiters        intentWaiters
+	claimStrategy  ClaimStrategy
+	drain          DrainState
+	admission      admissionState
 }
 
 // IdempotencyConflictError reports a conflicting submission for the same intentId.
@@ -133,13 +215,36 @@ func (e UnknownSubmissionTargetError) Error() string {
 	return fmt.Sprintf("unknown submissionTarget %q", e.SubmissionTarget)
 }
 
-// NewManager constructs a SubmissionManager with the provided registry, executor, and SQL store.
+// CallbackNotAllowedError reports a per-intent callback that the target's webhook allowlist rejects.
+type CallbackNotAllowedError struct {
+	SubmissionTarget string
+	CallbackURL      string
+	Reason           string
+}
+
+func (e CallbackNotAllowedError) Error() string {
+	return fmt.Sprintf("callback %q is not allowed for submissionTarget %q: %s", e.CallbackURL, e.SubmissionTarget, e.Reason)
+}
+
+// NewManager constructs a SubmissionManager with the provided registry, executor, and SQL Server store.
 func NewManager(reg submission.Registry, exec AttemptExecutor, clock Clock, db *sql.DB) (*Manager, error) {
 	if exec == nil {
 		return nil, errors.New("executor is required")
 	}
-	if db == nil {
-		return nil, errors.New("db is required")
+	store, err := newSQLStore(db)
+	if err != nil {
+		return nil, err
+	}
+	return NewManagerWithStore(reg, exec, clock, store)
+}
+
+// NewManagerWithStore constructs a SubmissionManager on any Store implementation.
+func NewManagerWithStore(reg submission.Registry, exec AttemptExecutor, clock Clock, store Store) (*Manager, error) {
+	if exec == nil {
+		return nil, errors.New("executor is required")
+	}
+	if store == nil {
+		return nil, errors.New("store is required")
 	}
 	if clock.Now == nil {
 		clock.Now = time.Now
@@ -148,19 +253,17 @@ func NewManager(reg submission.Registry, exec AttemptExecutor, clock Clock, db *
 		clock.After = time.After
 	}
 
-	store, err := newSQLStore(db)
-	if err != nil {
-		return nil, err
-	}
-
 	manager := &Manager{
-		reg:         reg,
-		exec:        exec,
-		store:       store,
-		clock:       clock,
-		wake:        make(chan struct{}, 1),
-		scheduled:   make(map[string]time.Time),
-		scheduleNow: store.loadSQLTime,
+		reg:            reg,
+		exec:           exec,
+		store:          store,
+		clock:          clock,
+		wake:           make(chan struct{}, 1),
+		scheduled:      make(map[string]scheduledAttempt),
+		partitionCount: 1,
+		held:           make(map[int]heldPartition),
+		scheduleNow:    store.loadSQLTime,
+		claimStrategy:  ClaimRetry,
 	}
 	heap.Init(&manager.queue)
 	return manager, nil
@@ -180,6 +283,16 @@ func (m *Manager) SetMetrics(metrics *Metrics) {
 	}
 }
 
+// SetTracer assigns the tracer used for schedule-wait and attempt spans.
+func (m *Manager) SetTracer(tracer *tracing.Tracer) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.tracer = tracer
+	m.mu.Unlock()
+}
+
 // SetWebhookSender assigns the webhook sender used for terminal callbacks.
 func (m *Manager) SetWebhookSender(sender WebhookSender) {
 	if m == nil {
@@ -211,6 +324,11 @@ func (m *Manager) SubmitIntent(ctx context.Context, intent Intent) (Intent, erro
 
 	payload := normalizePayload(intent.Payload)
 
+	targetAlias := ""
+	if alias, ok := m.reg.AliasFor(submissionTarget); ok {
+		targetAlias = alias.Alias
+		submissionTarget = alias.Resolve(intentID)
+	}
 	contract, ok := m.reg.ContractFor(submissionTarget)
 	if !ok {
 		return Intent{}, UnknownSubmissionTargetError{SubmissionTarget: submissionTarget}
@@ -218,14 +336,30 @@ func (m *Manager) SubmitIntent(ctx context.Context, intent Intent) (Intent, erro
 	// Freeze a contract snapshot so registry changes never affect existing intents.
 	contract = cloneContract(contract)
 
+	callbackURL := strings.TrimSpace(intent.CallbackURL)
+	callbackSecretEnv := strings.TrimSpace(intent.CallbackSecretEnv)
+	if err := m.checkCallback(ctx, intentID, contract, callbackURL, callbackSecretEnv); err != nil {
+		return Intent{}, err
+	}
+
+	if err := m.checkDrain(ctx, intentID); err != nil {
+		return Intent{}, err
+	}
+	if err := m.checkAdmission(ctx, intentID, contract); err != nil {
+		return Intent{}, err
+	}
+
 	createdAt := m.clock.Now()
 	newIntent := Intent{
-		IntentID:         intentID,
-		SubmissionTarget: submissionTarget,
-		Payload:          payload,
-		CreatedAt:        createdAt,
-		Status:           IntentPending,
-		Contract:         contract,
+		IntentID:          intentID,
+		SubmissionTarget:  submissionTarget,
+		TargetAlias:       targetAlias,
+		Payload:           payload,
+		CreatedAt:         createdAt,
+		Status:            IntentPending,
+		Contract:          contract,
+		CallbackURL:       callbackURL,
+		CallbackSecretEnv: callbackSecretEnv,
 	}
 
 	stored, inserted, err := m.store.insertIntent(ctx, newIntent, payloadHash(payload), createdAt)
@@ -241,10 +375,10 @@ func (m *Manager) SubmitIntent(ctx context.Context, intent Intent) (Intent, erro
 	if inserted {
 		if m.metrics != nil {
 			m.metrics.ObserveIntentCreated()
+			m.metrics.ObserveAliasRouted(targetAlias, submissionTarget)
 		}
-		if m.isLeader() {
-			m.enqueueAttempt(intentID, createdAt)
-		}
+		m.noteAdmitted(submissionTarget)
+		m.enqueueAttempt(intentID, m.partitionOf(intentID), createdAt)
 	} else if m.metrics != nil {
 		m.metrics.ObserveIdempotentHit()
 	}
@@ -263,57 +397,3 @@ func (m *Manager) GetIntent(intentID string) (Intent, bool) {
 	}
 	return intent, true
 }
-
-// WaitForIntent polls SQL until the intent reaches a terminal state, completes its first attempt,
-// or the wait duration elapses.
-func (m *Manager) WaitForIntent(ctx context.Context, intentID string, wait time.Duration) (Intent, bool, error) {
-	// Flow intent: poll SQL until terminal, first attempt done, or timeout.
-	trimmed := strings.TrimSpace(intentID)
-	if trimmed == "" {
-		return Intent{}, false, nil
-	}
-	if wait <= 0 {
-		intent, ok := m.GetIntent(trimmed)
-		return intent, ok, nil
-	}
-	if ctx == nil {
-		ctx = context.Background()
-	}
-
-	deadline := m.clock.Now().Add(wait)
-	var last Intent
-	var ok bool
-
-	for {
-		// Non-obvious constraint: read SQL because the executor may be another process.
-		intent, attemptCount, found, err := m.store.loadIntentRow(context.Background(), trimmed)
-		if err != nil || !found {
-			return Intent{}, found, err
-		}
-		last = intent
-		ok = true
-		if intent.Status == IntentAccepted || intent.Status == IntentRejected || intent.Status == IntentExhausted {
-			return intent, true, nil
-		}
-		// Non-obvious constraint: wait stops after the first attempt, even if still pending.
-		if attemptCount >= 1 {
-			return intent, true, nil
-		}
-
-		now := m.clock.Now()
-		if !now.Before(deadline) {
-			return last, ok, nil
-		}
-		remaining := deadline.Sub(now)
-		waitFor := waitPollInterval
-		if remaining < waitFor {
-			waitFor = remaining
-		}
-
-		select {
-		case <-ctx.Done():
-			return last, ok, nil
-		case <-m.clock.After(waitFor):
-		}
-	}
-}
@@ -2,7 +2,6 @@ package submissionmanager
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"runtime"
@@ -206,13 +205,16 @@ func baseContract(policy submission.ContractPolicy) submission.TargetContract {
 		contract.MaxAcceptanceSeconds = 10
 	case submission.PolicyMaxAttempts:
 		contract.MaxAttempts = 2
+	case submission.PolicyDeadlineOrMaxAttempts:
+		contract.MaxAcceptanceSeconds = 10
+		contract.MaxAttempts = 2
 	}
 	return contract
 }
 
-func newManager(t *testing.T, reg submission.Registry, exec AttemptExecutor, clock *fakeClock, db *sql.DB) *Manager {
+func newManager(t *testing.T, reg submission.Registry, exec AttemptExecutor, clock *fakeClock, db *testDB) *Manager {
 	t.Helper()
-	manager, err := NewManager(reg, exec, Clock{Now: clock.Now, After: clock.After}, db)
+	manager, err := NewManagerWithStore(reg, exec, Clock{Now: clock.Now, After: clock.After}, db.store(t))
 	if err != nil {
 		t.Fatalf("new manager: %v", err)
 	}
@@ -250,28 +252,14 @@ func activateLeader(t *testing.T, manager *Manager) LeaseFence {
 		HolderID:   cfg.HolderID,
 		LeaseEpoch: lease.leaseEpoch,
 	}
-	manager.setLeader(fence, func() {})
+	manager.holdPartition(fence, func() {})
 	return fence
 }
 
-func expireLease(t *testing.T, manager *Manager) {
-	t.Helper()
-	_, err := manager.store.db.ExecContext(
-		context.Background(),
-		`UPDATE dbo.submission_manager_leases
-     SET expires_at = DATEADD(SECOND, -1, SYSUTCDATETIME())
-     WHERE lease_name = @p1`,
-		"submission-manager-executor",
-	)
-	if err != nil {
-		t.Fatalf("expire lease: %v", err)
-	}
-}
-
 func startManager(t *testing.T, manager *Manager) (context.Context, context.CancelFunc, chan struct{}) {
 	t.Helper()
 	activateLeader(t, manager)
-	if _, err := manager.rebuildSchedule(context.Background()); err != nil {
+	if _, err := manager.rebuildSchedule(context.Background(), 0); err != nil {
 		t.Fatalf("rebuild schedule: %v", err)
 	}
 	ctx, cancel := context.WithCancel(context.Background())
@@ -326,6 +314,62 @@ func TestSubmitIntentIdempotency(t *testing.T) {
 	}
 }
 
+func TestSubmitIntentResolvesAlias(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	current := baseContract(submission.PolicyOneShot)
+	next := baseContract(submission.PolicyOneShot)
+	next.SubmissionTarget = "sms.realtime.v2"
+	targets := map[string]submission.TargetContract{current.SubmissionTarget: current, next.SubmissionTarget: next}
+	aliasTo := func(target string) submission.Registry {
+		return submission.Registry{
+			Targets: targets,
+			Aliases: map[string]submission.TargetAlias{"otp-sms": {Alias: "otp-sms", Routes: []submission.WeightedTarget{
+				{SubmissionTarget: target, Weight: 1},
+			}}},
+		}
+	}
+	stub := newStubExecutor(clock, nil)
+	manager := newManager(t, aliasTo(next.SubmissionTarget), stub.Exec, clock, db)
+	metrics := NewMetrics()
+	manager.SetMetrics(metrics)
+
+	intent := Intent{IntentID: "intent-1", SubmissionTarget: "otp-sms", Payload: []byte(`{"a":1}`)}
+	stored, err := manager.SubmitIntent(context.Background(), intent)
+	if err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+	if stored.SubmissionTarget != next.SubmissionTarget || stored.TargetAlias != "otp-sms" {
+		t.Fatalf("expected otp-sms resolved to %s, got %q via %q", next.SubmissionTarget, stored.SubmissionTarget, stored.TargetAlias)
+	}
+	loaded, ok := manager.GetIntent("intent-1")
+	if !ok || loaded.TargetAlias != "otp-sms" || loaded.Contract.SubmissionTarget != next.SubmissionTarget {
+		t.Fatalf("expected persisted alias and concrete contract, got %+v", loaded)
+	}
+	var buf strings.Builder
+	metrics.WritePrometheus(&buf)
+	if want := `submission_alias_routed_total{alias="otp-sms",submission_target="sms.realtime.v2"} 1`; !strings.Contains(buf.String(), want) {
+		t.Fatalf("expected metrics to contain %q", want)
+	}
+
+	// A replay after the weights moved still returns the existing intent.
+	replaying := newManager(t, aliasTo(current.SubmissionTarget), stub.Exec, clock, db)
+	replayed, err := replaying.SubmitIntent(context.Background(), intent)
+	if err != nil {
+		t.Fatalf("replay intent: %v", err)
+	}
+	if replayed.SubmissionTarget != next.SubmissionTarget {
+		t.Fatalf("expected replay to keep %s, got %s", next.SubmissionTarget, replayed.SubmissionTarget)
+	}
+
+	// The concrete target is a different request than the alias.
+	_, err = replaying.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: next.SubmissionTarget, Payload: intent.Payload})
+	var conflict IdempotencyConflictError
+	if !errors.As(err, &conflict) || conflict.ExistingTarget != "otp-sms" {
+		t.Fatalf("expected idempotency conflict against otp-sms, got %v", err)
+	}
+}
+
 func TestTerminalIntentDispatchesWebhook(t *testing.T) {
 	clock := newFakeClock(time.Unix(0, 0))
 	db := newTestDB(t)
@@ -360,6 +404,50 @@ func TestTerminalIntentDispatchesWebhook(t *testing.T) {
 	}
 }
 
+func TestRedeliverWebhookRecordsRedelivery(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := contractWithWebhook(baseContract(submission.PolicyOneShot))
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, []execResult{{outcome: GatewayOutcome{Status: gatewayAccepted}}})
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	webhook := newStubWebhookSender(nil)
+	manager.SetWebhookSender(webhook.Send)
+
+	intent := Intent{
+		IntentID:         "intent-1",
+		SubmissionTarget: contract.SubmissionTarget,
+		Payload:          []byte(`{"a":1}`),
+	}
+	if _, err := manager.SubmitIntent(context.Background(), intent); err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+	if _, _, err := manager.RedeliverWebhook(context.Background(), intent.IntentID); err == nil {
+		t.Fatalf("expected redelivery of pending intent to fail")
+	}
+
+	_, cancel, done := startManager(t, manager)
+	waitForStatus(t, manager, intent.IntentID, IntentAccepted)
+	cancel()
+	<-done
+	original := waitForWebhook(t, webhook.calls)
+
+	redelivered, ok, err := manager.RedeliverWebhook(context.Background(), intent.IntentID)
+	if err != nil || !ok {
+		t.Fatalf("redeliver webhook: ok=%t err=%v", ok, err)
+	}
+	again := waitForWebhook(t, webhook.calls)
+	if string(again.Body) != string(original.Body) {
+		t.Fatalf("expected identical webhook body, got %q and %q", original.Body, again.Body)
+	}
+	if redelivered.WebhookRedeliveryCount != 1 || redelivered.WebhookRedeliveryStatus != webhookDelivered {
+		t.Fatalf("unexpected redelivery state: %+v", redelivered)
+	}
+	if redelivered.WebhookStatus != webhookDelivered {
+		t.Fatalf("expected original webhook status preserved, got %q", redelivered.WebhookStatus)
+	}
+}
+
 func TestTerminalIntentWithoutWebhookDoesNotDispatch(t *testing.T) {
 	clock := newFakeClock(time.Unix(0, 0))
 	db := newTestDB(t)
@@ -517,7 +605,7 @@ func TestRestartRebuildsSchedule(t *testing.T) {
 	waitForAttempts(t, manager, "intent-1", 1)
 	cancel()
 	<-done
-	expireLease(t, manager)
+	db.expireLease(t, "submission-manager-executor")
 
 	manager = newManager(t, reg, stub.Exec, clock, db)
 	_, cancel, done = startManager(t, manager)
@@ -660,6 +748,132 @@ func TestMaxAttemptsExhausted(t *testing.T) {
 	}
 }
 
+func TestDeadlineOrMaxAttemptsExhaustsAtFirstLimit(t *testing.T) {
+	created := time.Unix(0, 0)
+	cases := []struct {
+		name        string
+		maxAttempts int
+		charged     int
+		finishedAt  time.Duration
+		exempt      bool
+		wantRetry   bool
+		wantReason  string
+	}{
+		{name: "both limits open", maxAttempts: 2, charged: 1, finishedAt: time.Second, wantRetry: true},
+		{name: "attempts first", maxAttempts: 2, charged: 2, finishedAt: 7 * time.Second, wantReason: "max_attempts"},
+		{name: "deadline before next due", maxAttempts: 5, charged: 2, finishedAt: 6 * time.Second, wantReason: "deadline_exceeded"},
+		{name: "deadline passed during last attempt", maxAttempts: 2, charged: 2, finishedAt: 10 * time.Second, wantReason: "deadline_exceeded"},
+		{name: "exempt error is bounded by the deadline only", maxAttempts: 2, charged: 2, finishedAt: time.Second, exempt: true, wantRetry: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			contract := baseContract(submission.PolicyDeadlineOrMaxAttempts)
+			contract.MaxAttempts = tc.maxAttempts
+			contract.BudgetExemptErrorClasses = []submission.AttemptErrorClass{submission.ErrorClassTransport}
+			intent := &Intent{CreatedAt: created, Status: IntentPending, Contract: contract, ChargedAttempts: tc.charged}
+			attempt := &Attempt{FinishedAt: created.Add(tc.finishedAt), Error: "send failed", ErrorClass: submission.ErrorClassGateway5xx}
+			if tc.exempt {
+				attempt.ErrorClass = submission.ErrorClassTransport
+			}
+			retry, next := (&Manager{}).applyPolicy(intent, attempt)
+			if retry != tc.wantRetry || intent.ExhaustedReason != tc.wantReason {
+				t.Fatalf("expected retry=%v reason=%q, got retry=%v reason=%q", tc.wantRetry, tc.wantReason, retry, intent.ExhaustedReason)
+			}
+			if retry && !next.Equal(attempt.FinishedAt.Add(retryDelay)) {
+				t.Fatalf("expected next attempt after the retry delay, got %s", next)
+			}
+		})
+	}
+}
+
+func contractWithWindow(contract submission.TargetContract, excludeDeferral bool) submission.TargetContract {
+	// time.Unix(0, 0) is 00:00 UTC, so the first attempt is due an hour before the window opens.
+	contract.DeliveryWindow = &submission.DeliveryWindow{
+		TimeZone:                    "UTC",
+		Spans:                       []submission.WindowSpan{{StartMinute: 60, EndMinute: 120}},
+		ExcludeDeferralFromDeadline: excludeDeferral,
+	}
+	return contract
+}
+
+func TestDeliveryWindowDefersAttempt(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := contractWithWindow(baseContract(submission.PolicyDeadline), true)
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, []execResult{{outcome: GatewayOutcome{Status: "accepted"}}})
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	_, cancel, done := startManager(t, manager)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	_, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget})
+	if err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		intent, _ := manager.GetIntent("intent-1")
+		if intent.DeferredFor == time.Hour {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			t.Fatalf("expected intent deferred for 1h, got %s", intent.DeferredFor)
+		}
+		runtime.Gosched()
+	}
+	assertNoCall(t, stub.calls)
+
+	// The deferral is excluded from the 10s deadline, so the attempt at the opening still counts.
+	clock.Advance(time.Hour)
+	waitForCall(t, stub.calls)
+	waitForStatus(t, manager, "intent-1", IntentAccepted)
+}
+
+func TestDeliveryWindowDeferralPastDeadlineExhausts(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := contractWithWindow(baseContract(submission.PolicyDeadline), false)
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, nil)
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	_, cancel, done := startManager(t, manager)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	_, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget})
+	if err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+	intent := waitForStatus(t, manager, "intent-1", IntentExhausted)
+	if intent.ExhaustedReason != "deadline_exceeded" {
+		t.Fatalf("expected deadline_exceeded, got %q", intent.ExhaustedReason)
+	}
+	assertNoCall(t, stub.calls)
+}
+
+func TestAcceptanceDeadlineExcludesDeferral(t *testing.T) {
+	created := time.Unix(0, 0)
+	for _, exclude := range []bool{false, true} {
+		intent := Intent{
+			CreatedAt:   created,
+			Contract:    contractWithWindow(baseContract(submission.PolicyDeadline), exclude),
+			DeferredFor: time.Hour,
+		}
+		want := created.Add(10 * time.Second)
+		if exclude {
+			want = want.Add(time.Hour)
+		}
+		if got, ok := acceptanceDeadline(intent); !ok || !got.Equal(want) {
+			t.Fatalf("exclude=%v: acceptanceDeadline = %s, %v; want %s", exclude, got, ok, want)
+		}
+	}
+}
+
 func TestOneShotExhausted(t *testing.T) {
 	clock := newFakeClock(time.Unix(0, 0))
 	db := newTestDB(t)
@@ -720,6 +934,286 @@ func TestDuplicateReferenceNonTerminal(t *testing.T) {
 	}
 }
 
+func TestDuplicateReferenceAfterTimeoutIsNotTerminal(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := baseContract(submission.PolicyMaxAttempts)
+	contract.MaxAttempts = 3
+	contract.AttemptTimeoutSeconds = 2
+	contract.TerminalOutcomes = []string{"invalid_request", "duplicate_reference"}
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, []execResult{
+		{err: AttemptTimeoutError{Timeout: 2 * time.Second}},
+		{outcome: GatewayOutcome{Status: "rejected", Reason: "duplicate_reference"}},
+		{outcome: GatewayOutcome{Status: "accepted"}},
+	})
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	_, cancel, done := startManager(t, manager)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	_, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget})
+	if err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+	waitForCall(t, stub.calls)
+	waitForAttempts(t, manager, "intent-1", 1)
+	clock.Advance(5 * time.Second)
+	waitForCall(t, stub.calls)
+	waitForAttempts(t, manager, "intent-1", 2)
+	clock.Advance(5 * time.Second)
+	waitForCall(t, stub.calls)
+	intent := waitForStatus(t, manager, "intent-1", IntentAccepted)
+	if !strings.Contains(intent.Attempts[0].Error, "attempt_timeout") {
+		t.Fatalf("expected attempt_timeout error, got %q", intent.Attempts[0].Error)
+	}
+	if !strings.Contains(intent.Attempts[1].Error, "still in flight") {
+		t.Fatalf("expected in-flight duplicate error, got %q", intent.Attempts[1].Error)
+	}
+}
+
+func TestTimedOutSendIsLeftForReview(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := baseContract(submission.PolicyMaxAttempts)
+	contract.MaxAttempts = 3
+	contract.AttemptTimeoutSeconds = 2
+	contract.UnresolvedSend = submission.UnresolvedSendReview
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, []execResult{
+		{err: AttemptTimeoutError{Timeout: 2 * time.Second}},
+		{outcome: GatewayOutcome{Status: "accepted"}},
+	})
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	_, cancel, done := startManager(t, manager)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	_, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget})
+	if err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+	waitForCall(t, stub.calls)
+	intent := waitForStatus(t, manager, "intent-1", IntentExhausted)
+	if intent.ExhaustedReason != exhaustedAttemptUnresolved {
+		t.Fatalf("expected exhausted reason %q, got %q", exhaustedAttemptUnresolved, intent.ExhaustedReason)
+	}
+	if !intent.PriorSendUnresolved {
+		t.Fatalf("expected the timed-out send to stay unresolved")
+	}
+
+	clock.Advance(5 * time.Second)
+	select {
+	case <-stub.calls:
+		t.Fatalf("unexpected resend of a timed-out send under review")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBudgetExemptErrorClassDoesNotConsumeAttempt(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := baseContract(submission.PolicyMaxAttempts)
+	contract.MaxAttempts = 1
+	contract.BudgetExemptErrorClasses = []submission.AttemptErrorClass{submission.ErrorClassGateway5xx}
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, []execResult{
+		{err: AttemptError{Class: submission.ErrorClassGateway5xx, Err: errors.New("gateway returned status 503")}},
+		{outcome: GatewayOutcome{Status: "accepted"}},
+	})
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	_, cancel, done := startManager(t, manager)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	_, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget})
+	if err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+	waitForCall(t, stub.calls)
+	waitForAttempts(t, manager, "intent-1", 1)
+	clock.Advance(5 * time.Second)
+	waitForCall(t, stub.calls)
+	intent := waitForStatus(t, manager, "intent-1", IntentAccepted)
+	if intent.Attempts[0].ErrorClass != submission.ErrorClassGateway5xx {
+		t.Fatalf("expected gateway_5xx class, got %q", intent.Attempts[0].ErrorClass)
+	}
+	if intent.ChargedAttempts != 1 {
+		t.Fatalf("expected 1 charged attempt, got %d", intent.ChargedAttempts)
+	}
+}
+
+func TestBudgetExemptAttemptsExhaustAtCap(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := baseContract(submission.PolicyOneShot)
+	contract.BudgetExemptErrorClasses = []submission.AttemptErrorClass{submission.ErrorClassGateway5xx}
+	contract.MaxExemptAttempts = 3
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	var results []execResult
+	for i := 0; i < 4; i++ {
+		results = append(results, execResult{err: AttemptError{Class: submission.ErrorClassGateway5xx, Err: errors.New("gateway returned status 503")}})
+	}
+	stub := newStubExecutor(clock, results)
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	_, cancel, done := startManager(t, manager)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	_, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget})
+	if err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+	for attempt := 1; attempt < 3; attempt++ {
+		waitForCall(t, stub.calls)
+		waitForAttempts(t, manager, "intent-1", attempt)
+		clock.Advance(5 * time.Second)
+	}
+	waitForCall(t, stub.calls)
+	intent := waitForStatus(t, manager, "intent-1", IntentExhausted)
+	if intent.ExhaustedReason != exhaustedExemptAttempts {
+		t.Fatalf("expected exhausted reason %q, got %q", exhaustedExemptAttempts, intent.ExhaustedReason)
+	}
+	if len(intent.Attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(intent.Attempts))
+	}
+	if intent.ChargedAttempts != 0 {
+		t.Fatalf("expected no charged attempts, got %d", intent.ChargedAttempts)
+	}
+
+	clock.Advance(5 * time.Second)
+	select {
+	case <-stub.calls:
+		t.Fatalf("unexpected attempt after the exempt cap")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestExemptAttemptLimitDefaultsForOldSnapshots(t *testing.T) {
+	contract := baseContract(submission.PolicyMaxAttempts)
+	if got := exemptAttemptLimit(contract); got != submission.DefaultMaxExemptAttempts {
+		t.Fatalf("expected default limit %d, got %d", submission.DefaultMaxExemptAttempts, got)
+	}
+	contract.MaxExemptAttempts = 2
+	if got := exemptAttemptLimit(contract); got != 2 {
+		t.Fatalf("expected limit 2, got %d", got)
+	}
+}
+
+func TestBackpressureDelaysRetryWithoutCharging(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := baseContract(submission.PolicyMaxAttempts)
+	contract.MaxAttempts = 1
+	contract.BudgetExemptBackpressure = true
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, []execResult{
+		{err: AttemptError{Class: submission.ErrorClassGateway4xx, Err: GatewayBackpressureError{StatusCode: 429, RetryAfter: 30 * time.Second}}},
+		{outcome: GatewayOutcome{Status: "accepted"}},
+	})
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	_, cancel, done := startManager(t, manager)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	_, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget})
+	if err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+	waitForCall(t, stub.calls)
+	waitForAttempts(t, manager, "intent-1", 1)
+
+	clock.Advance(29 * time.Second)
+	select {
+	case <-stub.calls:
+		t.Fatalf("unexpected retry before Retry-After elapsed")
+	default:
+	}
+
+	clock.Advance(1 * time.Second)
+	waitForCall(t, stub.calls)
+	intent := waitForStatus(t, manager, "intent-1", IntentAccepted)
+	if intent.ChargedAttempts != 1 {
+		t.Fatalf("expected 1 charged attempt, got %d", intent.ChargedAttempts)
+	}
+}
+
+func TestRetryRulesInEvaluateAttempt(t *testing.T) {
+	created := time.Unix(0, 0)
+	finished := created.Add(time.Second)
+	contract := baseContract(submission.PolicyMaxAttempts)
+	contract.MaxAttempts = 5
+	contract.OutcomeRules = map[string]submission.RetryRule{
+		"provider_failure":    {Action: submission.RuleRetry, RetryAfterSeconds: 30},
+		"duplicate_reference": {Action: submission.RuleAccept},
+		"invalid_recipient":   {Action: submission.RuleTerminal},
+	}
+	contract.ErrorClassRules = map[submission.AttemptErrorClass]submission.RetryRule{
+		submission.ErrorClassDecode:     {Action: submission.RuleTerminal},
+		submission.ErrorClassGateway5xx: {Action: submission.RuleRetry, RetryAfterSeconds: 20},
+	}
+	cases := []struct {
+		name          string
+		attempt       Attempt
+		priorTimedOut bool
+		wantStatus    IntentStatus
+		wantReason    string
+		wantNext      time.Duration
+	}{
+		{name: "retry rule delay", attempt: Attempt{GatewayOutcome: GatewayOutcome{Status: "rejected", Reason: "provider_failure"}}, wantStatus: IntentPending, wantNext: 30 * time.Second},
+		{name: "accept rule", attempt: Attempt{GatewayOutcome: GatewayOutcome{Status: "rejected", Reason: "duplicate_reference"}}, wantStatus: IntentAccepted},
+		{name: "accept rule wins over in-flight guard", attempt: Attempt{GatewayOutcome: GatewayOutcome{Status: "rejected", Reason: "duplicate_reference"}}, priorTimedOut: true, wantStatus: IntentAccepted},
+		{name: "terminal rule", attempt: Attempt{GatewayOutcome: GatewayOutcome{Status: "rejected", Reason: "invalid_recipient"}}, wantStatus: IntentRejected},
+		{name: "listed terminal outcome", attempt: Attempt{GatewayOutcome: GatewayOutcome{Status: "rejected", Reason: "invalid_request"}}, wantStatus: IntentRejected},
+		{name: "reason without rule", attempt: Attempt{GatewayOutcome: GatewayOutcome{Status: "rejected", Reason: "invalid_message"}}, wantStatus: IntentPending, wantNext: retryDelay},
+		{name: "terminal error class", attempt: Attempt{Error: "bad json", ErrorClass: submission.ErrorClassDecode}, wantStatus: IntentExhausted, wantReason: exhaustedTerminalError},
+		{name: "error class retry delay", attempt: Attempt{Error: "status 502", ErrorClass: submission.ErrorClassGateway5xx}, wantStatus: IntentPending, wantNext: 20 * time.Second},
+		{name: "longer backpressure wins", attempt: Attempt{Error: "status 503", ErrorClass: submission.ErrorClassGateway5xx, Backpressure: true, RetryAfter: time.Minute}, wantStatus: IntentPending, wantNext: time.Minute},
+		{name: "error class without rule", attempt: Attempt{Error: "dial failed", ErrorClass: submission.ErrorClassTransport}, wantStatus: IntentPending, wantNext: retryDelay},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			intent := &Intent{CreatedAt: created, Status: IntentPending, Contract: cloneContract(contract), PriorSendUnresolved: tc.priorTimedOut}
+			attempt := tc.attempt
+			attempt.FinishedAt = finished
+			retry, next := (&Manager{}).evaluateAttempt(intent, &attempt)
+			if intent.Status != tc.wantStatus || intent.ExhaustedReason != tc.wantReason {
+				t.Fatalf("expected %s/%q, got %s/%q", tc.wantStatus, tc.wantReason, intent.Status, intent.ExhaustedReason)
+			}
+			if retry && !next.Equal(finished.Add(tc.wantNext)) {
+				t.Fatalf("expected next attempt after %s, got %s", tc.wantNext, next.Sub(finished))
+			}
+		})
+	}
+}
+
+func TestRetryDelayForBackpressure(t *testing.T) {
+	cases := []struct {
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{retryAfter: 0, want: retryDelay},
+		{retryAfter: time.Second, want: retryDelay},
+		{retryAfter: 30 * time.Second, want: 30 * time.Second},
+		{retryAfter: 2 * time.Hour, want: maxBackpressureDelay},
+	}
+	for _, tc := range cases {
+		if got := retryDelayFor(&Attempt{Backpressure: true, RetryAfter: tc.retryAfter}); got != tc.want {
+			t.Fatalf("retryDelayFor(%s) = %s, want %s", tc.retryAfter, got, tc.want)
+		}
+	}
+}
+
 func TestUnknownGatewayOutcomeStatusExhausted(t *testing.T) {
 	clock := newFakeClock(time.Unix(0, 0))
 	db := newTestDB(t)
@@ -747,6 +1241,9 @@ func TestUnknownGatewayOutcomeStatusExhausted(t *testing.T) {
 	if !strings.Contains(intent.Attempts[0].Error, "unknown gateway outcome status") {
 		t.Fatalf("expected unknown gateway outcome status error, got %q", intent.Attempts[0].Error)
 	}
+	if intent.Attempts[0].ErrorClass != submission.ErrorClassContractViolation {
+		t.Fatalf("expected contract_violation class, got %q", intent.Attempts[0].ErrorClass)
+	}
 }
 
 func TestContractSnapshotStableAfterRegistryChange(t *testing.T) {
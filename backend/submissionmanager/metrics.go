@@ -3,9 +3,12 @@ package submissionmanager
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
+
+	"gateway/submission"
 )
 
 // Metrics tracks SubmissionManager metrics for Prometheus.
@@ -24,16 +27,37 @@ type Metrics struct {
 	exhaustedMax      uint64
 	exhaustedOneShot  uint64
 	exhaustedUnknown  uint64
+	exhaustedClaim    uint64
+	exhaustedTerminal uint64
+	exhaustedExempt   uint64
 	exhaustedOther    uint64
 
 	attemptsAccepted uint64
 	attemptsRejected uint64
 	attemptsError    uint64
+	attemptsTimeout  uint64
+
+	attemptErrors map[submission.AttemptErrorClass]uint64
 
 	retriesScheduled uint64
+	backpressure     uint64
+	deferred         uint64
+
+	claimsRetried  uint64
+	claimsReviewed uint64
+
+	queueDepth     int
+	inflight       int
+	partitionsHeld int
+	draining       bool
+	drainRejected  uint64
 
-	queueDepth int
-	inflight   int
+	admissionRejectedGlobal uint64
+	admissionRejectedTarget uint64
+
+	// Alias counters are keyed by registry-defined names only, so their cardinality stays bounded.
+	aliasRouted   map[aliasRoute]uint64
+	aliasTerminal map[aliasTerminal]uint64
 
 	intentAcceptedDuration  histogram
 	intentRejectedDuration  histogram
@@ -57,9 +81,21 @@ func NewMetrics() *Metrics {
 		intentExhaustedDuration: newHistogram(durationBucketsIntentTerminal),
 		attemptDuration:         newHistogram(durationBucketsAttempt),
 		queueDelay:              newHistogram(durationBucketsQueueDelay),
+		attemptErrors:           make(map[submission.AttemptErrorClass]uint64, len(attemptErrorClasses)),
 	}
 }
 
+// attemptErrorClasses fixes the label order so every class is exported even at zero.
+var attemptErrorClasses = []submission.AttemptErrorClass{
+	submission.ErrorClassTransport,
+	submission.ErrorClassTimeout,
+	submission.ErrorClassGateway5xx,
+	submission.ErrorClassGateway4xx,
+	submission.ErrorClassDecode,
+	submission.ErrorClassContractViolation,
+	submission.ErrorClassLeaseLost,
+}
+
 var durationBucketsAttempt = []float64{
 	0.1,
 	0.25,
@@ -121,6 +157,19 @@ func (m *Metrics) ObserveIdempotencyConflict() {
 	m.mu.Unlock()
 }
 
+// ObserveAttemptError records an attempt error by class.
+func (m *Metrics) ObserveAttemptError(class submission.AttemptErrorClass) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	if m.attemptErrors == nil {
+		m.attemptErrors = make(map[submission.AttemptErrorClass]uint64, len(attemptErrorClasses))
+	}
+	m.attemptErrors[class]++
+	m.mu.Unlock()
+}
+
 // ObserveAttemptOutcome records an attempt outcome status.
 func (m *Metrics) ObserveAttemptOutcome(status string) {
 	if m == nil {
@@ -132,6 +181,8 @@ func (m *Metrics) ObserveAttemptOutcome(status string) {
 		m.attemptsAccepted++
 	case gatewayRejected:
 		m.attemptsRejected++
+	case attemptOutcomeTimeout:
+		m.attemptsTimeout++
 	default:
 		m.attemptsError++
 	}
@@ -148,6 +199,40 @@ func (m *Metrics) ObserveRetryScheduled() {
 	m.mu.Unlock()
 }
 
+// ObserveClaimReconciled records an unresolved attempt claim reconciled under strategy.
+func (m *Metrics) ObserveClaimReconciled(strategy ClaimStrategy) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	if strategy == ClaimReview {
+		m.claimsReviewed++
+	} else {
+		m.claimsRetried++
+	}
+	m.mu.Unlock()
+}
+
+// ObserveBackpressure records an attempt that hit gateway backpressure (429 or 503).
+func (m *Metrics) ObserveBackpressure() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.backpressure++
+	m.mu.Unlock()
+}
+
+// ObserveAttemptDeferred records an attempt moved to the next delivery window opening.
+func (m *Metrics) ObserveAttemptDeferred() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.deferred++
+	m.mu.Unlock()
+}
+
 // ObserveIntentTerminal records a terminal intent and its duration.
 func (m *Metrics) ObserveIntentTerminal(status IntentStatus, duration time.Duration) {
 	if m == nil {
@@ -187,6 +272,12 @@ func (m *Metrics) ObserveExhausted(reason string) {
 		m.exhaustedOneShot++
 	case "unknown_policy":
 		m.exhaustedUnknown++
+	case exhaustedAttemptUnresolved:
+		m.exhaustedClaim++
+	case exhaustedTerminalError:
+		m.exhaustedTerminal++
+	case exhaustedExemptAttempts:
+		m.exhaustedExempt++
 	default:
 		m.exhaustedOther++
 	}
@@ -234,6 +325,88 @@ func (m *Metrics) SetQueueDepth(depth int) {
 	m.mu.Unlock()
 }
 
+// SetPartitionsHeld updates the held lease partitions gauge.
+func (m *Metrics) SetPartitionsHeld(count int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.partitionsHeld = count
+	m.mu.Unlock()
+}
+
+// SetDraining updates the drain mode gauge.
+func (m *Metrics) SetDraining(draining bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.draining = draining
+	m.mu.Unlock()
+}
+
+// ObserveDrainRejected records a new intent refused while draining.
+func (m *Metrics) ObserveDrainRejected() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.drainRejected++
+	m.mu.Unlock()
+}
+
+// ObserveAdmissionRejected records a new intent refused by the global or per-target pending limit.
+func (m *Metrics) ObserveAdmissionRejected(scope string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	if scope == admissionScopeTarget {
+		m.admissionRejectedTarget++
+	} else {
+		m.admissionRejectedGlobal++
+	}
+	m.mu.Unlock()
+}
+
+// aliasRoute is an alias and the concrete submissionTarget an intent resolved to.
+type aliasRoute struct {
+	alias  string
+	target string
+}
+
+type aliasTerminal struct {
+	aliasRoute
+	status IntentStatus
+}
+
+// ObserveAliasRouted records a new intent submitted to alias and resolved to target. Intents
+// submitted to a concrete target (empty alias) are not counted.
+func (m *Metrics) ObserveAliasRouted(alias, target string) {
+	if m == nil || alias == "" {
+		return
+	}
+	m.mu.Lock()
+	if m.aliasRouted == nil {
+		m.aliasRouted = make(map[aliasRoute]uint64)
+	}
+	m.aliasRouted[aliasRoute{alias: alias, target: target}]++
+	m.mu.Unlock()
+}
+
+// ObserveAliasTerminal records a terminal intent that was submitted to alias.
+func (m *Metrics) ObserveAliasTerminal(alias, target string, status IntentStatus) {
+	if m == nil || alias == "" {
+		return
+	}
+	m.mu.Lock()
+	if m.aliasTerminal == nil {
+		m.aliasTerminal = make(map[aliasTerminal]uint64)
+	}
+	m.aliasTerminal[aliasTerminal{aliasRoute: aliasRoute{alias: alias, target: target}, status: status}]++
+	m.mu.Unlock()
+}
+
 // IncInflight increments the inflight attempt gauge.
 func (m *Metrics) IncInflight() {
 	if m == nil {
@@ -273,13 +446,41 @@ func (m *Metrics) WritePrometheus(w io.Writer) {
 	exhaustedMax := m.exhaustedMax
 	exhaustedOneShot := m.exhaustedOneShot
 	exhaustedUnknown := m.exhaustedUnknown
+	exhaustedClaim := m.exhaustedClaim
+	exhaustedTerminal := m.exhaustedTerminal
+	exhaustedExempt := m.exhaustedExempt
 	exhaustedOther := m.exhaustedOther
 	attemptsAccepted := m.attemptsAccepted
 	attemptsRejected := m.attemptsRejected
 	attemptsError := m.attemptsError
+	attemptsTimeout := m.attemptsTimeout
+	attemptErrors := make(map[submission.AttemptErrorClass]uint64, len(m.attemptErrors))
+	for class, count := range m.attemptErrors {
+		attemptErrors[class] = count
+	}
 	retriesScheduled := m.retriesScheduled
+	backpressure := m.backpressure
+	deferred := m.deferred
+	claimsRetried := m.claimsRetried
+	claimsReviewed := m.claimsReviewed
 	queueDepth := m.queueDepth
 	inflight := m.inflight
+	partitionsHeld := m.partitionsHeld
+	draining := 0
+	if m.draining {
+		draining = 1
+	}
+	drainRejected := m.drainRejected
+	admissionRejectedGlobal := m.admissionRejectedGlobal
+	admissionRejectedTarget := m.admissionRejectedTarget
+	aliasRouted := make(map[aliasRoute]uint64, len(m.aliasRouted))
+	for route, count := range m.aliasRouted {
+		aliasRouted[route] = count
+	}
+	aliasTerminals := make(map[aliasTerminal]uint64, len(m.aliasTerminal))
+	for key, count := range m.aliasTerminal {
+		aliasTerminals[key] = count
+	}
 	intentAcceptedDuration := copyHistogram(m.intentAcceptedDuration)
 	intentRejectedDuration := copyHistogram(m.intentRejectedDuration)
 	intentExhaustedDuration := copyHistogram(m.intentExhaustedDuration)
@@ -311,6 +512,9 @@ func (m *Metrics) WritePrometheus(w io.Writer) {
 	fmt.Fprintf(w, "submission_exhausted_total{reason=%q} %d\n", "max_attempts", exhaustedMax)
 	fmt.Fprintf(w, "submission_exhausted_total{reason=%q} %d\n", "one_shot", exhaustedOneShot)
 	fmt.Fprintf(w, "submission_exhausted_total{reason=%q} %d\n", "unknown_policy", exhaustedUnknown)
+	fmt.Fprintf(w, "submission_exhausted_total{reason=%q} %d\n", exhaustedAttemptUnresolved, exhaustedClaim)
+	fmt.Fprintf(w, "submission_exhausted_total{reason=%q} %d\n", exhaustedTerminalError, exhaustedTerminal)
+	fmt.Fprintf(w, "submission_exhausted_total{reason=%q} %d\n", exhaustedExemptAttempts, exhaustedExempt)
 	fmt.Fprintf(w, "submission_exhausted_total{reason=%q} %d\n", "unknown_reason", exhaustedOther)
 
 	fmt.Fprintf(w, "# HELP submission_attempts_total Attempt outcomes by status.\n")
@@ -318,11 +522,31 @@ func (m *Metrics) WritePrometheus(w io.Writer) {
 	fmt.Fprintf(w, "submission_attempts_total{outcome_status=%q} %d\n", "accepted", attemptsAccepted)
 	fmt.Fprintf(w, "submission_attempts_total{outcome_status=%q} %d\n", "rejected", attemptsRejected)
 	fmt.Fprintf(w, "submission_attempts_total{outcome_status=%q} %d\n", "error", attemptsError)
+	fmt.Fprintf(w, "submission_attempts_total{outcome_status=%q} %d\n", "attempt_timeout", attemptsTimeout)
+
+	fmt.Fprintf(w, "# HELP submission_attempt_errors_total Attempt errors by failure class.\n")
+	fmt.Fprintf(w, "# TYPE submission_attempt_errors_total counter\n")
+	for _, class := range attemptErrorClasses {
+		fmt.Fprintf(w, "submission_attempt_errors_total{error_class=%q} %d\n", string(class), attemptErrors[class])
+	}
 
 	fmt.Fprintf(w, "# HELP submission_retries_scheduled_total Retries scheduled.\n")
 	fmt.Fprintf(w, "# TYPE submission_retries_scheduled_total counter\n")
 	fmt.Fprintf(w, "submission_retries_scheduled_total %d\n", retriesScheduled)
 
+	fmt.Fprintf(w, "# HELP submission_attempt_backpressure_total Attempts answered with gateway backpressure (429 or 503).\n")
+	fmt.Fprintf(w, "# TYPE submission_attempt_backpressure_total counter\n")
+	fmt.Fprintf(w, "submission_attempt_backpressure_total %d\n", backpressure)
+
+	fmt.Fprintf(w, "# HELP submission_attempts_deferred_total Attempts deferred to the next delivery window opening.\n")
+	fmt.Fprintf(w, "# TYPE submission_attempts_deferred_total counter\n")
+	fmt.Fprintf(w, "submission_attempts_deferred_total %d\n", deferred)
+
+	fmt.Fprintf(w, "# HELP submission_attempt_claims_reconciled_total Unresolved attempt claims reconciled on partition takeover.\n")
+	fmt.Fprintf(w, "# TYPE submission_attempt_claims_reconciled_total counter\n")
+	fmt.Fprintf(w, "submission_attempt_claims_reconciled_total{strategy=%q} %d\n", string(ClaimRetry), claimsRetried)
+	fmt.Fprintf(w, "submission_attempt_claims_reconciled_total{strategy=%q} %d\n", string(ClaimReview), claimsReviewed)
+
 	fmt.Fprintf(w, "# HELP submission_queue_depth Pending scheduled attempts.\n")
 	fmt.Fprintf(w, "# TYPE submission_queue_depth gauge\n")
 	fmt.Fprintf(w, "submission_queue_depth %d\n", queueDepth)
@@ -331,6 +555,59 @@ func (m *Metrics) WritePrometheus(w io.Writer) {
 	fmt.Fprintf(w, "# TYPE submission_inflight_attempts gauge\n")
 	fmt.Fprintf(w, "submission_inflight_attempts %d\n", inflight)
 
+	fmt.Fprintf(w, "# HELP submission_partitions_held Lease partitions this instance executes.\n")
+	fmt.Fprintf(w, "# TYPE submission_partitions_held gauge\n")
+	fmt.Fprintf(w, "submission_partitions_held %d\n", partitionsHeld)
+
+	fmt.Fprintf(w, "# HELP submission_draining Whether drain mode refuses new intents (1) or not (0).\n")
+	fmt.Fprintf(w, "# TYPE submission_draining gauge\n")
+	fmt.Fprintf(w, "submission_draining %d\n", draining)
+
+	fmt.Fprintf(w, "# HELP submission_drain_rejected_total New intents refused while draining.\n")
+	fmt.Fprintf(w, "# TYPE submission_drain_rejected_total counter\n")
+	fmt.Fprintf(w, "submission_drain_rejected_total %d\n", drainRejected)
+
+	fmt.Fprintf(w, "# HELP submission_admission_rejected_total New intents refused by a pending-intent limit.\n")
+	fmt.Fprintf(w, "# TYPE submission_admission_rejected_total counter\n")
+	fmt.Fprintf(w, "submission_admission_rejected_total{scope=%q} %d\n", admissionScopeGlobal, admissionRejectedGlobal)
+	fmt.Fprintf(w, "submission_admission_rejected_total{scope=%q} %d\n", admissionScopeTarget, admissionRejectedTarget)
+
+	routes := make([]aliasRoute, 0, len(aliasRouted))
+	for route := range aliasRouted {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].alias != routes[j].alias {
+			return routes[i].alias < routes[j].alias
+		}
+		return routes[i].target < routes[j].target
+	})
+	fmt.Fprintf(w, "# HELP submission_alias_routed_total Intents submitted to an alias by the concrete submissionTarget they resolved to.\n")
+	fmt.Fprintf(w, "# TYPE submission_alias_routed_total counter\n")
+	for _, route := range routes {
+		fmt.Fprintf(w, "submission_alias_routed_total{alias=%q,submission_target=%q} %d\n", route.alias, route.target, aliasRouted[route])
+	}
+
+	terminalKeys := make([]aliasTerminal, 0, len(aliasTerminals))
+	for key := range aliasTerminals {
+		terminalKeys = append(terminalKeys, key)
+	}
+	sort.Slice(terminalKeys, func(i, j int) bool {
+		a, b := terminalKeys[i], terminalKeys[j]
+		if a.alias != b.alias {
+			return a.alias < b.alias
+		}
+		if a.target != b.target {
+			return a.target < b.target
+		}
+		return a.status < b.status
+	})
+	fmt.Fprintf(w, "# HELP submission_alias_intents_terminal_total Terminal intents submitted to an alias by concrete submissionTarget and final status.\n")
+	fmt.Fprintf(w, "# TYPE submission_alias_intents_terminal_total counter\n")
+	for _, key := range terminalKeys {
+		fmt.Fprintf(w, "submission_alias_intents_terminal_total{alias=%q,submission_target=%q,status=%q} %d\n", key.alias, key.target, string(key.status), aliasTerminals[key])
+	}
+
 	writeHistogram(w, "submission_intent_time_to_terminal_seconds", "Intent time to terminal in seconds.", `status="accepted"`, intentAcceptedDuration)
 	writeHistogram(w, "submission_intent_time_to_terminal_seconds", "Intent time to terminal in seconds.", `status="rejected"`, intentRejectedDuration)
 	writeHistogram(w, "submission_intent_time_to_terminal_seconds", "Intent time to terminal in seconds.", `status="exhausted"`, intentExhaustedDuration)
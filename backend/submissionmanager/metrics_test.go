@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"gateway/submission"
 )
 
 func TestMetricsWritePrometheus(t *testing.T) {
@@ -15,7 +17,10 @@ func TestMetricsWritePrometheus(t *testing.T) {
 	metrics.ObserveAttemptOutcome(gatewayAccepted)
 	metrics.ObserveAttemptOutcome(gatewayRejected)
 	metrics.ObserveAttemptOutcome("error")
+	metrics.ObserveAttemptOutcome(attemptOutcomeTimeout)
+	metrics.ObserveAttemptError(submission.ErrorClassGateway5xx)
 	metrics.ObserveRetryScheduled()
+	metrics.ObserveAttemptDeferred()
 	metrics.ObserveIntentTerminal(IntentAccepted, 2*time.Second)
 	metrics.ObserveIntentTerminal(IntentRejected, 3*time.Second)
 	metrics.ObserveIntentTerminal(IntentExhausted, 4*time.Second)
@@ -24,11 +29,19 @@ func TestMetricsWritePrometheus(t *testing.T) {
 	metrics.ObserveExhausted("one_shot")
 	metrics.ObserveExhausted("unknown_policy")
 	metrics.ObserveExhausted("other")
+	metrics.ObserveExhausted(exhaustedTerminalError)
+	metrics.ObserveExhausted(exhaustedExemptAttempts)
 	metrics.ObserveAttemptDuration(500 * time.Millisecond)
 	metrics.ObserveQueueDelay(10 * time.Millisecond)
 	metrics.SetQueueDepth(3)
 	metrics.IncInflight()
 	metrics.DecInflight()
+	metrics.SetDraining(true)
+	metrics.ObserveDrainRejected()
+	metrics.ObserveAdmissionRejected(admissionScopeTarget)
+	metrics.ObserveAliasRouted("otp-sms", "sms.realtime")
+	metrics.ObserveAliasRouted("", "sms.realtime")
+	metrics.ObserveAliasTerminal("otp-sms", "sms.realtime", IntentAccepted)
 
 	var buf bytes.Buffer
 	metrics.WritePrometheus(&buf)
@@ -42,12 +55,24 @@ func TestMetricsWritePrometheus(t *testing.T) {
 		`submission_intents_terminal_total{status="rejected"} 1`,
 		`submission_intents_terminal_total{status="exhausted"} 1`,
 		`submission_exhausted_total{reason="unknown_reason"} 1`,
+		`submission_exhausted_total{reason="terminal_error"} 1`,
+		`submission_exhausted_total{reason="exempt_attempts"} 1`,
 		`submission_attempts_total{outcome_status="accepted"} 1`,
 		`submission_attempts_total{outcome_status="rejected"} 1`,
 		`submission_attempts_total{outcome_status="error"} 1`,
+		`submission_attempts_total{outcome_status="attempt_timeout"} 1`,
+		`submission_attempt_errors_total{error_class="gateway_5xx"} 1`,
+		`submission_attempt_errors_total{error_class="lease_lost"} 0`,
 		"submission_retries_scheduled_total 1",
+		"submission_attempts_deferred_total 1",
 		"submission_queue_depth 3",
 		"submission_inflight_attempts 0",
+		"submission_draining 1",
+		"submission_drain_rejected_total 1",
+		`submission_admission_rejected_total{scope="global"} 0`,
+		`submission_admission_rejected_total{scope="target"} 1`,
+		`submission_alias_routed_total{alias="otp-sms",submission_target="sms.realtime"} 1`,
+		`submission_alias_intents_terminal_total{alias="otp-sms",submission_target="sms.realtime",status="accepted"} 1`,
 		"submission_attempt_duration_seconds_bucket",
 		"submission_intent_time_to_terminal_seconds_bucket",
 		"submission_queue_delay_seconds_bucket",
@@ -0,0 +1,38 @@
+package submissionmanager
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+)
+
+// partitionKey mirrors the persisted partition_key column:
+// CAST(SUBSTRING(HASHBYTES('SHA2_256', intent_id), 1, 4) AS INT) & 0x7FFFFFFF.
+// Non-obvious constraint: intent_id is NVARCHAR, so SQL Server hashes its UTF-16LE bytes.
+func partitionKey(intentID string) int {
+	units := utf16.Encode([]rune(intentID))
+	buf := make([]byte, 2*len(units))
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(buf[2*i:], unit)
+	}
+	sum := sha256.Sum256(buf)
+	return int(binary.BigEndian.Uint32(sum[:4]) & 0x7fffffff)
+}
+
+// partitionFor maps a partition key onto one of count partitions.
+func partitionFor(key, count int) int {
+	if count <= 1 {
+		return 0
+	}
+	return key % count
+}
+
+// partitionLeaseName names the lease row for a partition. A single partition keeps the bare
+// lease name so existing deployments continue with the same row.
+func partitionLeaseName(leaseName string, partition, count int) string {
+	if count <= 1 {
+		return leaseName
+	}
+	return fmt.Sprintf("%s/%d", leaseName, partition)
+}
@@ -0,0 +1,72 @@
+package submissionmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPartitionKeyMatchesSQLHash(t *testing.T) {
+	// Expected values are CAST(SUBSTRING(HASHBYTES('SHA2_256', N'<id>'), 1, 4) AS INT) & 0x7FFFFFFF.
+	cases := map[string]int{
+		"intent-1": 983166109,
+		"ïntent-ü": 672226993,
+		"😀x":       1263998764,
+	}
+	for intentID, want := range cases {
+		if got := partitionKey(intentID); got != want {
+			t.Fatalf("partitionKey(%q) = %d, want %d", intentID, got, want)
+		}
+	}
+}
+
+func TestPartitionLeaseName(t *testing.T) {
+	if got := partitionLeaseName("executor", 0, 1); got != "executor" {
+		t.Fatalf("single partition should keep the bare lease name, got %q", got)
+	}
+	if got := partitionLeaseName("executor", 3, 8); got != "executor/3" {
+		t.Fatalf("unexpected partition lease name %q", got)
+	}
+}
+
+func TestReleasePartitionDropsScheduleAndWaitsForInflight(t *testing.T) {
+	m := &Manager{
+		wake:           make(chan struct{}, 1),
+		scheduled:      make(map[string]scheduledAttempt),
+		partitionCount: 2,
+		held:           make(map[int]heldPartition),
+	}
+	m.holdPartition(LeaseFence{Partition: 0}, nil)
+	m.holdPartition(LeaseFence{Partition: 1}, nil)
+	due := time.Unix(0, 0)
+	m.enqueueAttempt("a", 0, due)
+	m.enqueueAttempt("b", 1, due)
+
+	m.mu.Lock()
+	inflight := m.held[0].inflight
+	m.mu.Unlock()
+	inflight.Add(1)
+
+	idle := m.releasePartition(0)
+	m.enqueueAttempt("c", 0, due)
+	m.mu.Lock()
+	_, okA := m.scheduled["a"]
+	_, okB := m.scheduled["b"]
+	_, okC := m.scheduled["c"]
+	queued := len(m.queue.items)
+	m.mu.Unlock()
+	if okA || okC || !okB || queued != 1 {
+		t.Fatalf("expected only partition 1 scheduled; a=%t b=%t c=%t queued=%d", okA, okB, okC, queued)
+	}
+
+	select {
+	case <-idle:
+		t.Fatalf("release should wait for the in-flight attempt")
+	case <-time.After(20 * time.Millisecond):
+	}
+	inflight.Done()
+	select {
+	case <-idle:
+	case <-time.After(time.Second):
+		t.Fatalf("release did not finish after the in-flight attempt")
+	}
+}
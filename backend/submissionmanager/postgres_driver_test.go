@@ -0,0 +1,3 @@
+package submissionmanager
+
+import _ "github.com/jackc/pgx/v5/stdlib"
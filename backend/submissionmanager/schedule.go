@@ -6,16 +6,14 @@ import (
 	"time"
 )
 
-// Run executes scheduled attempts until the context is canceled.
+// Run executes scheduled attempts of the held partitions until the context is canceled.
+// It idles while no partition is held.
 func (m *Manager) Run(ctx context.Context) {
 	// Flow intent: run due attempts in time order until stop.
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	for {
-		if !m.isLeader() {
-			return
-		}
 		select {
 		case <-ctx.Done():
 			return
@@ -23,11 +21,7 @@ func (m *Manager) Run(ctx context.Context) {
 		}
 
 		m.mu.Lock()
-		if !m.leader {
-			m.mu.Unlock()
-			return
-		}
-		if len(m.queue.items) == 0 {
+		if len(m.held) == 0 || len(m.queue.items) == 0 {
 			m.mu.Unlock()
 			select {
 			case <-ctx.Done():
@@ -40,17 +34,20 @@ func (m *Manager) Run(ctx context.Context) {
 
 		now, err := m.scheduleTimeNow(ctx)
 		if err != nil {
-			if ctx.Err() == nil {
-				m.notifyLeaseLoss()
+			if ctx.Err() != nil {
+				return
 			}
-			return
+			m.notifyAllLeaseLoss()
+			// Wait for the runner to drop the lost partitions instead of retrying SQL in a tight loop.
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.wake:
+			}
+			continue
 		}
 
 		m.mu.Lock()
-		if !m.leader {
-			m.mu.Unlock()
-			return
-		}
 		if len(m.queue.items) == 0 {
 			m.mu.Unlock()
 			continue
@@ -62,8 +59,8 @@ func (m *Manager) Run(ctx context.Context) {
 			// Concurrency/locking intent: pop under lock so the queue stays correct,
 			// then run outside the lock so we do not hold it during the gateway call.
 			heap.Pop(&m.queue)
-			due, ok := m.scheduled[next.intentID]
-			if !ok || !due.Equal(next.due) {
+			current, ok := m.scheduled[next.intentID]
+			if !ok || !current.due.Equal(next.due) {
 				m.mu.Unlock()
 				continue
 			}
@@ -71,11 +68,17 @@ func (m *Manager) Run(ctx context.Context) {
 			if m.metrics != nil {
 				m.metrics.SetQueueDepth(len(m.scheduled))
 			}
-			m.mu.Unlock()
-			if !m.isLeader() {
-				return
+			// Non-obvious constraint: the in-flight count is taken under the same lock as the held
+			// check, so releasePartition never misses an attempt that is about to start.
+			held, ok := m.held[next.partition]
+			if !ok {
+				m.mu.Unlock()
+				continue
 			}
-			m.executeAttempt(ctx, next.intentID, next.due)
+			held.inflight.Add(1)
+			m.mu.Unlock()
+			m.executeAttempt(ctx, held.fence, next.intentID, next.due)
+			held.inflight.Done()
 			continue
 		}
 		m.mu.Unlock()
@@ -91,26 +94,32 @@ func (m *Manager) Run(ctx context.Context) {
 	}
 }
 
-func (m *Manager) enqueueAttempt(intentID string, due time.Time) {
+// enqueueAttempt schedules intentID if this instance holds its partition.
+func (m *Manager) enqueueAttempt(intentID string, partition int, due time.Time) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.enqueueAttemptLocked(intentID, due)
+	m.enqueueAttemptLocked(intentID, partition, due)
 }
 
-func (m *Manager) enqueueAttemptLocked(intentID string, due time.Time) {
+func (m *Manager) enqueueAttemptLocked(intentID string, partition int, due time.Time) {
+	if _, ok := m.held[partition]; !ok {
+		return
+	}
 	if m.scheduled == nil {
-		m.scheduled = make(map[string]time.Time)
+		m.scheduled = make(map[string]scheduledAttempt)
 	}
-	if existing, ok := m.scheduled[intentID]; ok && existing.Equal(due) {
+	if existing, ok := m.scheduled[intentID]; ok && existing.due.Equal(due) {
 		return
 	}
 	m.nextSeq++
-	m.scheduled[intentID] = due
-	heap.Push(&m.queue, scheduledAttempt{
-		intentID: intentID,
-		due:      due,
-		seq:      m.nextSeq,
-	})
+	item := scheduledAttempt{
+		intentID:  intentID,
+		partition: partition,
+		due:       due,
+		seq:       m.nextSeq,
+	}
+	m.scheduled[intentID] = item
+	heap.Push(&m.queue, item)
 	if m.metrics != nil {
 		m.metrics.SetQueueDepth(len(m.scheduled))
 	}
@@ -121,9 +130,10 @@ func (m *Manager) enqueueAttemptLocked(intentID string, due time.Time) {
 }
 
 type scheduledAttempt struct {
-	intentID string
-	due      time.Time
-	seq      int
+	intentID  string
+	partition int
+	due       time.Time
+	seq       int
 }
 
 type attemptQueue struct {
@@ -1,34 +1,40 @@
 package submissionmanager
 
 import (
+	"container/heap"
 	"context"
 	"errors"
 	"time"
 )
 
 type scheduleCursor struct {
+	partition    int
 	lastModified time.Time
 	intentID     string
 }
 
-func (m *Manager) rebuildSchedule(ctx context.Context) (scheduleCursor, error) {
-	if !m.isLeader() {
-		return scheduleCursor{}, errors.New("schedule rebuild requires leader")
+// rebuildSchedule replaces the partition's scheduled attempts with a snapshot from SQL.
+func (m *Manager) rebuildSchedule(ctx context.Context, partition int) (scheduleCursor, error) {
+	if !m.holdsPartition(partition) {
+		return scheduleCursor{}, errors.New("schedule rebuild requires the partition lease")
 	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	rows, err := m.store.loadScheduleSnapshot(ctx)
+	m.mu.Lock()
+	count := m.partitionCount
+	m.mu.Unlock()
+	rows, err := m.store.loadScheduleSnapshot(ctx, partition, count)
 	if err != nil {
 		return scheduleCursor{}, err
 	}
 
-	var cursor scheduleCursor
+	cursor := scheduleCursor{partition: partition}
 	m.mu.Lock()
-	m.clearScheduleLocked()
+	m.clearPartitionLocked(partition)
 	for _, row := range rows {
-		m.enqueueAttemptLocked(row.intentID, row.due)
+		m.enqueueAttemptLocked(row.intentID, partition, row.due)
 		cursor.lastModified = row.lastModified
 		cursor.intentID = row.intentID
 	}
@@ -37,14 +43,17 @@ func (m *Manager) rebuildSchedule(ctx context.Context) (scheduleCursor, error) {
 }
 
 func (m *Manager) refreshSchedule(ctx context.Context, cursor scheduleCursor) (scheduleCursor, error) {
-	if !m.isLeader() {
-		return cursor, errors.New("schedule refresh requires leader")
+	if !m.holdsPartition(cursor.partition) {
+		return cursor, errors.New("schedule refresh requires the partition lease")
 	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	changes, err := m.store.loadScheduleChanges(ctx, cursor)
+	m.mu.Lock()
+	count := m.partitionCount
+	m.mu.Unlock()
+	changes, err := m.store.loadScheduleChanges(ctx, cursor, count)
 	if err != nil {
 		return cursor, err
 	}
@@ -60,7 +69,7 @@ func (m *Manager) refreshSchedule(ctx context.Context, cursor scheduleCursor) (s
 			delete(m.scheduled, change.intentID)
 			continue
 		}
-		m.enqueueAttemptLocked(change.intentID, *change.due)
+		m.enqueueAttemptLocked(change.intentID, cursor.partition, *change.due)
 	}
 	if m.metrics != nil {
 		m.metrics.SetQueueDepth(len(m.scheduled))
@@ -72,7 +81,7 @@ func (m *Manager) refreshSchedule(ctx context.Context, cursor scheduleCursor) (s
 func (m *Manager) clearScheduleLocked() {
 	m.queue.items = nil
 	if m.scheduled == nil {
-		m.scheduled = make(map[string]time.Time)
+		m.scheduled = make(map[string]scheduledAttempt)
 	} else {
 		for key := range m.scheduled {
 			delete(m.scheduled, key)
@@ -83,3 +92,23 @@ func (m *Manager) clearScheduleLocked() {
 		m.metrics.SetQueueDepth(len(m.scheduled))
 	}
 }
+
+// clearPartitionLocked drops one partition's attempts and rebuilds the heap from the rest.
+func (m *Manager) clearPartitionLocked(partition int) {
+	kept := m.queue.items[:0]
+	for _, item := range m.queue.items {
+		if item.partition != partition {
+			kept = append(kept, item)
+		}
+	}
+	m.queue.items = kept
+	heap.Init(&m.queue)
+	for key, item := range m.scheduled {
+		if item.partition == partition {
+			delete(m.scheduled, key)
+		}
+	}
+	if m.metrics != nil {
+		m.metrics.SetQueueDepth(len(m.scheduled))
+	}
+}
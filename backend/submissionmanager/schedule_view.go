@@ -0,0 +1,154 @@
+package submissionmanager
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	leaseEventAcquired    = "acquired"
+	leaseEventRenewFailed = "renew_failed"
+	leaseEventLost        = "lost"
+	leaseEventReleased    = "released"
+)
+
+// LeaseEvent is one recorded partition lease transition: acquired, renew_failed, lost or released.
+type LeaseEvent struct {
+	Partition  int
+	HolderID   string
+	LeaseEpoch int64
+	Event      string
+	Detail     string
+	OccurredAt time.Time
+}
+
+// PartitionLeaseState is the stored lease row of one partition, whichever instance holds it.
+// Active is false once the lease has expired or was released.
+type PartitionLeaseState struct {
+	Partition  int
+	LeaseName  string
+	HolderID   string
+	LeaseEpoch int64
+	ExpiresAt  time.Time
+	Active     bool
+}
+
+// DueAttempt is the next scheduled attempt of a pending intent.
+type DueAttempt struct {
+	IntentID         string
+	SubmissionTarget string
+	Partition        int
+	AttemptNumber    int
+	DueAt            time.Time
+}
+
+// InFlightAttempt is an attempt claimed by a holder whose outcome is not recorded yet.
+type InFlightAttempt struct {
+	IntentID      string
+	Partition     int
+	AttemptNumber int
+	HolderID      string
+	LeaseEpoch    int64
+	ClaimedAt     time.Time
+}
+
+// ScheduleView is the cluster-wide scheduler state read from SQL, plus this instance's lease view.
+// Now is the database clock the lease expiries and due times compare against.
+type ScheduleView struct {
+	Now          time.Time
+	Local        LeaseStatus
+	Leases       []PartitionLeaseState
+	QueueDepth   map[string]int
+	Due          []DueAttempt
+	InFlight     []InFlightAttempt
+	LeaseHistory []LeaseEvent
+}
+
+// Schedule reads the scheduler state for the admin view: every partition lease, the scheduled
+// intents per submissionTarget, the limit soonest due attempts, in-flight attempts, and the limit
+// newest lease events. It works on followers too, since everything but Local comes from SQL.
+func (r *LeaderRunner) Schedule(ctx context.Context, limit int) (ScheduleView, error) {
+	limit = max(limit, 1)
+	count := r.cfg.partitionCount()
+	now, err := r.store.loadSQLTime(ctx)
+	if err != nil {
+		return ScheduleView{}, err
+	}
+	view := ScheduleView{Now: now, Local: r.Status()}
+
+	for partition := 0; partition < count; partition++ {
+		leaseName := partitionLeaseName(r.cfg.LeaseName, partition, count)
+		lease, found, err := r.store.readLease(ctx, leaseName)
+		if err != nil {
+			return ScheduleView{}, err
+		}
+		if !found {
+			continue
+		}
+		view.Leases = append(view.Leases, PartitionLeaseState{
+			Partition:  partition,
+			LeaseName:  leaseName,
+			HolderID:   lease.holderID,
+			LeaseEpoch: lease.leaseEpoch,
+			ExpiresAt:  lease.expiresAt,
+			Active:     lease.expiresAt.After(now),
+		})
+	}
+
+	if view.QueueDepth, err = r.store.loadQueueDepths(ctx); err != nil {
+		return ScheduleView{}, err
+	}
+
+	due, err := r.store.loadDueIntents(ctx, limit)
+	if err != nil {
+		return ScheduleView{}, err
+	}
+	for _, row := range due {
+		view.Due = append(view.Due, DueAttempt{
+			IntentID:         row.intentID,
+			SubmissionTarget: row.submissionTarget,
+			Partition:        partitionFor(row.partitionKey, count),
+			AttemptNumber:    row.attemptCount + 1,
+			DueAt:            row.due,
+		})
+	}
+
+	// A single partition spans every intent, so this lists the claims of all holders.
+	claims, err := r.store.loadAttemptClaims(ctx, 0, 1)
+	if err != nil {
+		return ScheduleView{}, err
+	}
+	for _, claim := range claims {
+		view.InFlight = append(view.InFlight, InFlightAttempt{
+			IntentID:      claim.intentID,
+			Partition:     partitionFor(partitionKey(claim.intentID), count),
+			AttemptNumber: claim.attemptNumber,
+			HolderID:      claim.holderID,
+			LeaseEpoch:    claim.leaseEpoch,
+			ClaimedAt:     claim.claimedAt,
+		})
+	}
+
+	if view.LeaseHistory, err = r.store.loadLeaseEvents(ctx, r.cfg.LeaseName, limit); err != nil {
+		return ScheduleView{}, err
+	}
+	return view, nil
+}
+
+// recordLeaseEvent appends a lease transition to the history. A failed write is logged and does
+// not affect the lease itself.
+func (r *LeaderRunner) recordLeaseEvent(ctx context.Context, partition int, epoch int64, event string, detail string) {
+	eventCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), leaseReleaseTimeout)
+	defer cancel()
+	err := r.store.recordLeaseEvent(eventCtx, r.cfg.LeaseName, LeaseEvent{
+		Partition:  partition,
+		HolderID:   r.cfg.HolderID,
+		LeaseEpoch: epoch,
+		Event:      event,
+		Detail:     detail,
+	})
+	if err != nil {
+		slog.Error("lease_event_failed", "holderId", r.cfg.HolderID, "partition", partition, "leaseEpoch", epoch, "event", event, "error", err)
+	}
+}
@@ -2,6 +2,7 @@ package submissionmanager
 
 import (
 	"encoding/json"
+	"time"
 
 	"gateway/submission"
 )
@@ -21,9 +22,30 @@ func clonePayload(payload json.RawMessage) json.RawMessage {
 
 func cloneContract(contract submission.TargetContract) submission.TargetContract {
 	clone := contract
+	if len(contract.GatewayURLs) > 0 {
+		clone.GatewayURLs = append([]string(nil), contract.GatewayURLs...)
+	}
 	if len(contract.TerminalOutcomes) > 0 {
 		clone.TerminalOutcomes = append([]string(nil), contract.TerminalOutcomes...)
 	}
+	if len(contract.BudgetExemptErrorClasses) > 0 {
+		clone.BudgetExemptErrorClasses = append([]submission.AttemptErrorClass(nil), contract.BudgetExemptErrorClasses...)
+	}
+	if len(contract.OutcomeRules) > 0 {
+		clone.OutcomeRules = make(map[string]submission.RetryRule, len(contract.OutcomeRules))
+		for reason, rule := range contract.OutcomeRules {
+			clone.OutcomeRules[reason] = rule
+		}
+	}
+	if len(contract.ErrorClassRules) > 0 {
+		clone.ErrorClassRules = make(map[submission.AttemptErrorClass]submission.RetryRule, len(contract.ErrorClassRules))
+		for class, rule := range contract.ErrorClassRules {
+			clone.ErrorClassRules[class] = rule
+		}
+	}
+	if contract.DeliveryWindow != nil {
+		clone.DeliveryWindow = cloneDeliveryWindow(contract.DeliveryWindow)
+	}
 	if contract.Webhook != nil {
 		clone.Webhook = cloneWebhook(contract.Webhook)
 	}
@@ -36,8 +58,15 @@ func cloneWebhook(webhook *submission.WebhookConfig) *submission.WebhookConfig {
 	}
 	clone := &submission.WebhookConfig{
 		URL:       webhook.URL,
+		Format:    webhook.Format,
 		SecretEnv: webhook.SecretEnv,
 	}
+	if len(webhook.AllowedCallbackURLs) > 0 {
+		clone.AllowedCallbackURLs = append([]string(nil), webhook.AllowedCallbackURLs...)
+	}
+	if len(webhook.AllowedCallbackSecretEnvs) > 0 {
+		clone.AllowedCallbackSecretEnvs = append([]string(nil), webhook.AllowedCallbackSecretEnvs...)
+	}
 	if len(webhook.Headers) > 0 {
 		clone.Headers = make(map[string]string, len(webhook.Headers))
 		for key, value := range webhook.Headers {
@@ -52,3 +81,21 @@ func cloneWebhook(webhook *submission.WebhookConfig) *submission.WebhookConfig {
 	}
 	return clone
 }
+
+func cloneDeliveryWindow(window *submission.DeliveryWindow) *submission.DeliveryWindow {
+	clone := *window
+	if len(window.CountryTimeZones) > 0 {
+		clone.CountryTimeZones = make(map[string]string, len(window.CountryTimeZones))
+		for code, zone := range window.CountryTimeZones {
+			clone.CountryTimeZones[code] = zone
+		}
+	}
+	clone.Spans = make([]submission.WindowSpan, len(window.Spans))
+	for i, span := range window.Spans {
+		clone.Spans[i] = span
+		if len(span.Days) > 0 {
+			clone.Spans[i].Days = append([]time.Weekday(nil), span.Days...)
+		}
+	}
+	return &clone
+}
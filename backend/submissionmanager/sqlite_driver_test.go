@@ -0,0 +1,3 @@
+package submissionmanager
+
+import _ "modernc.org/sqlite"
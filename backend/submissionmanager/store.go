@@ -1,14 +1,68 @@
 package submissionmanager
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"time"
 )
 
+// Store persists intents, attempts, schedule state, webhook results, and leases. Every executor
+// write that mutates execution state is fenced by the caller's LeaseFence. Implementations live in
+// this package; construct one with NewSQLServerStore, NewPostgresStore, or NewSQLiteStore.
+type Store interface {
+	insertIntent(ctx context.Context, intent Intent, payloadHash []byte, now time.Time) (Intent, bool, error)
+	loadIntent(ctx context.Context, intentID string) (Intent, bool, error)
+	loadIntentRow(ctx context.Context, intentID string) (Intent, int, bool, error)
+	loadIntentForExecution(ctx context.Context, intentID string) (Intent, int, bool, error)
+	// loadIntentChangeMark returns the high-water mark of intent changes: every change below it is
+	// committed, and every later change lands at or above it.
+	loadIntentChangeMark(ctx context.Context) (int64, error)
+	// loadIntentChanges returns the intents changed at or above since, and the mark to pass next.
+	loadIntentChanges(ctx context.Context, since int64) ([]string, int64, error)
+	markExhausted(ctx context.Context, fence LeaseFence, intentID string, exhaustedReason string, now time.Time) (bool, error)
+	deferAttempt(ctx context.Context, fence LeaseFence, intentID string, nextAttemptAt time.Time, deferredFor time.Duration, now time.Time) (bool, error)
+
+	loadAttempts(ctx context.Context, intentID string) ([]Attempt, error)
+	recordAttempt(ctx context.Context, fence LeaseFence, intent Intent, attempt Attempt, nextAttemptAt *time.Time, now time.Time) (bool, error)
+	claimAttempt(ctx context.Context, fence LeaseFence, intentID string, attemptNumber int, now time.Time) (bool, error)
+	loadAttemptClaims(ctx context.Context, partition, count int) ([]attemptClaim, error)
+
+	// loadSQLTime returns the database clock, which every lease and schedule comparison uses.
+	loadSQLTime(ctx context.Context) (time.Time, error)
+	loadScheduleSnapshot(ctx context.Context, partition, count int) ([]scheduleSnapshotRow, error)
+	loadScheduleChanges(ctx context.Context, cursor scheduleCursor, count int) ([]scheduleChangeRow, error)
+
+	recordWebhookAttempt(ctx context.Context, fence LeaseFence, intentID string, status string, attemptedAt time.Time, errMsg string) (bool, error)
+	recordWebhookRedelivery(ctx context.Context, intentID string, status string, attemptedAt time.Time, errMsg string) error
+
+	acquireLease(ctx context.Context, cfg LeaseConfig) (leaseRow, bool, error)
+	renewLease(ctx context.Context, cfg LeaseConfig, epoch int64) (leaseRow, bool, error)
+	readLease(ctx context.Context, leaseName string) (leaseRow, bool, error)
+	releaseLease(ctx context.Context, cfg LeaseConfig, epoch int64) (bool, error)
+	heartbeatMember(ctx context.Context, cfg LeaseConfig) ([]leaseMember, error)
+	removeMember(ctx context.Context, cfg LeaseConfig) error
+	countPartitionHolders(ctx context.Context, cfg LeaseConfig) (map[string]int, error)
+
+	recordLeaseEvent(ctx context.Context, leaseName string, event LeaseEvent) error
+	loadLeaseEvents(ctx context.Context, leaseName string, limit int) ([]LeaseEvent, error)
+	loadQueueDepths(ctx context.Context) (map[string]int, error)
+	loadDueIntents(ctx context.Context, limit int) ([]dueIntentRow, error)
+
+	loadDrain(ctx context.Context) (DrainState, error)
+	saveDrain(ctx context.Context, draining bool, reason string) (DrainState, error)
+}
+
+// sqlStore is the SQL Server store.
 type sqlStore struct {
 	db *sql.DB
 }
 
+// NewSQLServerStore returns a Store backed by SQL Server (conf/sql/submissionmanager).
+func NewSQLServerStore(db *sql.DB) (Store, error) {
+	return newSQLStore(db)
+}
+
 func newSQLStore(db *sql.DB) (*sqlStore, error) {
 	if db == nil {
 		return nil, errors.New("db is required")
@@ -0,0 +1,156 @@
+package submissionmanager
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// leaseEventRetention bounds the lease history; older events are dropped as new ones are recorded.
+const leaseEventRetention = 7 * 24 * time.Hour
+
+// maxLeaseEventDetail matches the width of the detail column.
+const maxLeaseEventDetail = 512
+
+type dueIntentRow struct {
+	intentID         string
+	submissionTarget string
+	due              time.Time
+	attemptCount     int
+	partitionKey     int
+}
+
+// recordLeaseEvent appends a lease transition to the history and drops events past retention.
+func (s *sqlStore) recordLeaseEvent(ctx context.Context, leaseName string, event LeaseEvent) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO dbo.submission_manager_lease_events (
+      lease_name, partition_number, holder_id, lease_epoch, event_type, detail, occurred_at
+    ) VALUES (@p1, @p2, @p3, @p4, @p5, @p6, SYSUTCDATETIME());
+    DELETE FROM dbo.submission_manager_lease_events
+    WHERE occurred_at < DATEADD(SECOND, -@p7, SYSUTCDATETIME());`,
+		leaseName,
+		event.Partition,
+		event.HolderID,
+		event.LeaseEpoch,
+		event.Event,
+		nullString(leaseEventDetail(event.Detail)),
+		int64(leaseEventRetention.Seconds()),
+	)
+	return err
+}
+
+// loadLeaseEvents returns the newest lease events for the base lease name, newest first.
+func (s *sqlStore) loadLeaseEvents(ctx context.Context, leaseName string, limit int) ([]LeaseEvent, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT TOP (@p2) partition_number, holder_id, lease_epoch, event_type, detail, occurred_at
+     FROM dbo.submission_manager_lease_events
+     WHERE lease_name = @p1
+     ORDER BY occurred_at DESC, event_id DESC`,
+		leaseName,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanLeaseEvents(rows)
+}
+
+// loadQueueDepths counts scheduled pending intents per submissionTarget.
+func (s *sqlStore) loadQueueDepths(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT submission_target, COUNT(*)
+     FROM dbo.submission_intents
+     WHERE status = @p1 AND next_attempt_at IS NOT NULL
+     GROUP BY submission_target`,
+		string(IntentPending),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanQueueDepths(rows)
+}
+
+// loadDueIntents returns the limit scheduled pending intents that are due soonest.
+func (s *sqlStore) loadDueIntents(ctx context.Context, limit int) ([]dueIntentRow, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT TOP (@p2) intent_id, submission_target, next_attempt_at, attempt_count, partition_key
+     FROM dbo.submission_intents
+     WHERE status = @p1 AND next_attempt_at IS NOT NULL
+     ORDER BY next_attempt_at, intent_id`,
+		string(IntentPending),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDueIntents(rows)
+}
+
+func scanLeaseEvents(rows *sql.Rows) ([]LeaseEvent, error) {
+	events := []LeaseEvent{}
+	for rows.Next() {
+		var (
+			event  LeaseEvent
+			detail sql.NullString
+		)
+		if err := rows.Scan(&event.Partition, &event.HolderID, &event.LeaseEpoch, &event.Event, &detail, &event.OccurredAt); err != nil {
+			return nil, err
+		}
+		event.Detail = detail.String
+		event.OccurredAt = normalizeDBTime(event.OccurredAt)
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func scanQueueDepths(rows *sql.Rows) (map[string]int, error) {
+	depths := make(map[string]int)
+	for rows.Next() {
+		var (
+			target string
+			count  int
+		)
+		if err := rows.Scan(&target, &count); err != nil {
+			return nil, err
+		}
+		depths[target] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return depths, nil
+}
+
+func scanDueIntents(rows *sql.Rows) ([]dueIntentRow, error) {
+	due := []dueIntentRow{}
+	for rows.Next() {
+		var row dueIntentRow
+		if err := rows.Scan(&row.intentID, &row.submissionTarget, &row.due, &row.attemptCount, &row.partitionKey); err != nil {
+			return nil, err
+		}
+		row.due = normalizeDBTime(row.due)
+		due = append(due, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return due, nil
+}
+
+func leaseEventDetail(detail string) string {
+	runes := []rune(detail)
+	if len(runes) <= maxLeaseEventDetail {
+		return detail
+	}
+	return string(runes[:maxLeaseEventDetail])
+}
@@ -5,12 +5,14 @@ import (
 	"database/sql"
 	"errors"
 	"time"
+
+	"gateway/submission"
 )
 
 func (s *sqlStore) loadAttempts(ctx context.Context, intentID string) ([]Attempt, error) {
 	rows, err := s.db.QueryContext(
 		ctx,
-		`SELECT attempt_number, started_at, finished_at, outcome_status, outcome_reason, error
+		`SELECT attempt_number, started_at, finished_at, outcome_status, outcome_reason, error, error_class
      FROM dbo.submission_attempts
      WHERE intent_id = @p1
      ORDER BY attempt_number`,
@@ -20,38 +22,12 @@ func (s *sqlStore) loadAttempts(ctx context.Context, intentID string) ([]Attempt
 		return nil, err
 	}
 	defer rows.Close()
-
-	attempts := []Attempt{}
-	for rows.Next() {
-		var (
-			number     int
-			startedAt  time.Time
-			finishedAt time.Time
-			status     sql.NullString
-			reason     sql.NullString
-			attemptErr sql.NullString
-		)
-		if err := rows.Scan(&number, &startedAt, &finishedAt, &status, &reason, &attemptErr); err != nil {
-			return nil, err
-		}
-		attempts = append(attempts, Attempt{
-			Number:     number,
-			StartedAt:  normalizeDBTime(startedAt),
-			FinishedAt: normalizeDBTime(finishedAt),
-			GatewayOutcome: GatewayOutcome{
-				Status: status.String,
-				Reason: reason.String,
-			},
-			Error: attemptErr.String,
-		})
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return attempts, nil
+	return scanAttempts(rows)
 }
 
-func (s *sqlStore) recordAttempt(ctx context.Context, fence LeaseFence, intentID string, attempt Attempt, status IntentStatus, finalOutcome GatewayOutcome, exhaustedReason string, nextAttemptAt *time.Time, now time.Time) (bool, error) {
+// recordAttempt stores the attempt and the evaluated intent state (status, outcome, budget, and retry).
+func (s *sqlStore) recordAttempt(ctx context.Context, fence LeaseFence, intent Intent, attempt Attempt, nextAttemptAt *time.Time, now time.Time) (bool, error) {
+	intentID := intent.IntentID
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return false, err
@@ -81,9 +57,9 @@ func (s *sqlStore) recordAttempt(ctx context.Context, fence LeaseFence, intentID
 	result, err := tx.ExecContext(
 		ctx,
 		`INSERT INTO dbo.submission_attempts (
-      intent_id, attempt_number, started_at, finished_at, outcome_status, outcome_reason, error
+      intent_id, attempt_number, started_at, finished_at, outcome_status, outcome_reason, error, error_class
     )
-    SELECT @p1, @p2, @p3, @p4, @p5, @p6, @p7
+    SELECT @p1, @p2, @p3, @p4, @p5, @p6, @p7, @p11
     WHERE EXISTS (
       SELECT 1
       FROM dbo.submission_manager_leases
@@ -102,6 +78,7 @@ func (s *sqlStore) recordAttempt(ctx context.Context, fence LeaseFence, intentID
 		fence.LeaseName,
 		fence.HolderID,
 		fence.LeaseEpoch,
+		nullString(string(attempt.ErrorClass)),
 	)
 	if err != nil {
 		return false, err
@@ -114,21 +91,7 @@ func (s *sqlStore) recordAttempt(ctx context.Context, fence LeaseFence, intentID
 		return false, nil
 	}
 
-	var nextAttemptValue sql.NullTime
-	if nextAttemptAt != nil {
-		nextAttemptValue = sql.NullTime{Time: nextAttemptAt.UTC(), Valid: true}
-	}
-
-	finalStatus := sql.NullString{}
-	finalReason := sql.NullString{}
-	exhausted := sql.NullString{}
-	if status == IntentAccepted || status == IntentRejected {
-		finalStatus = nullString(finalOutcome.Status)
-		finalReason = nullString(finalOutcome.Reason)
-	} else if status == IntentExhausted {
-		exhausted = nullString(exhaustedReason)
-	}
-
+	state := attemptIntentState(intent, nextAttemptAt)
 	now = now.UTC()
 	result, err = tx.ExecContext(
 		ctx,
@@ -140,6 +103,8 @@ func (s *sqlStore) recordAttempt(ctx context.Context, fence LeaseFence, intentID
          exhausted_reason = @p5,
          next_attempt_at = @p6,
          updated_at = @p7,
+         prior_send_unresolved = @p12,
+         charged_attempt_count = @p13,
          last_modified_at = SYSUTCDATETIME()
      WHERE intent_id = @p8
        AND EXISTS (
@@ -151,16 +116,18 @@ func (s *sqlStore) recordAttempt(ctx context.Context, fence LeaseFence, intentID
            AND expires_at > SYSUTCDATETIME()
        )`,
 		attemptNumber,
-		string(status),
-		finalStatus,
-		finalReason,
-		exhausted,
-		nextAttemptValue,
+		string(intent.Status),
+		state.finalStatus,
+		state.finalReason,
+		state.exhausted,
+		state.nextAttemptAt,
 		now,
 		intentID,
 		fence.LeaseName,
 		fence.HolderID,
 		fence.LeaseEpoch,
+		intent.PriorSendUnresolved,
+		intent.ChargedAttempts,
 	)
 	if err != nil {
 		return false, err
@@ -173,8 +140,68 @@ func (s *sqlStore) recordAttempt(ctx context.Context, fence LeaseFence, intentID
 		return false, nil
 	}
 
+	// The recorded attempt resolves its claim.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dbo.submission_attempt_claims WHERE intent_id = @p1`, intentID); err != nil {
+		return false, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return false, err
 	}
 	return true, nil
 }
+
+func scanAttempts(rows *sql.Rows) ([]Attempt, error) {
+	attempts := []Attempt{}
+	for rows.Next() {
+		var (
+			number     int
+			startedAt  time.Time
+			finishedAt time.Time
+			status     sql.NullString
+			reason     sql.NullString
+			attemptErr sql.NullString
+			errorClass sql.NullString
+		)
+		if err := rows.Scan(&number, &startedAt, &finishedAt, &status, &reason, &attemptErr, &errorClass); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, Attempt{
+			Number:     number,
+			StartedAt:  normalizeDBTime(startedAt),
+			FinishedAt: normalizeDBTime(finishedAt),
+			GatewayOutcome: GatewayOutcome{
+				Status: status.String,
+				Reason: reason.String,
+			},
+			Error:      attemptErr.String,
+			ErrorClass: submission.AttemptErrorClass(errorClass.String),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// attemptIntentStateValues are the nullable intent columns written after an attempt.
+type attemptIntentStateValues struct {
+	finalStatus   sql.NullString
+	finalReason   sql.NullString
+	exhausted     sql.NullString
+	nextAttemptAt sql.NullTime
+}
+
+func attemptIntentState(intent Intent, nextAttemptAt *time.Time) attemptIntentStateValues {
+	var state attemptIntentStateValues
+	if nextAttemptAt != nil {
+		state.nextAttemptAt = sql.NullTime{Time: nextAttemptAt.UTC(), Valid: true}
+	}
+	if intent.Status == IntentAccepted || intent.Status == IntentRejected {
+		state.finalStatus = nullString(intent.FinalOutcome.Status)
+		state.finalReason = nullString(intent.FinalOutcome.Reason)
+	} else if intent.Status == IntentExhausted {
+		state.exhausted = nullString(intent.ExhaustedReason)
+	}
+	return state
+}
@@ -0,0 +1,88 @@
+package submissionmanager
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// attemptClaim is an attempt that was about to call the gateway and has not been recorded.
+type attemptClaim struct {
+	intentID      string
+	attemptNumber int
+	holderID      string
+	leaseEpoch    int64
+	claimedAt     time.Time
+}
+
+// claimAttempt writes the intent's attempt claim, fenced by the partition lease. It reports false
+// when the lease is no longer held or an unresolved claim already exists for the intent.
+func (s *sqlStore) claimAttempt(ctx context.Context, fence LeaseFence, intentID string, attemptNumber int, now time.Time) (bool, error) {
+	result, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO dbo.submission_attempt_claims (intent_id, attempt_number, holder_id, lease_epoch, claimed_at)
+    SELECT @p1, @p2, @p3, @p4, @p5
+    WHERE EXISTS (
+      SELECT 1
+      FROM dbo.submission_manager_leases
+      WHERE lease_name = @p6
+        AND holder_id = @p3
+        AND lease_epoch = @p4
+        AND expires_at > SYSUTCDATETIME()
+    )
+      AND NOT EXISTS (
+        SELECT 1 FROM dbo.submission_attempt_claims WHERE intent_id = @p1
+      )`,
+		intentID,
+		attemptNumber,
+		fence.HolderID,
+		fence.LeaseEpoch,
+		now.UTC(),
+		fence.LeaseName,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// loadAttemptClaims loads the unresolved claims of pending intents in one partition out of count.
+func (s *sqlStore) loadAttemptClaims(ctx context.Context, partition, count int) ([]attemptClaim, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT c.intent_id, c.attempt_number, c.holder_id, c.lease_epoch, c.claimed_at
+     FROM dbo.submission_attempt_claims c
+     JOIN dbo.submission_intents i ON i.intent_id = c.intent_id
+     WHERE i.status = @p1
+       AND i.partition_key % @p2 = @p3
+     ORDER BY c.claimed_at, c.intent_id`,
+		string(IntentPending),
+		max(count, 1),
+		partition,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanAttemptClaims(rows)
+}
+
+func scanAttemptClaims(rows *sql.Rows) ([]attemptClaim, error) {
+	claims := []attemptClaim{}
+	for rows.Next() {
+		var claim attemptClaim
+		if err := rows.Scan(&claim.intentID, &claim.attemptNumber, &claim.holderID, &claim.leaseEpoch, &claim.claimedAt); err != nil {
+			return nil, err
+		}
+		claim.claimedAt = normalizeDBTime(claim.claimedAt)
+		claims = append(claims, claim)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
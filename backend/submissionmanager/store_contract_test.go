@@ -0,0 +1,197 @@
+package submissionmanager
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"gateway/submission"
+)
+
+// TestStoreContract runs against whichever database newTestDB provides: SQL Server or SQLite.
+func TestStoreContract(t *testing.T) {
+	db := newTestDB(t)
+	runStoreContract(t, db.store(t))
+}
+
+func TestPostgresStoreContract(t *testing.T) {
+	store, err := NewPostgresStore(newTestPostgresDB(t))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	runStoreContract(t, store)
+}
+
+// runStoreContract checks the semantics every Store must share: idempotent insert, lease acquire
+// and renew, fenced claims and writes, and schedule snapshot and changes.
+func runStoreContract(t *testing.T, store Store) {
+	ctx := context.Background()
+	now, err := store.loadSQLTime(ctx)
+	if err != nil {
+		t.Fatalf("load sql time: %v", err)
+	}
+
+	intent := Intent{
+		IntentID:         "contract-1",
+		SubmissionTarget: "sms.realtime",
+		Payload:          []byte(`{"to":"+1","message":"hello"}`),
+		Contract:         baseContract(submission.PolicyOneShot),
+	}
+	if _, inserted, err := store.insertIntent(ctx, intent, payloadHash(intent.Payload), now); err != nil || !inserted {
+		t.Fatalf("insert intent: inserted=%v err=%v", inserted, err)
+	}
+	replayed, inserted, err := store.insertIntent(ctx, intent, payloadHash(intent.Payload), now)
+	if err != nil || inserted {
+		t.Fatalf("replay intent: inserted=%v err=%v", inserted, err)
+	}
+	if replayed.Status != IntentPending {
+		t.Fatalf("expected replay to return the pending intent, got %q", replayed.Status)
+	}
+	conflicting := intent
+	conflicting.Payload = []byte(`{"to":"+2","message":"hello"}`)
+	var conflict IdempotencyConflictError
+	if _, _, err := store.insertIntent(ctx, conflicting, payloadHash(conflicting.Payload), now); !errors.As(err, &conflict) {
+		t.Fatalf("expected idempotency conflict, got %v", err)
+	}
+
+	cfgA := LeaseConfig{LeaseName: "contract-executor", HolderID: "holder-a", LeaseDuration: time.Minute}
+	cfgB := LeaseConfig{LeaseName: "contract-executor", HolderID: "holder-b", LeaseDuration: time.Minute}
+	leaseA, acquired, err := store.acquireLease(ctx, cfgA)
+	if err != nil || !acquired {
+		t.Fatalf("acquire lease: acquired=%v err=%v", acquired, err)
+	}
+	if _, acquired, err := store.acquireLease(ctx, cfgB); err != nil || acquired {
+		t.Fatalf("expected held lease to block acquire: acquired=%v err=%v", acquired, err)
+	}
+	if _, renewed, err := store.renewLease(ctx, cfgA, leaseA.leaseEpoch); err != nil || !renewed {
+		t.Fatalf("renew lease: renewed=%v err=%v", renewed, err)
+	}
+	if _, renewed, err := store.renewLease(ctx, cfgB, leaseA.leaseEpoch); err != nil || renewed {
+		t.Fatalf("expected renew by non-holder to fail: renewed=%v err=%v", renewed, err)
+	}
+
+	snapshot, err := store.loadScheduleSnapshot(ctx, 0, 1)
+	if err != nil {
+		t.Fatalf("load snapshot: %v", err)
+	}
+	if len(snapshot) != 1 || snapshot[0].intentID != intent.IntentID {
+		t.Fatalf("expected the pending intent in the snapshot, got %+v", snapshot)
+	}
+	cursor := scheduleCursor{lastModified: snapshot[0].lastModified, intentID: snapshot[0].intentID}
+	mark, err := store.loadIntentChangeMark(ctx)
+	if err != nil {
+		t.Fatalf("load change mark: %v", err)
+	}
+	if changed, next, err := store.loadIntentChanges(ctx, mark); err != nil || len(changed) != 0 || next < mark {
+		t.Fatalf("expected no changes at the mark, got %v next=%d mark=%d err=%v", changed, next, mark, err)
+	}
+	depths, err := store.loadQueueDepths(ctx)
+	if err != nil || depths[intent.SubmissionTarget] != 1 {
+		t.Fatalf("expected one scheduled intent for %s, got %v err=%v", intent.SubmissionTarget, depths, err)
+	}
+	due, err := store.loadDueIntents(ctx, 10)
+	if err != nil || len(due) != 1 || due[0].intentID != intent.IntentID || due[0].attemptCount != 0 {
+		t.Fatalf("expected the pending intent as due, got %+v err=%v", due, err)
+	}
+
+	fenceA := LeaseFence{LeaseName: cfgA.LeaseName, HolderID: cfgA.HolderID, LeaseEpoch: leaseA.leaseEpoch}
+	staleFence := fenceA
+	staleFence.LeaseEpoch++
+	attempt := Attempt{StartedAt: now, FinishedAt: now, GatewayOutcome: GatewayOutcome{Status: "accepted"}}
+	finished := intent
+	finished.Status = IntentAccepted
+	finished.FinalOutcome = attempt.GatewayOutcome
+	finished.ChargedAttempts = 1
+	if claimed, err := store.claimAttempt(ctx, staleFence, intent.IntentID, 1, now); err != nil || claimed {
+		t.Fatalf("expected stale fence to reject the claim: claimed=%v err=%v", claimed, err)
+	}
+	if claimed, err := store.claimAttempt(ctx, fenceA, intent.IntentID, 1, now); err != nil || !claimed {
+		t.Fatalf("claim attempt: claimed=%v err=%v", claimed, err)
+	}
+	if claimed, err := store.claimAttempt(ctx, fenceA, intent.IntentID, 1, now); err != nil || claimed {
+		t.Fatalf("expected an unresolved claim to block another: claimed=%v err=%v", claimed, err)
+	}
+	claims, err := store.loadAttemptClaims(ctx, 0, 1)
+	if err != nil || len(claims) != 1 || claims[0].holderID != cfgA.HolderID || claims[0].attemptNumber != 1 {
+		t.Fatalf("expected holder-a's claim for attempt 1, got %+v err=%v", claims, err)
+	}
+	if ok, err := store.recordAttempt(ctx, staleFence, finished, attempt, nil, now); err != nil || ok {
+		t.Fatalf("expected stale fence to reject the write: ok=%v err=%v", ok, err)
+	}
+	if ok, err := store.recordAttempt(ctx, fenceA, finished, attempt, nil, now); err != nil || !ok {
+		t.Fatalf("record attempt: ok=%v err=%v", ok, err)
+	}
+	loaded, found, err := store.loadIntent(ctx, intent.IntentID)
+	if err != nil || !found {
+		t.Fatalf("load intent: found=%v err=%v", found, err)
+	}
+	if loaded.Status != IntentAccepted || len(loaded.Attempts) != 1 {
+		t.Fatalf("expected accepted intent with one attempt, got %q with %d", loaded.Status, len(loaded.Attempts))
+	}
+	if claims, err := store.loadAttemptClaims(ctx, 0, 1); err != nil || len(claims) != 0 {
+		t.Fatalf("expected the recorded attempt to resolve its claim, got %+v err=%v", claims, err)
+	}
+
+	changes, err := store.loadScheduleChanges(ctx, cursor, 1)
+	if err != nil {
+		t.Fatalf("load changes: %v", err)
+	}
+	if len(changes) != 1 || changes[0].status != IntentAccepted || changes[0].due != nil {
+		t.Fatalf("expected the accepted intent as a change, got %+v", changes)
+	}
+	changed, next, err := store.loadIntentChanges(ctx, mark)
+	if err != nil || !slices.Equal(changed, []string{intent.IntentID}) || next <= mark {
+		t.Fatalf("expected the recorded attempt past the mark, got %v next=%d mark=%d err=%v", changed, next, mark, err)
+	}
+	if changed, _, err := store.loadIntentChanges(ctx, next); err != nil || len(changed) != 0 {
+		t.Fatalf("expected no changes past the new mark, got %v err=%v", changed, err)
+	}
+
+	if released, err := store.releaseLease(ctx, cfgA, leaseA.leaseEpoch); err != nil || !released {
+		t.Fatalf("release lease: released=%v err=%v", released, err)
+	}
+	leaseB, acquired, err := store.acquireLease(ctx, cfgB)
+	if err != nil || !acquired {
+		t.Fatalf("acquire released lease: acquired=%v err=%v", acquired, err)
+	}
+	if leaseB.leaseEpoch != leaseA.leaseEpoch+1 {
+		t.Fatalf("expected epoch %d, got %d", leaseA.leaseEpoch+1, leaseB.leaseEpoch)
+	}
+	pending := intent
+	pending.IntentID = "contract-2"
+	if _, inserted, err := store.insertIntent(ctx, pending, payloadHash(pending.Payload), now); err != nil || !inserted {
+		t.Fatalf("insert second intent: inserted=%v err=%v", inserted, err)
+	}
+	if ok, err := store.markExhausted(ctx, fenceA, pending.IntentID, "deadline", now); err != nil || ok {
+		t.Fatalf("expected the previous holder's fence to be rejected: ok=%v err=%v", ok, err)
+	}
+	fenceB := LeaseFence{LeaseName: cfgB.LeaseName, HolderID: cfgB.HolderID, LeaseEpoch: leaseB.leaseEpoch}
+	if ok, err := store.markExhausted(ctx, fenceB, pending.IntentID, "deadline", now); err != nil || !ok {
+		t.Fatalf("mark exhausted: ok=%v err=%v", ok, err)
+	}
+
+	for _, event := range []LeaseEvent{
+		{HolderID: cfgA.HolderID, LeaseEpoch: leaseA.leaseEpoch, Event: leaseEventReleased, Detail: "step_down"},
+		{HolderID: cfgB.HolderID, LeaseEpoch: leaseB.leaseEpoch, Event: leaseEventAcquired},
+	} {
+		if err := store.recordLeaseEvent(ctx, cfgA.LeaseName, event); err != nil {
+			t.Fatalf("record lease event: %v", err)
+		}
+	}
+	if state, err := store.loadDrain(ctx); err != nil || state.Draining {
+		t.Fatalf("expected no drain before one is set: %+v err=%v", state, err)
+	}
+	if _, err := store.saveDrain(ctx, true, "maintenance"); err != nil {
+		t.Fatalf("save drain: %v", err)
+	}
+	if state, err := store.saveDrain(ctx, false, ""); err != nil || state.Draining || state.Reason != "" {
+		t.Fatalf("expected the drain row to be overwritten: %+v err=%v", state, err)
+	}
+
+	events, err := store.loadLeaseEvents(ctx, cfgA.LeaseName, 1)
+	if err != nil || len(events) != 1 || events[0].Event != leaseEventAcquired || events[0].HolderID != cfgB.HolderID {
+		t.Fatalf("expected holder-b's acquire as the newest lease event, got %+v err=%v", events, err)
+	}
+}
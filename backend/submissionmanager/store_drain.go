@@ -0,0 +1,55 @@
+package submissionmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// loadDrain reads the shared drain row; a missing row means not draining.
+func (s *sqlStore) loadDrain(ctx context.Context) (DrainState, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT draining, reason, updated_at
+     FROM dbo.submission_manager_drain
+     WHERE drain_id = 1`,
+	)
+	return scanDrain(row)
+}
+
+// saveDrain writes the shared drain row, stamped with SQL time.
+func (s *sqlStore) saveDrain(ctx context.Context, draining bool, reason string) (DrainState, error) {
+	_, err := s.db.ExecContext(
+		ctx,
+		`UPDATE dbo.submission_manager_drain
+     SET draining = @p1, reason = @p2, updated_at = SYSUTCDATETIME()
+     WHERE drain_id = 1;
+     IF @@ROWCOUNT = 0
+       INSERT INTO dbo.submission_manager_drain (drain_id, draining, reason, updated_at)
+       VALUES (1, @p1, @p2, SYSUTCDATETIME());`,
+		draining,
+		nullString(reason),
+	)
+	if err != nil {
+		return DrainState{}, err
+	}
+	return s.loadDrain(ctx)
+}
+
+func scanDrain(row *sql.Row) (DrainState, error) {
+	var (
+		state     DrainState
+		reason    sql.NullString
+		updatedAt time.Time
+	)
+	if err := row.Scan(&state.Draining, &reason, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return DrainState{}, nil
+		}
+		return DrainState{}, err
+	}
+	state.Reason = reason.String
+	state.UpdatedAt = normalizeDBTime(updatedAt)
+	return state, nil
+}
@@ -62,3 +62,62 @@ func (s *sqlStore) loadSQLTime(ctx context.Context) (time.Time, error) {
 	}
 	return normalizeDBTime(now), nil
 }
+
+// queryIntentChanges reads the mark with markQuery, then the IDs of intents changed from since up to
+// it with changesQuery, which takes since and the mark as parameters. Reading the mark first keeps
+// a change that commits in between for the next call.
+func queryIntentChanges(ctx context.Context, db *sql.DB, markQuery, changesQuery string, since int64) ([]string, int64, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	mark, err := queryIntentChangeMark(ctx, db, markQuery)
+	if err != nil {
+		return nil, since, err
+	}
+	rows, err := db.QueryContext(ctx, changesQuery, since, mark)
+	if err != nil {
+		return nil, since, err
+	}
+	defer rows.Close()
+	var intentIDs []string
+	for rows.Next() {
+		var intentID string
+		if err := rows.Scan(&intentID); err != nil {
+			return nil, since, err
+		}
+		intentIDs = append(intentIDs, intentID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, since, err
+	}
+	return intentIDs, mark, nil
+}
+
+func queryIntentChangeMark(ctx context.Context, db *sql.DB, markQuery string) (int64, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var mark int64
+	err := db.QueryRowContext(ctx, markQuery).Scan(&mark)
+	return mark, err
+}
+
+// sqlServerChangeMark is the oldest row version an open transaction may still commit.
+const sqlServerChangeMark = `SELECT CAST(MIN_ACTIVE_ROWVERSION() AS BIGINT)`
+
+func (s *sqlStore) loadIntentChangeMark(ctx context.Context) (int64, error) {
+	return queryIntentChangeMark(ctx, s.db, sqlServerChangeMark)
+}
+
+func (s *sqlStore) loadIntentChanges(ctx context.Context, since int64) ([]string, int64, error) {
+	return queryIntentChanges(
+		ctx,
+		s.db,
+		sqlServerChangeMark,
+		`SELECT intent_id
+    FROM dbo.submission_intents
+    WHERE change_version >= CAST(@p1 AS BINARY(8))
+      AND change_version < CAST(@p2 AS BINARY(8))`,
+		since,
+	)
+}
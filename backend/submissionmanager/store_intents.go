@@ -13,34 +13,11 @@ import (
 )
 
 func (s *sqlStore) insertIntent(ctx context.Context, intent Intent, payloadHash []byte, now time.Time) (Intent, bool, error) {
-	terminalOutcomes, err := json.Marshal(intent.Contract.TerminalOutcomes)
+	now = now.UTC()
+	args, err := intentInsertArgs(intent, payloadHash, now)
 	if err != nil {
 		return Intent{}, false, err
 	}
-	webhookURL := ""
-	webhookSecretEnv := ""
-	var webhookHeadersJSON []byte
-	var webhookHeadersEnvJSON []byte
-	webhookStatus := ""
-	if intent.Contract.Webhook != nil {
-		webhookURL = intent.Contract.Webhook.URL
-		webhookSecretEnv = intent.Contract.Webhook.SecretEnv
-		if len(intent.Contract.Webhook.Headers) > 0 {
-			webhookHeadersJSON, err = json.Marshal(intent.Contract.Webhook.Headers)
-			if err != nil {
-				return Intent{}, false, err
-			}
-		}
-		if len(intent.Contract.Webhook.HeadersEnv) > 0 {
-			webhookHeadersEnvJSON, err = json.Marshal(intent.Contract.Webhook.HeadersEnv)
-			if err != nil {
-				return Intent{}, false, err
-			}
-		}
-		webhookStatus = webhookPending
-	}
-	now = now.UTC()
-
 	_, err = s.db.ExecContext(
 		ctx,
 		`INSERT INTO dbo.submission_intents (
@@ -53,8 +30,14 @@ func (s *sqlStore) insertIntent(ctx context.Context, intent Intent, payloadHash
       policy,
       max_acceptance_seconds,
       max_attempts,
+      attempt_timeout_seconds,
+      unresolved_send,
+      max_exempt_attempts,
+      budget_exempt_error_classes,
+      budget_exempt_backpressure,
       terminal_outcomes,
       webhook_url,
+      webhook_format,
       webhook_headers,
       webhook_headers_env,
       webhook_secret_env,
@@ -62,6 +45,8 @@ func (s *sqlStore) insertIntent(ctx context.Context, intent Intent, payloadHash
       webhook_attempted_at,
       webhook_delivered_at,
       webhook_error,
+      callback_url,
+      callback_secret_env,
       status,
       final_outcome_status,
       final_outcome_reason,
@@ -70,10 +55,111 @@ func (s *sqlStore) insertIntent(ctx context.Context, intent Intent, payloadHash
       created_at,
       updated_at,
       last_modified_at,
-      next_attempt_at
+      next_attempt_at,
+      trace_parent,
+      trace_state,
+      outcome_rules,
+      error_class_rules,
+      delivery_window,
+      target_alias,
+      gateway_urls,
+      endpoint_strategy
     ) VALUES (
-      @p1, @p2, @p3, @p4, @p5, @p6, @p7, @p8, @p9, @p10, @p11, @p12, @p13, @p14, @p15, @p16, @p17, @p18, @p19, @p20, @p21, @p22, @p23, @p24, @p25, SYSUTCDATETIME(), @p26
+      @p1, @p2, @p3, @p4, @p5, @p6, @p7, @p8, @p9, @p10, @p11, @p12, @p13, @p14, @p15, @p16, @p17, @p18, @p19, @p20, @p21, @p22, @p23, @p24, @p25, @p26, @p27, @p28, @p29, @p30, @p31, @p32, @p33, SYSUTCDATETIME(), @p34, @p35, @p36, @p37, @p38, @p39, @p40, @p41, @p42
     )`,
+		args...,
+	)
+	if err == nil {
+		intent.Status = IntentPending
+		intent.CreatedAt = now
+		return intent, true, nil
+	}
+	if !isUniqueViolation(err) {
+		return Intent{}, false, err
+	}
+
+	existing, ok, err := s.loadIntent(ctx, intent.IntentID)
+	if err != nil {
+		return Intent{}, false, err
+	}
+	if !ok {
+		return Intent{}, false, errors.New("intent already exists but could not be loaded")
+	}
+	return replayIntent(existing, intent)
+}
+
+// intentLastModifiedArg is where last_modified_at falls in intentInsertArgs' column order. SQL
+// Server and PostgreSQL set it in SQL; SQLite binds it there.
+const intentLastModifiedArg = 33
+
+// intentInsertArgs returns the positional insert values for an intent in the column order every
+// store's insert statement uses; last_modified_at is set by the database.
+func intentInsertArgs(intent Intent, payloadHash []byte, now time.Time) ([]any, error) {
+	terminalOutcomes, err := json.Marshal(intent.Contract.TerminalOutcomes)
+	if err != nil {
+		return nil, err
+	}
+	var exemptClassesJSON []byte
+	if len(intent.Contract.BudgetExemptErrorClasses) > 0 {
+		exemptClassesJSON, err = json.Marshal(intent.Contract.BudgetExemptErrorClasses)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var outcomeRulesJSON []byte
+	if len(intent.Contract.OutcomeRules) > 0 {
+		outcomeRulesJSON, err = json.Marshal(intent.Contract.OutcomeRules)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var errorClassRulesJSON []byte
+	if len(intent.Contract.ErrorClassRules) > 0 {
+		errorClassRulesJSON, err = json.Marshal(intent.Contract.ErrorClassRules)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var gatewayURLsJSON []byte
+	if len(intent.Contract.GatewayURLs) > 0 {
+		gatewayURLsJSON, err = json.Marshal(intent.Contract.GatewayURLs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var deliveryWindowJSON []byte
+	if intent.Contract.DeliveryWindow != nil {
+		deliveryWindowJSON, err = json.Marshal(intent.Contract.DeliveryWindow)
+		if err != nil {
+			return nil, err
+		}
+	}
+	webhookURL := ""
+	webhookFormat := ""
+	webhookSecretEnv := ""
+	var webhookHeadersJSON []byte
+	var webhookHeadersEnvJSON []byte
+	webhookStatus := ""
+	if intent.Contract.Webhook != nil {
+		webhookURL = intent.Contract.Webhook.URL
+		webhookFormat = string(intent.Contract.Webhook.Format)
+		webhookSecretEnv = intent.Contract.Webhook.SecretEnv
+		if len(intent.Contract.Webhook.Headers) > 0 {
+			webhookHeadersJSON, err = json.Marshal(intent.Contract.Webhook.Headers)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(intent.Contract.Webhook.HeadersEnv) > 0 {
+			webhookHeadersEnvJSON, err = json.Marshal(intent.Contract.Webhook.HeadersEnv)
+			if err != nil {
+				return nil, err
+			}
+		}
+		webhookStatus = webhookPending
+	}
+
+	return []any{
 		intent.IntentID,
 		intent.SubmissionTarget,
 		intent.Payload,
@@ -83,8 +169,14 @@ func (s *sqlStore) insertIntent(ctx context.Context, intent Intent, payloadHash
 		string(intent.Contract.Policy),
 		nullInt(intent.Contract.MaxAcceptanceSeconds),
 		nullInt(intent.Contract.MaxAttempts),
+		nullInt(intent.Contract.AttemptTimeoutSeconds),
+		nullString(string(intent.Contract.UnresolvedSend)),
+		nullInt(intent.Contract.MaxExemptAttempts),
+		nullString(string(exemptClassesJSON)),
+		intent.Contract.BudgetExemptBackpressure,
 		string(terminalOutcomes),
 		nullString(webhookURL),
+		nullString(webhookFormat),
 		nullString(string(webhookHeadersJSON)),
 		nullString(string(webhookHeadersEnvJSON)),
 		nullString(webhookSecretEnv),
@@ -92,6 +184,8 @@ func (s *sqlStore) insertIntent(ctx context.Context, intent Intent, payloadHash
 		sql.NullTime{},
 		sql.NullTime{},
 		nullString(""),
+		nullString(intent.CallbackURL),
+		nullString(intent.CallbackSecretEnv),
 		string(IntentPending),
 		nullString(""),
 		nullString(""),
@@ -100,64 +194,60 @@ func (s *sqlStore) insertIntent(ctx context.Context, intent Intent, payloadHash
 		now,
 		now,
 		now,
-	)
-	if err == nil {
-		intent.Status = IntentPending
-		intent.CreatedAt = now
-		return intent, true, nil
-	}
-	if !isUniqueViolation(err) {
-		return Intent{}, false, err
-	}
+		nullString(intent.TraceParent),
+		nullString(intent.TraceState),
+		nullString(string(outcomeRulesJSON)),
+		nullString(string(errorClassRulesJSON)),
+		nullString(string(deliveryWindowJSON)),
+		nullString(intent.TargetAlias),
+		nullString(string(gatewayURLsJSON)),
+		nullString(string(intent.Contract.EndpointStrategy)),
+	}, nil
+}
 
-	existing, ok, err := s.loadIntent(ctx, intent.IntentID)
-	if err != nil {
-		return Intent{}, false, err
-	}
-	if !ok {
-		return Intent{}, false, errors.New("intent already exists but could not be loaded")
-	}
-	if existing.SubmissionTarget == intent.SubmissionTarget && bytes.Equal(existing.Payload, intent.Payload) {
+// replayIntent resolves an insert that hit an existing intent id: an identical request returns the
+// stored intent, anything else is an idempotency conflict.
+func replayIntent(existing, intent Intent) (Intent, bool, error) {
+	// Non-obvious constraint: the callback is part of the request identity so a replay cannot silently redirect it.
+	// An alias request is identified by the alias, so a replay still matches after its weights change.
+	if existing.RequestedTarget() == intent.RequestedTarget() && bytes.Equal(existing.Payload, intent.Payload) &&
+		existing.CallbackURL == intent.CallbackURL && existing.CallbackSecretEnv == intent.CallbackSecretEnv {
 		return existing, false, nil
 	}
 
 	return Intent{}, false, IdempotencyConflictError{
 		IntentID:        intent.IntentID,
-		ExistingTarget:  existing.SubmissionTarget,
+		ExistingTarget:  existing.RequestedTarget(),
 		ExistingPayload: string(existing.Payload),
-		IncomingTarget:  intent.SubmissionTarget,
+		IncomingTarget:  intent.RequestedTarget(),
 		IncomingPayload: string(intent.Payload),
 		ExistingStatus:  existing.Status,
 	}
 }
 
-func (s *sqlStore) loadIntent(ctx context.Context, intentID string) (Intent, bool, error) {
-	intent, _, ok, err := s.loadIntentRow(ctx, intentID)
-	if err != nil || !ok {
-		return Intent{}, false, err
-	}
-
-	attempts, err := s.loadAttempts(ctx, intentID)
-	if err != nil {
-		return Intent{}, false, err
-	}
-	intent.Attempts = attempts
-	return intent, true, nil
-}
-
-func (s *sqlStore) loadIntentForExecution(ctx context.Context, intentID string) (Intent, int, bool, error) {
-	row := s.db.QueryRowContext(
-		ctx,
-		`SELECT intent_id,
+// intentColumns is the column list scanIntentRow expects, shared by every store.
+const intentColumns = `intent_id,
       submission_target,
+      target_alias,
       payload,
       gateway_type,
       gateway_url,
+      gateway_urls,
+      endpoint_strategy,
       policy,
       max_acceptance_seconds,
       max_attempts,
+      attempt_timeout_seconds,
+      unresolved_send,
+      max_exempt_attempts,
+      budget_exempt_error_classes,
+      budget_exempt_backpressure,
       terminal_outcomes,
+      outcome_rules,
+      error_class_rules,
+      delivery_window,
       webhook_url,
+      webhook_format,
       webhook_headers,
       webhook_headers_env,
       webhook_secret_env,
@@ -165,14 +255,44 @@ func (s *sqlStore) loadIntentForExecution(ctx context.Context, intentID string)
       webhook_attempted_at,
       webhook_delivered_at,
       webhook_error,
+      webhook_redelivery_count,
+      webhook_redelivery_status,
+      webhook_redelivery_attempted_at,
+      webhook_redelivery_error,
+      callback_url,
+      callback_secret_env,
+      trace_parent,
+      trace_state,
       status,
       final_outcome_status,
       final_outcome_reason,
       exhausted_reason,
       attempt_count,
+      prior_send_unresolved,
+      charged_attempt_count,
+      deferred_ms,
       created_at,
       updated_at,
-      next_attempt_at
+      next_attempt_at`
+
+func (s *sqlStore) loadIntent(ctx context.Context, intentID string) (Intent, bool, error) {
+	intent, _, ok, err := s.loadIntentRow(ctx, intentID)
+	if err != nil || !ok {
+		return Intent{}, false, err
+	}
+
+	attempts, err := s.loadAttempts(ctx, intentID)
+	if err != nil {
+		return Intent{}, false, err
+	}
+	intent.Attempts = attempts
+	return intent, true, nil
+}
+
+func (s *sqlStore) loadIntentForExecution(ctx context.Context, intentID string) (Intent, int, bool, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT `+intentColumns+`
     FROM dbo.submission_intents
     WHERE intent_id = @p1
       AND status = @p2
@@ -182,7 +302,7 @@ func (s *sqlStore) loadIntentForExecution(ctx context.Context, intentID string)
 		string(IntentPending),
 	)
 
-	intent, attemptCount, ok, err := s.scanIntentRow(row)
+	intent, attemptCount, ok, err := scanIntentRow(row)
 	if err != nil || !ok {
 		return Intent{}, 0, ok, err
 	}
@@ -192,50 +312,38 @@ func (s *sqlStore) loadIntentForExecution(ctx context.Context, intentID string)
 func (s *sqlStore) loadIntentRow(ctx context.Context, intentID string) (Intent, int, bool, error) {
 	row := s.db.QueryRowContext(
 		ctx,
-		`SELECT intent_id,
-      submission_target,
-      payload,
-      gateway_type,
-      gateway_url,
-      policy,
-      max_acceptance_seconds,
-      max_attempts,
-      terminal_outcomes,
-      webhook_url,
-      webhook_headers,
-      webhook_headers_env,
-      webhook_secret_env,
-      webhook_status,
-      webhook_attempted_at,
-      webhook_delivered_at,
-      webhook_error,
-      status,
-      final_outcome_status,
-      final_outcome_reason,
-      exhausted_reason,
-      attempt_count,
-      created_at,
-      updated_at,
-      next_attempt_at
+		`SELECT `+intentColumns+`
     FROM dbo.submission_intents
     WHERE intent_id = @p1`,
 		intentID,
 	)
-	return s.scanIntentRow(row)
+	return scanIntentRow(row)
 }
 
-func (s *sqlStore) scanIntentRow(row *sql.Row) (Intent, int, bool, error) {
+func scanIntentRow(row *sql.Row) (Intent, int, bool, error) {
 	var (
 		storedIntentID        string
 		submissionTarget      string
+		targetAlias           sql.NullString
 		payload               []byte
 		gatewayType           string
 		gatewayURL            string
+		gatewayURLsJSON       sql.NullString
+		endpointStrategy      sql.NullString
 		policy                string
 		maxAcceptanceSeconds  sql.NullInt32
 		maxAttempts           sql.NullInt32
+		attemptTimeout        sql.NullInt32
+		unresolvedSend        sql.NullString
+		maxExemptAttempts     sql.NullInt32
+		exemptClassesJSON     sql.NullString
+		exemptBackpressure    bool
 		terminalOutcomesJSON  string
+		outcomeRulesJSON      sql.NullString
+		errorClassRulesJSON   sql.NullString
+		deliveryWindowJSON    sql.NullString
 		webhookURL            sql.NullString
+		webhookFormat         sql.NullString
 		webhookHeadersJSON    sql.NullString
 		webhookHeadersEnvJSON sql.NullString
 		webhookSecretEnv      sql.NullString
@@ -243,11 +351,22 @@ func (s *sqlStore) scanIntentRow(row *sql.Row) (Intent, int, bool, error) {
 		webhookAttemptedAt    sql.NullTime
 		webhookDeliveredAt    sql.NullTime
 		webhookError          sql.NullString
+		redeliveryCount       int
+		redeliveryStatus      sql.NullString
+		redeliveryAttemptedAt sql.NullTime
+		redeliveryError       sql.NullString
+		callbackURL           sql.NullString
+		callbackSecretEnv     sql.NullString
+		traceParent           sql.NullString
+		traceState            sql.NullString
 		status                string
 		finalOutcomeStatus    sql.NullString
 		finalOutcomeReason    sql.NullString
 		exhaustedReason       sql.NullString
 		attemptCount          int
+		priorSendUnresolved   bool
+		chargedAttempts       int
+		deferredMillis        int64
 		createdAt             time.Time
 		updatedAt             time.Time
 		nextAttemptAt         sql.NullTime
@@ -256,14 +375,26 @@ func (s *sqlStore) scanIntentRow(row *sql.Row) (Intent, int, bool, error) {
 	if err := row.Scan(
 		&storedIntentID,
 		&submissionTarget,
+		&targetAlias,
 		&payload,
 		&gatewayType,
 		&gatewayURL,
+		&gatewayURLsJSON,
+		&endpointStrategy,
 		&policy,
 		&maxAcceptanceSeconds,
 		&maxAttempts,
+		&attemptTimeout,
+		&unresolvedSend,
+		&maxExemptAttempts,
+		&exemptClassesJSON,
+		&exemptBackpressure,
 		&terminalOutcomesJSON,
+		&outcomeRulesJSON,
+		&errorClassRulesJSON,
+		&deliveryWindowJSON,
 		&webhookURL,
+		&webhookFormat,
 		&webhookHeadersJSON,
 		&webhookHeadersEnvJSON,
 		&webhookSecretEnv,
@@ -271,11 +402,22 @@ func (s *sqlStore) scanIntentRow(row *sql.Row) (Intent, int, bool, error) {
 		&webhookAttemptedAt,
 		&webhookDeliveredAt,
 		&webhookError,
+		&redeliveryCount,
+		&redeliveryStatus,
+		&redeliveryAttemptedAt,
+		&redeliveryError,
+		&callbackURL,
+		&callbackSecretEnv,
+		&traceParent,
+		&traceState,
 		&status,
 		&finalOutcomeStatus,
 		&finalOutcomeReason,
 		&exhaustedReason,
 		&attemptCount,
+		&priorSendUnresolved,
+		&chargedAttempts,
+		&deferredMillis,
 		&createdAt,
 		&updatedAt,
 		&nextAttemptAt,
@@ -290,12 +432,47 @@ func (s *sqlStore) scanIntentRow(row *sql.Row) (Intent, int, bool, error) {
 	if err := json.Unmarshal([]byte(terminalOutcomesJSON), &terminalOutcomes); err != nil {
 		return Intent{}, 0, false, err
 	}
+	var exemptClasses []submission.AttemptErrorClass
+	if exemptClassesJSON.Valid && strings.TrimSpace(exemptClassesJSON.String) != "" {
+		if err := json.Unmarshal([]byte(exemptClassesJSON.String), &exemptClasses); err != nil {
+			return Intent{}, 0, false, err
+		}
+	}
+	var outcomeRules map[string]submission.RetryRule
+	if outcomeRulesJSON.Valid && strings.TrimSpace(outcomeRulesJSON.String) != "" {
+		if err := json.Unmarshal([]byte(outcomeRulesJSON.String), &outcomeRules); err != nil {
+			return Intent{}, 0, false, err
+		}
+	}
+	var errorClassRules map[submission.AttemptErrorClass]submission.RetryRule
+	if errorClassRulesJSON.Valid && strings.TrimSpace(errorClassRulesJSON.String) != "" {
+		if err := json.Unmarshal([]byte(errorClassRulesJSON.String), &errorClassRules); err != nil {
+			return Intent{}, 0, false, err
+		}
+	}
+	var gatewayURLs []string
+	if gatewayURLsJSON.Valid && strings.TrimSpace(gatewayURLsJSON.String) != "" {
+		if err := json.Unmarshal([]byte(gatewayURLsJSON.String), &gatewayURLs); err != nil {
+			return Intent{}, 0, false, err
+		}
+	}
+	var deliveryWindow *submission.DeliveryWindow
+	if deliveryWindowJSON.Valid && strings.TrimSpace(deliveryWindowJSON.String) != "" {
+		if err := json.Unmarshal([]byte(deliveryWindowJSON.String), &deliveryWindow); err != nil {
+			return Intent{}, 0, false, err
+		}
+	}
 	var webhook *submission.WebhookConfig
 	if webhookURL.Valid {
 		webhook = &submission.WebhookConfig{
 			URL:       webhookURL.String,
+			Format:    submission.WebhookFormat(strings.TrimSpace(webhookFormat.String)),
 			SecretEnv: webhookSecretEnv.String,
 		}
+		// Non-obvious constraint: rows written before formats existed have no format and use the Setu envelope.
+		if webhook.Format == "" {
+			webhook.Format = submission.WebhookFormatSetu
+		}
 		if webhookHeadersJSON.Valid && strings.TrimSpace(webhookHeadersJSON.String) != "" {
 			var headers map[string]string
 			if err := json.Unmarshal([]byte(webhookHeadersJSON.String), &headers); err != nil {
@@ -315,26 +492,47 @@ func (s *sqlStore) scanIntentRow(row *sql.Row) (Intent, int, bool, error) {
 	intent := Intent{
 		IntentID:         storedIntentID,
 		SubmissionTarget: submissionTarget,
+		TargetAlias:      targetAlias.String,
 		Payload:          payload,
 		CreatedAt:        normalizeDBTime(createdAt),
 		Status:           IntentStatus(status),
 		Contract: submission.TargetContract{
-			SubmissionTarget:     submissionTarget,
-			GatewayType:          submission.GatewayType(strings.TrimSpace(gatewayType)),
-			GatewayURL:           gatewayURL,
-			Policy:               submission.ContractPolicy(strings.TrimSpace(policy)),
-			MaxAcceptanceSeconds: int(maxAcceptanceSeconds.Int32),
-			MaxAttempts:          int(maxAttempts.Int32),
-			TerminalOutcomes:     terminalOutcomes,
-			Webhook:              webhook,
+			SubmissionTarget:         submissionTarget,
+			GatewayType:              submission.GatewayType(strings.TrimSpace(gatewayType)),
+			GatewayURL:               gatewayURL,
+			GatewayURLs:              gatewayURLs,
+			EndpointStrategy:         submission.EndpointStrategy(endpointStrategy.String),
+			Policy:                   submission.ContractPolicy(strings.TrimSpace(policy)),
+			MaxAcceptanceSeconds:     int(maxAcceptanceSeconds.Int32),
+			MaxAttempts:              int(maxAttempts.Int32),
+			AttemptTimeoutSeconds:    int(attemptTimeout.Int32),
+			UnresolvedSend:           submission.UnresolvedSend(unresolvedSend.String),
+			MaxExemptAttempts:        int(maxExemptAttempts.Int32),
+			BudgetExemptErrorClasses: exemptClasses,
+			BudgetExemptBackpressure: exemptBackpressure,
+			TerminalOutcomes:         terminalOutcomes,
+			OutcomeRules:             outcomeRules,
+			ErrorClassRules:          errorClassRules,
+			DeliveryWindow:           deliveryWindow,
+			Webhook:                  webhook,
 		},
 		FinalOutcome: GatewayOutcome{
 			Status: finalOutcomeStatus.String,
 			Reason: finalOutcomeReason.String,
 		},
-		ExhaustedReason: exhaustedReason.String,
-		WebhookStatus:   webhookStatus.String,
-		WebhookError:    webhookError.String,
+		ExhaustedReason:         exhaustedReason.String,
+		WebhookStatus:           webhookStatus.String,
+		WebhookError:            webhookError.String,
+		WebhookRedeliveryCount:  redeliveryCount,
+		WebhookRedeliveryStatus: redeliveryStatus.String,
+		WebhookRedeliveryError:  redeliveryError.String,
+		CallbackURL:             callbackURL.String,
+		CallbackSecretEnv:       callbackSecretEnv.String,
+		PriorSendUnresolved:     priorSendUnresolved,
+		ChargedAttempts:         chargedAttempts,
+		DeferredFor:             time.Duration(deferredMillis) * time.Millisecond,
+		TraceParent:             traceParent.String,
+		TraceState:              traceState.String,
 	}
 	if webhookAttemptedAt.Valid {
 		intent.WebhookAttemptedAt = normalizeDBTime(webhookAttemptedAt.Time)
@@ -342,9 +540,14 @@ func (s *sqlStore) scanIntentRow(row *sql.Row) (Intent, int, bool, error) {
 	if webhookDeliveredAt.Valid {
 		intent.WebhookDeliveredAt = normalizeDBTime(webhookDeliveredAt.Time)
 	}
+	if redeliveryAttemptedAt.Valid {
+		intent.WebhookRedeliveryAttemptedAt = normalizeDBTime(redeliveryAttemptedAt.Time)
+	}
 
 	if intent.Status != IntentPending {
 		intent.CompletedAt = normalizeDBTime(updatedAt)
+	} else {
+		intent.ScheduledAt = normalizeDBTime(updatedAt)
 	}
 	if nextAttemptAt.Valid {
 		_ = normalizeDBTime(nextAttemptAt.Time)
@@ -391,3 +594,41 @@ func (s *sqlStore) markExhausted(ctx context.Context, fence LeaseFence, intentID
 	}
 	return affected > 0, nil
 }
+
+// deferAttempt moves a pending intent's next attempt to a delivery window opening without recording
+// an attempt, and stores the accumulated deferral.
+func (s *sqlStore) deferAttempt(ctx context.Context, fence LeaseFence, intentID string, nextAttemptAt time.Time, deferredFor time.Duration, now time.Time) (bool, error) {
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE dbo.submission_intents
+     SET next_attempt_at = @p1,
+         deferred_ms = @p2,
+         updated_at = @p3,
+         last_modified_at = SYSUTCDATETIME()
+     WHERE intent_id = @p4 AND status = @p5
+       AND EXISTS (
+         SELECT 1
+         FROM dbo.submission_manager_leases
+         WHERE lease_name = @p6
+           AND holder_id = @p7
+           AND lease_epoch = @p8
+           AND expires_at > SYSUTCDATETIME()
+       )`,
+		nextAttemptAt.UTC(),
+		deferredFor.Milliseconds(),
+		now.UTC(),
+		intentID,
+		string(IntentPending),
+		fence.LeaseName,
+		fence.HolderID,
+		fence.LeaseEpoch,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,684 @@
+package submissionmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// postgresStore is the PostgreSQL store (conf/sql/submissionmanager/postgres). Timestamps are UTC
+// wall-clock values and utc_now() stands in for SYSUTCDATETIME(), so both stores share the same
+// time handling. Fenced writes take a FOR SHARE lock on the lease row, so a takeover waits for an
+// in-progress fenced write to commit instead of interleaving with it.
+//
+// Schedule and claim reads lock no intent rows, so SKIP LOCKED only appears in lease acquisition,
+// where instances do compete. A partition has one fenced holder, so no two executors read its
+// intents to run them, and a holder that lost its lease fails the fence on its next write.
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore returns a Store backed by PostgreSQL. The caller opens db with a registered
+// PostgreSQL driver.
+func NewPostgresStore(db *sql.DB) (Store, error) {
+	if db == nil {
+		return nil, errors.New("db is required")
+	}
+	return &postgresStore{db: db}, nil
+}
+
+func (s *postgresStore) loadSQLTime(ctx context.Context) (time.Time, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	row := s.db.QueryRowContext(ctx, `SELECT utc_now()`)
+	var now time.Time
+	if err := row.Scan(&now); err != nil {
+		return time.Time{}, err
+	}
+	return normalizeDBTime(now), nil
+}
+
+func (s *postgresStore) insertIntent(ctx context.Context, intent Intent, payloadHash []byte, now time.Time) (Intent, bool, error) {
+	now = now.UTC()
+	args, err := intentInsertArgs(intent, payloadHash, now)
+	if err != nil {
+		return Intent{}, false, err
+	}
+	// Non-obvious constraint: SQL Server computes partition_key itself; here the store writes the same hash.
+	args = append(args, partitionKey(intent.IntentID))
+	result, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO submission_intents (
+      intent_id,
+      submission_target,
+      payload,
+      payload_hash,
+      gateway_type,
+      gateway_url,
+      policy,
+      max_acceptance_seconds,
+      max_attempts,
+      attempt_timeout_seconds,
+      unresolved_send,
+      max_exempt_attempts,
+      budget_exempt_error_classes,
+      budget_exempt_backpressure,
+      terminal_outcomes,
+      webhook_url,
+      webhook_format,
+      webhook_headers,
+      webhook_headers_env,
+      webhook_secret_env,
+      webhook_status,
+      webhook_attempted_at,
+      webhook_delivered_at,
+      webhook_error,
+      callback_url,
+      callback_secret_env,
+      status,
+      final_outcome_status,
+      final_outcome_reason,
+      exhausted_reason,
+      attempt_count,
+      created_at,
+      updated_at,
+      last_modified_at,
+      next_attempt_at,
+      trace_parent,
+      trace_state,
+      outcome_rules,
+      error_class_rules,
+      delivery_window,
+      target_alias,
+      gateway_urls,
+      endpoint_strategy,
+      partition_key
+    ) VALUES (
+      $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, utc_now(), $34, $35, $36, $37, $38, $39, $40, $41, $42, $43
+    )
+    ON CONFLICT (intent_id) DO NOTHING`,
+		args...,
+	)
+	if err != nil {
+		return Intent{}, false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return Intent{}, false, err
+	}
+	if affected > 0 {
+		intent.Status = IntentPending
+		intent.CreatedAt = now
+		return intent, true, nil
+	}
+
+	existing, ok, err := s.loadIntent(ctx, intent.IntentID)
+	if err != nil {
+		return Intent{}, false, err
+	}
+	if !ok {
+		return Intent{}, false, errors.New("intent already exists but could not be loaded")
+	}
+	return replayIntent(existing, intent)
+}
+
+func (s *postgresStore) loadIntent(ctx context.Context, intentID string) (Intent, bool, error) {
+	intent, _, ok, err := s.loadIntentRow(ctx, intentID)
+	if err != nil || !ok {
+		return Intent{}, false, err
+	}
+
+	attempts, err := s.loadAttempts(ctx, intentID)
+	if err != nil {
+		return Intent{}, false, err
+	}
+	intent.Attempts = attempts
+	return intent, true, nil
+}
+
+func (s *postgresStore) loadIntentRow(ctx context.Context, intentID string) (Intent, int, bool, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT `+intentColumns+`
+    FROM submission_intents
+    WHERE intent_id = $1`,
+		intentID,
+	)
+	return scanIntentRow(row)
+}
+
+// postgresChangeMark is the oldest transaction still running; every change_xid below it is committed.
+const postgresChangeMark = `SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint`
+
+func (s *postgresStore) loadIntentChangeMark(ctx context.Context) (int64, error) {
+	return queryIntentChangeMark(ctx, s.db, postgresChangeMark)
+}
+
+func (s *postgresStore) loadIntentChanges(ctx context.Context, since int64) ([]string, int64, error) {
+	return queryIntentChanges(
+		ctx,
+		s.db,
+		postgresChangeMark,
+		`SELECT intent_id
+    FROM submission_intents
+    WHERE change_xid >= $1::bigint::text::xid8
+      AND change_xid < $2::bigint::text::xid8`,
+		since,
+	)
+}
+
+func (s *postgresStore) loadIntentForExecution(ctx context.Context, intentID string) (Intent, int, bool, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT `+intentColumns+`
+    FROM submission_intents
+    WHERE intent_id = $1
+      AND status = $2
+      AND next_attempt_at IS NOT NULL
+      AND next_attempt_at <= utc_now()`,
+		intentID,
+		string(IntentPending),
+	)
+	return scanIntentRow(row)
+}
+
+func (s *postgresStore) deferAttempt(ctx context.Context, fence LeaseFence, intentID string, nextAttemptAt time.Time, deferredFor time.Duration, now time.Time) (bool, error) {
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_intents
+     SET next_attempt_at = $1,
+         deferred_ms = $2,
+         updated_at = $3,
+         last_modified_at = utc_now()
+     WHERE intent_id = $4 AND status = $5
+       AND EXISTS (
+         SELECT 1
+         FROM submission_manager_leases
+         WHERE lease_name = $6
+           AND holder_id = $7
+           AND lease_epoch = $8
+           AND expires_at > utc_now()
+         FOR SHARE
+       )`,
+		nextAttemptAt.UTC(),
+		deferredFor.Milliseconds(),
+		now.UTC(),
+		intentID,
+		string(IntentPending),
+		fence.LeaseName,
+		fence.HolderID,
+		fence.LeaseEpoch,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *postgresStore) markExhausted(ctx context.Context, fence LeaseFence, intentID string, exhaustedReason string, now time.Time) (bool, error) {
+	now = now.UTC()
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_intents
+     SET status = $1,
+         final_outcome_status = NULL,
+         final_outcome_reason = NULL,
+         exhausted_reason = $2,
+         next_attempt_at = NULL,
+         updated_at = $3,
+         last_modified_at = utc_now()
+     WHERE intent_id = $4 AND status = $5
+       AND EXISTS (
+         SELECT 1
+         FROM submission_manager_leases
+         WHERE lease_name = $6
+           AND holder_id = $7
+           AND lease_epoch = $8
+           AND expires_at > utc_now()
+         FOR SHARE
+       )`,
+		string(IntentExhausted),
+		nullString(exhaustedReason),
+		now,
+		intentID,
+		string(IntentPending),
+		fence.LeaseName,
+		fence.HolderID,
+		fence.LeaseEpoch,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *postgresStore) loadAttempts(ctx context.Context, intentID string) ([]Attempt, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT attempt_number, started_at, finished_at, outcome_status, outcome_reason, error, error_class
+     FROM submission_attempts
+     WHERE intent_id = $1
+     ORDER BY attempt_number`,
+		intentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanAttempts(rows)
+}
+
+// recordAttempt stores the attempt and the evaluated intent state. The intent row is locked for the
+// transaction so attempt numbers cannot race.
+func (s *postgresStore) recordAttempt(ctx context.Context, fence LeaseFence, intent Intent, attempt Attempt, nextAttemptAt *time.Time, now time.Time) (bool, error) {
+	intentID := intent.IntentID
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var currentStatus string
+	var attemptCount int
+	row := tx.QueryRowContext(ctx, `SELECT status, attempt_count FROM submission_intents WHERE intent_id = $1 FOR UPDATE`, intentID)
+	if err = row.Scan(&currentStatus, &attemptCount); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, errors.New("intent not found")
+		}
+		return false, err
+	}
+	if currentStatus != string(IntentPending) {
+		return false, nil
+	}
+
+	attemptNumber := attemptCount + 1
+	// Non-obvious constraint: INSERT ... SELECT parameters need explicit types; PostgreSQL infers text otherwise.
+	result, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO submission_attempts (
+      intent_id, attempt_number, started_at, finished_at, outcome_status, outcome_reason, error, error_class
+    )
+    SELECT $1::varchar, $2::integer, $3::timestamp, $4::timestamp, $5::varchar, $6::varchar, $7::varchar, $11::varchar
+    WHERE EXISTS (
+      SELECT 1
+      FROM submission_manager_leases
+      WHERE lease_name = $8
+        AND holder_id = $9
+        AND lease_epoch = $10
+        AND expires_at > utc_now()
+      FOR SHARE
+    )`,
+		intentID,
+		attemptNumber,
+		attempt.StartedAt.UTC(),
+		attempt.FinishedAt.UTC(),
+		nullString(attempt.GatewayOutcome.Status),
+		nullString(attempt.GatewayOutcome.Reason),
+		nullString(attempt.Error),
+		fence.LeaseName,
+		fence.HolderID,
+		fence.LeaseEpoch,
+		nullString(string(attempt.ErrorClass)),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	state := attemptIntentState(intent, nextAttemptAt)
+	result, err = tx.ExecContext(
+		ctx,
+		`UPDATE submission_intents
+     SET attempt_count = $1,
+         status = $2,
+         final_outcome_status = $3,
+         final_outcome_reason = $4,
+         exhausted_reason = $5,
+         next_attempt_at = $6,
+         updated_at = $7,
+         prior_send_unresolved = $12,
+         charged_attempt_count = $13,
+         last_modified_at = utc_now()
+     WHERE intent_id = $8
+       AND EXISTS (
+         SELECT 1
+         FROM submission_manager_leases
+         WHERE lease_name = $9
+           AND holder_id = $10
+           AND lease_epoch = $11
+           AND expires_at > utc_now()
+         FOR SHARE
+       )`,
+		attemptNumber,
+		string(intent.Status),
+		state.finalStatus,
+		state.finalReason,
+		state.exhausted,
+		state.nextAttemptAt,
+		now.UTC(),
+		intentID,
+		fence.LeaseName,
+		fence.HolderID,
+		fence.LeaseEpoch,
+		intent.PriorSendUnresolved,
+		intent.ChargedAttempts,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err = result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	// The recorded attempt resolves its claim.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM submission_attempt_claims WHERE intent_id = $1`, intentID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *postgresStore) loadScheduleSnapshot(ctx context.Context, partition, count int) ([]scheduleSnapshotRow, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT intent_id, next_attempt_at, last_modified_at
+     FROM submission_intents
+     WHERE status = $1 AND next_attempt_at IS NOT NULL
+       AND partition_key % $2 = $3
+     ORDER BY last_modified_at, intent_id`,
+		string(IntentPending),
+		max(count, 1),
+		partition,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanScheduleSnapshot(rows)
+}
+
+func (s *postgresStore) loadScheduleChanges(ctx context.Context, cursor scheduleCursor, count int) ([]scheduleChangeRow, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT intent_id, status, next_attempt_at, last_modified_at
+     FROM submission_intents
+     WHERE ((last_modified_at > $1) OR (last_modified_at = $1 AND intent_id > $2))
+       AND partition_key % $3 = $4
+     ORDER BY last_modified_at, intent_id`,
+		cursor.lastModified,
+		cursor.intentID,
+		max(count, 1),
+		cursor.partition,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanScheduleChanges(rows)
+}
+
+func (s *postgresStore) recordWebhookAttempt(ctx context.Context, fence LeaseFence, intentID string, status string, attemptedAt time.Time, errMsg string) (bool, error) {
+	attemptedAt = attemptedAt.UTC()
+	var deliveredAt sql.NullTime
+	if status == webhookDelivered {
+		deliveredAt = sql.NullTime{Time: attemptedAt, Valid: true}
+	}
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_intents
+     SET webhook_status = $1,
+         webhook_attempted_at = $2,
+         webhook_delivered_at = $3,
+         webhook_error = $4,
+         updated_at = $5,
+         last_modified_at = utc_now()
+     WHERE intent_id = $6 AND webhook_status = $7
+       AND EXISTS (
+         SELECT 1
+         FROM submission_manager_leases
+         WHERE lease_name = $8
+           AND holder_id = $9
+           AND lease_epoch = $10
+           AND expires_at > utc_now()
+         FOR SHARE
+       )`,
+		status,
+		attemptedAt,
+		deliveredAt,
+		nullString(errMsg),
+		attemptedAt,
+		intentID,
+		webhookPending,
+		fence.LeaseName,
+		fence.HolderID,
+		fence.LeaseEpoch,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *postgresStore) recordWebhookRedelivery(ctx context.Context, intentID string, status string, attemptedAt time.Time, errMsg string) error {
+	attemptedAt = attemptedAt.UTC()
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_intents
+     SET webhook_redelivery_count = webhook_redelivery_count + 1,
+         webhook_redelivery_status = $1,
+         webhook_redelivery_attempted_at = $2,
+         webhook_redelivery_error = $3,
+         last_modified_at = utc_now()
+     WHERE intent_id = $4 AND status <> $5`,
+		status,
+		attemptedAt,
+		nullString(errMsg),
+		intentID,
+		string(IntentPending),
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.New("intent not found or not terminal")
+	}
+	return nil
+}
+
+// claimAttempt writes the intent's attempt claim, fenced by the partition lease.
+func (s *postgresStore) claimAttempt(ctx context.Context, fence LeaseFence, intentID string, attemptNumber int, now time.Time) (bool, error) {
+	result, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO submission_attempt_claims (intent_id, attempt_number, holder_id, lease_epoch, claimed_at)
+    SELECT $1::varchar, $2::integer, $3::varchar, $4::bigint, $5::timestamp
+    WHERE EXISTS (
+      SELECT 1
+      FROM submission_manager_leases
+      WHERE lease_name = $6
+        AND holder_id = $3
+        AND lease_epoch = $4
+        AND expires_at > utc_now()
+      FOR SHARE
+    )
+    ON CONFLICT (intent_id) DO NOTHING`,
+		intentID,
+		attemptNumber,
+		fence.HolderID,
+		fence.LeaseEpoch,
+		now.UTC(),
+		fence.LeaseName,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *postgresStore) loadAttemptClaims(ctx context.Context, partition, count int) ([]attemptClaim, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT c.intent_id, c.attempt_number, c.holder_id, c.lease_epoch, c.claimed_at
+     FROM submission_attempt_claims c
+     JOIN submission_intents i ON i.intent_id = c.intent_id
+     WHERE i.status = $1
+       AND i.partition_key % $2 = $3
+     ORDER BY c.claimed_at, c.intent_id`,
+		string(IntentPending),
+		max(count, 1),
+		partition,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanAttemptClaims(rows)
+}
+
+// recordLeaseEvent appends a lease transition to the history and drops events past retention.
+func (s *postgresStore) recordLeaseEvent(ctx context.Context, leaseName string, event LeaseEvent) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO submission_manager_lease_events (
+      lease_name, partition_number, holder_id, lease_epoch, event_type, detail, occurred_at
+    ) VALUES ($1, $2, $3, $4, $5, $6, utc_now())`,
+		leaseName,
+		event.Partition,
+		event.HolderID,
+		event.LeaseEpoch,
+		event.Event,
+		nullString(leaseEventDetail(event.Detail)),
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM submission_manager_lease_events
+     WHERE occurred_at < utc_now() - ($1::bigint * INTERVAL '1 second')`,
+		int64(leaseEventRetention.Seconds()),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *postgresStore) loadLeaseEvents(ctx context.Context, leaseName string, limit int) ([]LeaseEvent, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT partition_number, holder_id, lease_epoch, event_type, detail, occurred_at
+     FROM submission_manager_lease_events
+     WHERE lease_name = $1
+     ORDER BY occurred_at DESC, event_id DESC
+     LIMIT $2`,
+		leaseName,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanLeaseEvents(rows)
+}
+
+func (s *postgresStore) loadQueueDepths(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT submission_target, COUNT(*)
+     FROM submission_intents
+     WHERE status = $1 AND next_attempt_at IS NOT NULL
+     GROUP BY submission_target`,
+		string(IntentPending),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanQueueDepths(rows)
+}
+
+func (s *postgresStore) loadDueIntents(ctx context.Context, limit int) ([]dueIntentRow, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT intent_id, submission_target, next_attempt_at, attempt_count, partition_key
+     FROM submission_intents
+     WHERE status = $1 AND next_attempt_at IS NOT NULL
+     ORDER BY next_attempt_at, intent_id
+     LIMIT $2`,
+		string(IntentPending),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDueIntents(rows)
+}
+
+func (s *postgresStore) loadDrain(ctx context.Context) (DrainState, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT draining, reason, updated_at
+     FROM submission_manager_drain
+     WHERE drain_id = 1`,
+	)
+	return scanDrain(row)
+}
+
+func (s *postgresStore) saveDrain(ctx context.Context, draining bool, reason string) (DrainState, error) {
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO submission_manager_drain (drain_id, draining, reason, updated_at)
+    VALUES (1, $1, $2, utc_now())
+    ON CONFLICT (drain_id) DO UPDATE
+    SET draining = EXCLUDED.draining, reason = EXCLUDED.reason, updated_at = EXCLUDED.updated_at`,
+		draining,
+		nullString(reason),
+	)
+	if err != nil {
+		return DrainState{}, err
+	}
+	return s.loadDrain(ctx)
+}
@@ -19,20 +19,48 @@ type scheduleChangeRow struct {
 	lastModified time.Time
 }
 
-func (s *sqlStore) loadScheduleSnapshot(ctx context.Context) ([]scheduleSnapshotRow, error) {
+// loadScheduleSnapshot loads the pending intents of one partition out of count.
+func (s *sqlStore) loadScheduleSnapshot(ctx context.Context, partition, count int) ([]scheduleSnapshotRow, error) {
 	rows, err := s.db.QueryContext(
 		ctx,
 		`SELECT intent_id, next_attempt_at, last_modified_at
      FROM dbo.submission_intents
      WHERE status = @p1 AND next_attempt_at IS NOT NULL
+       AND partition_key % @p2 = @p3
      ORDER BY last_modified_at, intent_id`,
 		string(IntentPending),
+		max(count, 1),
+		partition,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanScheduleSnapshot(rows)
+}
+
+// loadScheduleChanges loads intents of the cursor's partition modified after the cursor.
+func (s *sqlStore) loadScheduleChanges(ctx context.Context, cursor scheduleCursor, count int) ([]scheduleChangeRow, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT intent_id, status, next_attempt_at, last_modified_at
+     FROM dbo.submission_intents
+     WHERE ((last_modified_at > @p1) OR (last_modified_at = @p1 AND intent_id > @p2))
+       AND partition_key % @p3 = @p4
+     ORDER BY last_modified_at, intent_id`,
+		cursor.lastModified,
+		cursor.intentID,
+		max(count, 1),
+		cursor.partition,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanScheduleChanges(rows)
+}
 
+func scanScheduleSnapshot(rows *sql.Rows) ([]scheduleSnapshotRow, error) {
 	var scheduled []scheduleSnapshotRow
 	for rows.Next() {
 		var intentID string
@@ -53,21 +81,7 @@ func (s *sqlStore) loadScheduleSnapshot(ctx context.Context) ([]scheduleSnapshot
 	return scheduled, nil
 }
 
-func (s *sqlStore) loadScheduleChanges(ctx context.Context, cursor scheduleCursor) ([]scheduleChangeRow, error) {
-	rows, err := s.db.QueryContext(
-		ctx,
-		`SELECT intent_id, status, next_attempt_at, last_modified_at
-     FROM dbo.submission_intents
-     WHERE (last_modified_at > @p1) OR (last_modified_at = @p1 AND intent_id > @p2)
-     ORDER BY last_modified_at, intent_id`,
-		cursor.lastModified,
-		cursor.intentID,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
+func scanScheduleChanges(rows *sql.Rows) ([]scheduleChangeRow, error) {
 	var changes []scheduleChangeRow
 	for rows.Next() {
 		var intentID string
@@ -0,0 +1,724 @@
+package submissionmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// SQLiteDriver is the database/sql driver name the SQLite store expects (modernc.org/sqlite).
+const SQLiteDriver = "sqlite"
+
+// sqliteTimeLayout is fixed width so that stored timestamps order correctly as text.
+const sqliteTimeLayout = "2006-01-02 15:04:05.000000000"
+
+// sqliteStore is the embedded SQLite store (conf/sql/submissionmanager/sqlite) for local
+// development and tests. SQLite serializes writers, so a fenced write's lease check and the write
+// itself cannot interleave with a takeover. The database shares the host with the process, so the
+// process clock stands in for SYSUTCDATETIME().
+type sqliteStore struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewSQLiteStore returns a Store backed by an embedded SQLite database opened with SQLiteDSN.
+func NewSQLiteStore(db *sql.DB) (Store, error) {
+	if db == nil {
+		return nil, errors.New("db is required")
+	}
+	return &sqliteStore{db: db, now: time.Now}, nil
+}
+
+// SQLiteDSN returns a DSN for path with the pragmas the store relies on: a busy timeout instead of
+// immediate SQLITE_BUSY, WAL so readers do not block the writer, enforced foreign keys, and
+// transactions that take the write lock up front.
+func SQLiteDSN(path string) string {
+	query := url.Values{}
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Set("_txlock", "immediate")
+	return "file:" + path + "?" + query.Encode()
+}
+
+func sqliteTime(value time.Time) string {
+	return value.UTC().Format(sqliteTimeLayout)
+}
+
+// sqliteArgs converts time arguments to the stored text form.
+func sqliteArgs(args []any) []any {
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case time.Time:
+			converted[i] = sqliteTime(value)
+		case sql.NullTime:
+			if value.Valid {
+				converted[i] = sqliteTime(value.Time)
+			} else {
+				converted[i] = sql.NullString{}
+			}
+		default:
+			converted[i] = arg
+		}
+	}
+	return converted
+}
+
+func (s *sqliteStore) clock() time.Time {
+	return normalizeDBTime(s.now().UTC())
+}
+
+func (s *sqliteStore) loadSQLTime(ctx context.Context) (time.Time, error) {
+	return s.clock(), nil
+}
+
+func (s *sqliteStore) insertIntent(ctx context.Context, intent Intent, payloadHash []byte, now time.Time) (Intent, bool, error) {
+	now = now.UTC()
+	args, err := intentInsertArgs(intent, payloadHash, now)
+	if err != nil {
+		return Intent{}, false, err
+	}
+	// Non-obvious constraint: SQLite has no database clock, so last_modified_at is bound at its column
+	// position and the placeholders stay in column order.
+	args = slices.Insert(args, intentLastModifiedArg, any(s.clock()))
+	args = append(args, partitionKey(intent.IntentID))
+	result, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO submission_intents (
+      intent_id,
+      submission_target,
+      payload,
+      payload_hash,
+      gateway_type,
+      gateway_url,
+      policy,
+      max_acceptance_seconds,
+      max_attempts,
+      attempt_timeout_seconds,
+      unresolved_send,
+      max_exempt_attempts,
+      budget_exempt_error_classes,
+      budget_exempt_backpressure,
+      terminal_outcomes,
+      webhook_url,
+      webhook_format,
+      webhook_headers,
+      webhook_headers_env,
+      webhook_secret_env,
+      webhook_status,
+      webhook_attempted_at,
+      webhook_delivered_at,
+      webhook_error,
+      callback_url,
+      callback_secret_env,
+      status,
+      final_outcome_status,
+      final_outcome_reason,
+      exhausted_reason,
+      attempt_count,
+      created_at,
+      updated_at,
+      last_modified_at,
+      next_attempt_at,
+      trace_parent,
+      trace_state,
+      outcome_rules,
+      error_class_rules,
+      delivery_window,
+      target_alias,
+      gateway_urls,
+      endpoint_strategy,
+      partition_key
+    ) VALUES (
+      ?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13, ?14, ?15, ?16, ?17, ?18, ?19, ?20, ?21, ?22, ?23, ?24, ?25, ?26, ?27, ?28, ?29, ?30, ?31, ?32, ?33, ?34, ?35, ?36, ?37, ?38, ?39, ?40, ?41, ?42, ?43, ?44
+    )
+    ON CONFLICT (intent_id) DO NOTHING`,
+		sqliteArgs(args)...,
+	)
+	if err != nil {
+		return Intent{}, false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return Intent{}, false, err
+	}
+	if affected > 0 {
+		intent.Status = IntentPending
+		intent.CreatedAt = now
+		return intent, true, nil
+	}
+
+	existing, ok, err := s.loadIntent(ctx, intent.IntentID)
+	if err != nil {
+		return Intent{}, false, err
+	}
+	if !ok {
+		return Intent{}, false, errors.New("intent already exists but could not be loaded")
+	}
+	return replayIntent(existing, intent)
+}
+
+func (s *sqliteStore) loadIntent(ctx context.Context, intentID string) (Intent, bool, error) {
+	intent, _, ok, err := s.loadIntentRow(ctx, intentID)
+	if err != nil || !ok {
+		return Intent{}, false, err
+	}
+
+	attempts, err := s.loadAttempts(ctx, intentID)
+	if err != nil {
+		return Intent{}, false, err
+	}
+	intent.Attempts = attempts
+	return intent, true, nil
+}
+
+func (s *sqliteStore) loadIntentRow(ctx context.Context, intentID string) (Intent, int, bool, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT `+intentColumns+`
+    FROM submission_intents
+    WHERE intent_id = ?1`,
+		intentID,
+	)
+	return scanIntentRow(row)
+}
+
+// sqliteChangeMark is the next change_seq the triggers will assign.
+const sqliteChangeMark = `SELECT COALESCE(MAX(change_seq), 0) + 1 FROM submission_intents`
+
+func (s *sqliteStore) loadIntentChangeMark(ctx context.Context) (int64, error) {
+	return queryIntentChangeMark(ctx, s.db, sqliteChangeMark)
+}
+
+func (s *sqliteStore) loadIntentChanges(ctx context.Context, since int64) ([]string, int64, error) {
+	return queryIntentChanges(
+		ctx,
+		s.db,
+		sqliteChangeMark,
+		`SELECT intent_id
+    FROM submission_intents
+    WHERE change_seq >= ?1
+      AND change_seq < ?2`,
+		since,
+	)
+}
+
+func (s *sqliteStore) loadIntentForExecution(ctx context.Context, intentID string) (Intent, int, bool, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT `+intentColumns+`
+    FROM submission_intents
+    WHERE intent_id = ?1
+      AND status = ?2
+      AND next_attempt_at IS NOT NULL
+      AND next_attempt_at <= ?3`,
+		intentID,
+		string(IntentPending),
+		sqliteTime(s.clock()),
+	)
+	return scanIntentRow(row)
+}
+
+func (s *sqliteStore) deferAttempt(ctx context.Context, fence LeaseFence, intentID string, nextAttemptAt time.Time, deferredFor time.Duration, now time.Time) (bool, error) {
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_intents
+     SET next_attempt_at = ?1,
+         deferred_ms = ?2,
+         updated_at = ?3,
+         last_modified_at = ?9
+     WHERE intent_id = ?4 AND status = ?5
+       AND EXISTS (
+         SELECT 1
+         FROM submission_manager_leases
+         WHERE lease_name = ?6
+           AND holder_id = ?7
+           AND lease_epoch = ?8
+           AND expires_at > ?9
+       )`,
+		sqliteTime(nextAttemptAt),
+		deferredFor.Milliseconds(),
+		sqliteTime(now),
+		intentID,
+		string(IntentPending),
+		fence.LeaseName,
+		fence.HolderID,
+		fence.LeaseEpoch,
+		sqliteTime(s.clock()),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *sqliteStore) markExhausted(ctx context.Context, fence LeaseFence, intentID string, exhaustedReason string, now time.Time) (bool, error) {
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_intents
+     SET status = ?1,
+         final_outcome_status = NULL,
+         final_outcome_reason = NULL,
+         exhausted_reason = ?2,
+         next_attempt_at = NULL,
+         updated_at = ?3,
+         last_modified_at = ?9
+     WHERE intent_id = ?4 AND status = ?5
+       AND EXISTS (
+         SELECT 1
+         FROM submission_manager_leases
+         WHERE lease_name = ?6
+           AND holder_id = ?7
+           AND lease_epoch = ?8
+           AND expires_at > ?9
+       )`,
+		string(IntentExhausted),
+		nullString(exhaustedReason),
+		sqliteTime(now),
+		intentID,
+		string(IntentPending),
+		fence.LeaseName,
+		fence.HolderID,
+		fence.LeaseEpoch,
+		sqliteTime(s.clock()),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *sqliteStore) loadAttempts(ctx context.Context, intentID string) ([]Attempt, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT attempt_number, started_at, finished_at, outcome_status, outcome_reason, error, error_class
+     FROM submission_attempts
+     WHERE intent_id = ?1
+     ORDER BY attempt_number`,
+		intentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanAttempts(rows)
+}
+
+func (s *sqliteStore) recordAttempt(ctx context.Context, fence LeaseFence, intent Intent, attempt Attempt, nextAttemptAt *time.Time, now time.Time) (bool, error) {
+	intentID := intent.IntentID
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var currentStatus string
+	var attemptCount int
+	row := tx.QueryRowContext(ctx, `SELECT status, attempt_count FROM submission_intents WHERE intent_id = ?1`, intentID)
+	if err = row.Scan(&currentStatus, &attemptCount); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, errors.New("intent not found")
+		}
+		return false, err
+	}
+	if currentStatus != string(IntentPending) {
+		return false, nil
+	}
+
+	attemptNumber := attemptCount + 1
+	dbNow := sqliteTime(s.clock())
+	result, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO submission_attempts (
+      intent_id, attempt_number, started_at, finished_at, outcome_status, outcome_reason, error, error_class
+    )
+    SELECT ?1, ?2, ?3, ?4, ?5, ?6, ?7, ?11
+    WHERE EXISTS (
+      SELECT 1
+      FROM submission_manager_leases
+      WHERE lease_name = ?8
+        AND holder_id = ?9
+        AND lease_epoch = ?10
+        AND expires_at > ?12
+    )`,
+		intentID,
+		attemptNumber,
+		sqliteTime(attempt.StartedAt),
+		sqliteTime(attempt.FinishedAt),
+		nullString(attempt.GatewayOutcome.Status),
+		nullString(attempt.GatewayOutcome.Reason),
+		nullString(attempt.Error),
+		fence.LeaseName,
+		fence.HolderID,
+		fence.LeaseEpoch,
+		nullString(string(attempt.ErrorClass)),
+		dbNow,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	state := attemptIntentState(intent, nextAttemptAt)
+	result, err = tx.ExecContext(
+		ctx,
+		`UPDATE submission_intents
+     SET attempt_count = ?1,
+         status = ?2,
+         final_outcome_status = ?3,
+         final_outcome_reason = ?4,
+         exhausted_reason = ?5,
+         next_attempt_at = ?6,
+         updated_at = ?7,
+         prior_send_unresolved = ?12,
+         charged_attempt_count = ?13,
+         last_modified_at = ?14
+     WHERE intent_id = ?8
+       AND EXISTS (
+         SELECT 1
+         FROM submission_manager_leases
+         WHERE lease_name = ?9
+           AND holder_id = ?10
+           AND lease_epoch = ?11
+           AND expires_at > ?14
+       )`,
+		sqliteArgs([]any{
+			attemptNumber,
+			string(intent.Status),
+			state.finalStatus,
+			state.finalReason,
+			state.exhausted,
+			state.nextAttemptAt,
+			now,
+			intentID,
+			fence.LeaseName,
+			fence.HolderID,
+			fence.LeaseEpoch,
+			intent.PriorSendUnresolved,
+			intent.ChargedAttempts,
+			dbNow,
+		})...,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err = result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	// The recorded attempt resolves its claim.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM submission_attempt_claims WHERE intent_id = ?1`, intentID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *sqliteStore) loadScheduleSnapshot(ctx context.Context, partition, count int) ([]scheduleSnapshotRow, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT intent_id, next_attempt_at, last_modified_at
+     FROM submission_intents
+     WHERE status = ?1 AND next_attempt_at IS NOT NULL
+       AND partition_key % ?2 = ?3
+     ORDER BY last_modified_at, intent_id`,
+		string(IntentPending),
+		max(count, 1),
+		partition,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanScheduleSnapshot(rows)
+}
+
+func (s *sqliteStore) loadScheduleChanges(ctx context.Context, cursor scheduleCursor, count int) ([]scheduleChangeRow, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT intent_id, status, next_attempt_at, last_modified_at
+     FROM submission_intents
+     WHERE ((last_modified_at > ?1) OR (last_modified_at = ?1 AND intent_id > ?2))
+       AND partition_key % ?3 = ?4
+     ORDER BY last_modified_at, intent_id`,
+		sqliteTime(cursor.lastModified),
+		cursor.intentID,
+		max(count, 1),
+		cursor.partition,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanScheduleChanges(rows)
+}
+
+func (s *sqliteStore) recordWebhookAttempt(ctx context.Context, fence LeaseFence, intentID string, status string, attemptedAt time.Time, errMsg string) (bool, error) {
+	var deliveredAt sql.NullTime
+	if status == webhookDelivered {
+		deliveredAt = sql.NullTime{Time: attemptedAt, Valid: true}
+	}
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_intents
+     SET webhook_status = ?1,
+         webhook_attempted_at = ?2,
+         webhook_delivered_at = ?3,
+         webhook_error = ?4,
+         updated_at = ?2,
+         last_modified_at = ?9
+     WHERE intent_id = ?5 AND webhook_status = ?6
+       AND EXISTS (
+         SELECT 1
+         FROM submission_manager_leases
+         WHERE lease_name = ?7
+           AND holder_id = ?8
+           AND lease_epoch = ?10
+           AND expires_at > ?9
+       )`,
+		sqliteArgs([]any{
+			status,
+			attemptedAt,
+			deliveredAt,
+			nullString(errMsg),
+			intentID,
+			webhookPending,
+			fence.LeaseName,
+			fence.HolderID,
+			s.clock(),
+			fence.LeaseEpoch,
+		})...,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *sqliteStore) recordWebhookRedelivery(ctx context.Context, intentID string, status string, attemptedAt time.Time, errMsg string) error {
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE submission_intents
+     SET webhook_redelivery_count = webhook_redelivery_count + 1,
+         webhook_redelivery_status = ?1,
+         webhook_redelivery_attempted_at = ?2,
+         webhook_redelivery_error = ?3,
+         last_modified_at = ?6
+     WHERE intent_id = ?4 AND status <> ?5`,
+		status,
+		sqliteTime(attemptedAt),
+		nullString(errMsg),
+		intentID,
+		string(IntentPending),
+		sqliteTime(s.clock()),
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.New("intent not found or not terminal")
+	}
+	return nil
+}
+
+// claimAttempt writes the intent's attempt claim, fenced by the partition lease.
+func (s *sqliteStore) claimAttempt(ctx context.Context, fence LeaseFence, intentID string, attemptNumber int, now time.Time) (bool, error) {
+	result, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO submission_attempt_claims (intent_id, attempt_number, holder_id, lease_epoch, claimed_at)
+    SELECT ?1, ?2, ?3, ?4, ?5
+    WHERE EXISTS (
+      SELECT 1
+      FROM submission_manager_leases
+      WHERE lease_name = ?6
+        AND holder_id = ?3
+        AND lease_epoch = ?4
+        AND expires_at > ?7
+    )
+    ON CONFLICT (intent_id) DO NOTHING`,
+		intentID,
+		attemptNumber,
+		fence.HolderID,
+		fence.LeaseEpoch,
+		sqliteTime(now),
+		fence.LeaseName,
+		sqliteTime(s.clock()),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *sqliteStore) loadAttemptClaims(ctx context.Context, partition, count int) ([]attemptClaim, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT c.intent_id, c.attempt_number, c.holder_id, c.lease_epoch, c.claimed_at
+     FROM submission_attempt_claims c
+     JOIN submission_intents i ON i.intent_id = c.intent_id
+     WHERE i.status = ?1
+       AND i.partition_key % ?2 = ?3
+     ORDER BY c.claimed_at, c.intent_id`,
+		string(IntentPending),
+		max(count, 1),
+		partition,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanAttemptClaims(rows)
+}
+
+// recordLeaseEvent appends a lease transition to the history and drops events past retention.
+func (s *sqliteStore) recordLeaseEvent(ctx context.Context, leaseName string, event LeaseEvent) error {
+	now := s.clock()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO submission_manager_lease_events (
+      lease_name, partition_number, holder_id, lease_epoch, event_type, detail, occurred_at
+    ) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)`,
+		leaseName,
+		event.Partition,
+		event.HolderID,
+		event.LeaseEpoch,
+		event.Event,
+		nullString(leaseEventDetail(event.Detail)),
+		sqliteTime(now),
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM submission_manager_lease_events WHERE occurred_at < ?1`,
+		sqliteTime(now.Add(-leaseEventRetention)),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) loadLeaseEvents(ctx context.Context, leaseName string, limit int) ([]LeaseEvent, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT partition_number, holder_id, lease_epoch, event_type, detail, occurred_at
+     FROM submission_manager_lease_events
+     WHERE lease_name = ?1
+     ORDER BY occurred_at DESC, event_id DESC
+     LIMIT ?2`,
+		leaseName,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanLeaseEvents(rows)
+}
+
+func (s *sqliteStore) loadQueueDepths(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT submission_target, COUNT(*)
+     FROM submission_intents
+     WHERE status = ?1 AND next_attempt_at IS NOT NULL
+     GROUP BY submission_target`,
+		string(IntentPending),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanQueueDepths(rows)
+}
+
+func (s *sqliteStore) loadDueIntents(ctx context.Context, limit int) ([]dueIntentRow, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT intent_id, submission_target, next_attempt_at, attempt_count, partition_key
+     FROM submission_intents
+     WHERE status = ?1 AND next_attempt_at IS NOT NULL
+     ORDER BY next_attempt_at, intent_id
+     LIMIT ?2`,
+		string(IntentPending),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanDueIntents(rows)
+}
+
+func (s *sqliteStore) loadDrain(ctx context.Context) (DrainState, error) {
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT draining, reason, updated_at
+     FROM submission_manager_drain
+     WHERE drain_id = 1`,
+	)
+	return scanDrain(row)
+}
+
+func (s *sqliteStore) saveDrain(ctx context.Context, draining bool, reason string) (DrainState, error) {
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO submission_manager_drain (drain_id, draining, reason, updated_at)
+    VALUES (1, ?1, ?2, ?3)
+    ON CONFLICT (drain_id) DO UPDATE
+    SET draining = excluded.draining, reason = excluded.reason, updated_at = excluded.updated_at`,
+		draining,
+		nullString(reason),
+		sqliteTime(s.clock()),
+	)
+	if err != nil {
+		return DrainState{}, err
+	}
+	return s.loadDrain(ctx)
+}
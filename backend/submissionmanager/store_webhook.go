@@ -3,6 +3,7 @@ package submissionmanager
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 )
 
@@ -50,3 +51,33 @@ func (s *sqlStore) recordWebhookAttempt(ctx context.Context, fence LeaseFence, i
 	}
 	return affected > 0, nil
 }
+
+func (s *sqlStore) recordWebhookRedelivery(ctx context.Context, intentID string, status string, attemptedAt time.Time, errMsg string) error {
+	attemptedAt = attemptedAt.UTC()
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE dbo.submission_intents
+     SET webhook_redelivery_count = webhook_redelivery_count + 1,
+         webhook_redelivery_status = @p1,
+         webhook_redelivery_attempted_at = @p2,
+         webhook_redelivery_error = @p3,
+         last_modified_at = SYSUTCDATETIME()
+     WHERE intent_id = @p4 AND status <> @p5`,
+		status,
+		attemptedAt,
+		nullString(errMsg),
+		intentID,
+		string(IntentPending),
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.New("intent not found or not terminal")
+	}
+	return nil
+}
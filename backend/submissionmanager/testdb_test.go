@@ -13,18 +13,38 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"gateway/migrate"
+)
+
+// testDB is a throwaway submission manager database plus the dialect its raw test SQL must use.
+type testDB struct {
+	*sql.DB
+	dialect string
+}
+
+const (
+	dialectSQLServer = "sqlserver"
+	dialectSQLite    = "sqlite"
 )
 
-func newTestDB(t *testing.T) *sql.DB {
+// newTestDB prefers SQL Server when MSSQL_SA_PASSWORD is configured and otherwise uses an embedded
+// SQLite file.
+func newTestDB(t *testing.T) *testDB {
 	t.Helper()
 
 	password, ok, err := resolveSQLPassword(t)
 	if err != nil {
 		t.Fatalf("resolve sql password: %v", err)
 	}
-	if !ok {
-		t.Skip("MSSQL_SA_PASSWORD not set; start docker compose and set env or backend/.env")
+	if ok {
+		return &testDB{DB: newTestSQLServerDB(t, password), dialect: dialectSQLServer}
 	}
+	return &testDB{DB: newTestSQLiteDB(t), dialect: dialectSQLite}
+}
+
+func newTestSQLServerDB(t *testing.T, password string) *sql.DB {
+	t.Helper()
 
 	host := envOrDefault("MSSQL_HOST", "localhost")
 	port := envOrDefault("MSSQL_PORT", "1433")
@@ -53,25 +73,13 @@ func newTestDB(t *testing.T) *sql.DB {
 		t.Fatalf("open test db: %v", err)
 	}
 
-	schemaPath := filepath.Join(moduleRoot(t), "conf", "sql", "submissionmanager", "001_create_schema.sql")
-	schema, err := os.ReadFile(schemaPath)
-	if err != nil {
-		_ = db.Close()
-		_ = dropTestDB(ctx, masterDB, dbName)
-		t.Fatalf("read schema: %v", err)
-	}
-	if _, err := db.ExecContext(ctx, string(schema)); err != nil {
-		_ = db.Close()
-		_ = dropTestDB(ctx, masterDB, dbName)
-		t.Fatalf("apply schema: %v", err)
-	}
-
 	t.Cleanup(func() {
 		_ = db.Close()
 		_ = dropTestDB(context.Background(), masterDB, dbName)
 		_ = masterDB.Close()
 	})
 
+	migrateTestDB(t, db, migrate.SQLServer, "")
 	return db
 }
 
@@ -81,6 +89,180 @@ func dropTestDB(ctx context.Context, masterDB *sql.DB, dbName string) error {
 	return err
 }
 
+// newTestPostgresDB creates a throwaway database from SM_TEST_POSTGRES_DSN (a postgres:// URL) and
+// migrates it.
+func newTestPostgresDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := strings.TrimSpace(os.Getenv("SM_TEST_POSTGRES_DSN"))
+	if dsn == "" {
+		t.Skip("SM_TEST_POSTGRES_DSN not set")
+	}
+	base, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("parse SM_TEST_POSTGRES_DSN: %v", err)
+	}
+
+	adminDB, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	dbName := fmt.Sprintf("submissionmanager_test_%d", time.Now().UnixNano())
+	if _, err := adminDB.ExecContext(ctx, fmt.Sprintf(`CREATE DATABASE "%s"`, dbName)); err != nil {
+		_ = adminDB.Close()
+		t.Fatalf("create database: %v", err)
+	}
+
+	testURL := *base
+	testURL.Path = "/" + dbName
+	db, err := sql.Open("pgx", testURL.String())
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		_, _ = adminDB.ExecContext(context.Background(), fmt.Sprintf(`DROP DATABASE IF EXISTS "%s" WITH (FORCE)`, dbName))
+		_ = adminDB.Close()
+	})
+
+	migrateTestDB(t, db, migrate.Postgres, "postgres")
+	return db
+}
+
+// newTestSQLiteDB opens an embedded database in the test's temp dir and migrates it.
+func newTestSQLiteDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open(SQLiteDriver, SQLiteDSN(filepath.Join(t.TempDir(), "submissionmanager.db")))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	migrateTestDB(t, db, migrate.SQLite, "sqlite")
+	return db
+}
+
+// migrateTestDB applies the migrations under conf/sql/submissionmanager/<subdir> with the same
+// runner the service uses.
+func migrateTestDB(t *testing.T, db *sql.DB, dialect migrate.Dialect, subdir string) {
+	t.Helper()
+	migrations, err := migrate.Load(filepath.Join(moduleRoot(t), "conf", "sql", "submissionmanager", subdir))
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	runner, err := migrate.NewRunner(db, dialect, migrations)
+	if err != nil {
+		t.Fatalf("new migration runner: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := runner.Up(ctx); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+}
+
+// store returns the Store for the database's dialect.
+func (db *testDB) store(t *testing.T) Store {
+	t.Helper()
+	var store Store
+	var err error
+	switch db.dialect {
+	case dialectSQLite:
+		store, err = NewSQLiteStore(db.DB)
+	default:
+		store, err = NewSQLServerStore(db.DB)
+	}
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	return store
+}
+
+// exec runs the statement for the database's dialect. SQLite statements take the process clock as
+// ?1 in place of SYSUTCDATETIME(), followed by args.
+func (db *testDB) exec(t *testing.T, sqlServer, sqlite string, args ...any) {
+	t.Helper()
+	var err error
+	switch db.dialect {
+	case dialectSQLite:
+		_, err = db.ExecContext(context.Background(), sqlite, sqliteArgs(append([]any{time.Now()}, args...))...)
+	default:
+		_, err = db.ExecContext(context.Background(), sqlServer, args...)
+	}
+	if err != nil {
+		t.Fatalf("exec test statement: %v", err)
+	}
+}
+
+func (db *testDB) expireLease(t *testing.T, leaseName string) {
+	t.Helper()
+	db.exec(t,
+		`UPDATE dbo.submission_manager_leases
+     SET expires_at = DATEADD(SECOND, -1, SYSUTCDATETIME())
+     WHERE lease_name = @p1`,
+		`UPDATE submission_manager_leases
+     SET expires_at = strftime('%Y-%m-%d %H:%M:%f000000', ?1, '-1 second')
+     WHERE lease_name = ?2`,
+		leaseName,
+	)
+}
+
+// rotateLease hands the lease to holderID with the next epoch, as a takeover would.
+func (db *testDB) rotateLease(t *testing.T, leaseName, holderID string) {
+	t.Helper()
+	db.exec(t,
+		`UPDATE dbo.submission_manager_leases
+     SET holder_id = @p1,
+         lease_epoch = lease_epoch + 1,
+         renewed_at = SYSUTCDATETIME(),
+         expires_at = DATEADD(SECOND, 10, SYSUTCDATETIME())
+     WHERE lease_name = @p2`,
+		`UPDATE submission_manager_leases
+     SET holder_id = ?2,
+         lease_epoch = lease_epoch + 1,
+         renewed_at = ?1,
+         expires_at = strftime('%Y-%m-%d %H:%M:%f000000', ?1, '+10 seconds')
+     WHERE lease_name = ?3`,
+		holderID,
+		leaseName,
+	)
+}
+
+func (db *testDB) setLastModified(t *testing.T, intentID string, at time.Time) {
+	t.Helper()
+	db.exec(t,
+		`UPDATE dbo.submission_intents
+     SET last_modified_at = @p1
+     WHERE intent_id = @p2`,
+		`UPDATE submission_intents
+     SET last_modified_at = ?2
+     WHERE intent_id = ?3`,
+		at,
+		intentID,
+	)
+}
+
+// makeDue sets the intent's next attempt to the database's current time.
+func (db *testDB) makeDue(t *testing.T, intentID string) {
+	t.Helper()
+	db.exec(t,
+		`UPDATE dbo.submission_intents
+     SET next_attempt_at = SYSUTCDATETIME(),
+         last_modified_at = SYSUTCDATETIME()
+     WHERE intent_id = @p1`,
+		`UPDATE submission_intents
+     SET next_attempt_at = ?1,
+         last_modified_at = ?1
+     WHERE intent_id = ?2`,
+		intentID,
+	)
+}
+
 func buildSQLServerDSN(host, port, password, database string) string {
 	u := &url.URL{
 		Scheme: "sqlserver",
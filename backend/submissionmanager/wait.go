@@ -0,0 +1,194 @@
+package submissionmanager
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// waitPollInterval is how often one instance polls SQL for intent changes while any caller waits.
+const waitPollInterval = 250 * time.Millisecond
+
+// intentWaiters fans intent changes out to WaitForIntent callers. Attempts recorded by this
+// instance notify directly; a single change poller per instance covers intents executed by other
+// instances, so SQL load does not grow with the number of waiters.
+type intentWaiters struct {
+	mu      sync.Mutex
+	waiters map[string]map[chan struct{}]struct{}
+	polling bool
+}
+
+func (w *intentWaiters) add(intentID string) chan struct{} {
+	ch := make(chan struct{}, 1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.waiters == nil {
+		w.waiters = make(map[string]map[chan struct{}]struct{})
+	}
+	if w.waiters[intentID] == nil {
+		w.waiters[intentID] = make(map[chan struct{}]struct{})
+	}
+	w.waiters[intentID][ch] = struct{}{}
+	return ch
+}
+
+func (w *intentWaiters) remove(intentID string, ch chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.waiters[intentID], ch)
+	if len(w.waiters[intentID]) == 0 {
+		delete(w.waiters, intentID)
+	}
+}
+
+func (w *intentWaiters) notify(intentID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.waiters[intentID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// startPolling reports whether the caller should start the change poller.
+func (w *intentWaiters) startPolling() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.polling {
+		return false
+	}
+	w.polling = true
+	return true
+}
+
+// stopPolling lets the next waiter start the poller again.
+func (w *intentWaiters) stopPolling() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.polling = false
+}
+
+// stopPollingIfIdle ends the poller once nobody waits.
+func (w *intentWaiters) stopPollingIfIdle() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.waiters) > 0 {
+		return false
+	}
+	w.polling = false
+	return true
+}
+
+// ensureChangePoller starts the instance's change poller if it is not running. The poller's first
+// mark is read before the caller's first read of its intent, so no change after that read is missed.
+func (m *Manager) ensureChangePoller(ctx context.Context) error {
+	if !m.waiters.startPolling() {
+		return nil
+	}
+	mark, err := m.store.loadIntentChangeMark(ctx)
+	if err != nil {
+		m.waiters.stopPolling()
+		return err
+	}
+	go m.pollChanges(mark)
+	return nil
+}
+
+// pollChanges reads the intents changed since mark, notifies their waiters, and advances mark. It
+// exits once no caller waits.
+func (m *Manager) pollChanges(mark int64) {
+	// Non-obvious constraint: mark is a database high-water mark, not a timestamp. A writer stamps
+	// last_modified_at before it commits and two writes can share one, so a time cursor could pass a
+	// change that becomes visible later.
+	for {
+		<-m.clock.After(waitPollInterval)
+		if m.waiters.stopPollingIfIdle() {
+			return
+		}
+		intentIDs, next, err := m.store.loadIntentChanges(context.Background(), mark)
+		if err != nil {
+			slog.Warn("wait_poll_failed", "error", err)
+			continue
+		}
+		mark = next
+		for _, intentID := range intentIDs {
+			m.waiters.notify(intentID)
+		}
+	}
+}
+
+// WaitCondition selects when a wait for an intent ends, besides its timeout.
+type WaitCondition string
+
+const (
+	// WaitFirstAttempt ends the wait after the first attempt, even if the intent is still pending.
+	WaitFirstAttempt WaitCondition = "first_attempt"
+	// WaitTerminal ends the wait only when the intent is accepted, rejected, or exhausted.
+	WaitTerminal WaitCondition = "terminal"
+)
+
+// WaitForIntent returns once the intent reaches a terminal state, completes its first attempt, or
+// the wait duration elapses.
+func (m *Manager) WaitForIntent(ctx context.Context, intentID string, wait time.Duration) (Intent, bool, error) {
+	return m.WaitForIntentUntil(ctx, intentID, wait, WaitFirstAttempt)
+}
+
+// WaitForIntentUntil returns once the intent meets the wait condition or the wait duration
+// elapses. It re-reads SQL only when the intent may have changed.
+func (m *Manager) WaitForIntentUntil(ctx context.Context, intentID string, wait time.Duration, until WaitCondition) (Intent, bool, error) {
+	// Flow intent: register, read, then re-read on each change notification until done or timeout.
+	trimmed := strings.TrimSpace(intentID)
+	if trimmed == "" {
+		return Intent{}, false, nil
+	}
+	if wait <= 0 {
+		intent, ok := m.GetIntent(trimmed)
+		return intent, ok, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	changed := m.waiters.add(trimmed)
+	defer m.waiters.remove(trimmed, changed)
+	if err := m.ensureChangePoller(ctx); err != nil {
+		return Intent{}, false, err
+	}
+	timeout := m.clock.After(wait)
+
+	for {
+		// Non-obvious constraint: read SQL because the executor may be another process.
+		intent, attemptCount, found, err := m.store.loadIntentRow(context.Background(), trimmed)
+		if err != nil || !found {
+			return Intent{}, found, err
+		}
+		if intent.Status == IntentAccepted || intent.Status == IntentRejected || intent.Status == IntentExhausted {
+			return intent, true, nil
+		}
+		// Non-obvious constraint: by default the wait stops after the first attempt, even if still pending.
+		if until != WaitTerminal && attemptCount >= 1 {
+			return intent, true, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return intent, true, nil
+		case <-timeout:
+			return m.waitDeadlineRead(trimmed, intent)
+		case <-changed:
+		}
+	}
+}
+
+// waitDeadlineRead takes one last look at the deadline, covering a change the poller missed.
+func (m *Manager) waitDeadlineRead(intentID string, last Intent) (Intent, bool, error) {
+	intent, _, found, err := m.store.loadIntentRow(context.Background(), intentID)
+	if err != nil || !found {
+		return last, true, nil
+	}
+	return intent, true, nil
+}
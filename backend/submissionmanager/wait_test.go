@@ -0,0 +1,135 @@
+package submissionmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gateway/submission"
+)
+
+func TestIntentWaitersNotifyOnlyMatchingIntent(t *testing.T) {
+	var waiters intentWaiters
+	a1 := waiters.add("intent-a")
+	a2 := waiters.add("intent-a")
+	b := waiters.add("intent-b")
+
+	waiters.notify("intent-a")
+	waiters.notify("intent-a")
+	for _, ch := range []chan struct{}{a1, a2} {
+		select {
+		case <-ch:
+		default:
+			t.Fatalf("expected intent-a waiter to be notified")
+		}
+	}
+	select {
+	case <-b:
+		t.Fatalf("expected intent-b waiter not to be notified")
+	default:
+	}
+
+	if !waiters.startPolling() {
+		t.Fatalf("expected first caller to start the poller")
+	}
+	if waiters.startPolling() {
+		t.Fatalf("expected a running poller not to be started twice")
+	}
+	if waiters.stopPollingIfIdle() {
+		t.Fatalf("expected poller to keep running while callers wait")
+	}
+	waiters.remove("intent-a", a1)
+	waiters.remove("intent-a", a2)
+	waiters.remove("intent-b", b)
+	if !waiters.stopPollingIfIdle() {
+		t.Fatalf("expected poller to stop once nobody waits")
+	}
+}
+
+func TestWaitForIntentWakesOnRemoteAttempt(t *testing.T) {
+	db := newTestDB(t)
+	exec := newBlockingExecutor()
+	leader := newLiveManager(t, db, exec.Exec)
+	follower := newLiveManager(t, db, newCallRecorder().Exec)
+
+	runner := NewLeaderRunnerFromManager(leader, newLeaseConfig("holder-a", 2*time.Second))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runner.Run(ctx)
+	waitForLeaderOnly(t, runner)
+
+	intentID := "intent-remote-wait"
+	if _, err := follower.SubmitIntent(context.Background(), Intent{IntentID: intentID, SubmissionTarget: "sms.realtime"}); err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+	waitForCall(t, exec.calls)
+
+	type result struct {
+		intent Intent
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		intent, _, err := follower.WaitForIntent(context.Background(), intentID, 10*time.Second)
+		done <- result{intent: intent, err: err}
+	}()
+	exec.Unblock()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			t.Fatalf("wait: %v", res.err)
+		}
+		if res.intent.Status != IntentAccepted {
+			t.Fatalf("expected accepted intent, got %q", res.intent.Status)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("expected the follower's change poller to wake the waiter")
+	}
+}
+
+func TestWaitForIntentUntilTerminalOutlastsFirstAttempt(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := baseContract(submission.PolicyMaxAttempts)
+	contract.MaxAttempts = 2
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, []execResult{
+		{outcome: GatewayOutcome{Status: "rejected", Reason: "provider_failure"}},
+		{outcome: GatewayOutcome{Status: "accepted"}},
+	})
+	manager := newManager(t, reg, stub.Exec, clock, db)
+	if _, err := manager.SubmitIntent(context.Background(), Intent{IntentID: "intent-1", SubmissionTarget: contract.SubmissionTarget}); err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+
+	_, cancel, done := startManager(t, manager)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	result := make(chan Intent, 1)
+	go func() {
+		intent, _, _ := manager.WaitForIntentUntil(context.Background(), "intent-1", 30*time.Second, WaitTerminal)
+		result <- intent
+	}()
+	waitForCall(t, stub.calls)
+	waitForAttempts(t, manager, "intent-1", 1)
+	select {
+	case intent := <-result:
+		t.Fatalf("expected terminal wait to outlast the first attempt, got %q", intent.Status)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	clock.Advance(5 * time.Second)
+	waitForCall(t, stub.calls)
+	select {
+	case intent := <-result:
+		if intent.Status != IntentAccepted {
+			t.Fatalf("expected accepted intent, got %q", intent.Status)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("expected terminal wait to return after the retry")
+	}
+}
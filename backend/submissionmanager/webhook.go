@@ -4,41 +4,127 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 	"time"
+
+	"gateway/logging"
+	"gateway/submission"
 )
 
 const (
 	webhookPending   = "pending"
 	webhookDelivered = "delivered"
 	webhookFailed    = "failed"
+	// webhookBlocked marks a delivery refused by the sender's egress policy.
+	webhookBlocked = "blocked"
+)
+
+// WebhookEgressDeniedError reports a webhook destination refused by the egress policy.
+type WebhookEgressDeniedError struct {
+	Host   string
+	Reason string
+}
+
+func (e WebhookEgressDeniedError) Error() string {
+	return fmt.Sprintf("webhook egress denied for %q: %s", e.Host, e.Reason)
+}
+
+func webhookFailureStatus(err error) string {
+	var denied WebhookEgressDeniedError
+	if errors.As(err, &denied) {
+		return webhookBlocked
+	}
+	return webhookFailed
+}
+
+// WebhookRedeliveryError reports why a terminal webhook cannot be redelivered.
+type WebhookRedeliveryError struct {
+	IntentID string
+	Reason   string
+}
+
+func (e WebhookRedeliveryError) Error() string {
+	return fmt.Sprintf("webhook for intent %q cannot be redelivered: %s", e.IntentID, e.Reason)
+}
+
+const (
+	redeliveryIntentNotTerminal    = "intent_not_terminal"
+	redeliveryWebhookNotConfigured = "webhook_not_configured"
 )
 
-func (m *Manager) dispatchWebhook(ctx context.Context, intent Intent, occurredAt time.Time) {
+// RedeliverWebhook rebuilds the terminal webhook for an intent and sends it again.
+// The result is recorded in the redelivery columns; the original delivery result is kept.
+func (m *Manager) RedeliverWebhook(ctx context.Context, intentID string) (Intent, bool, error) {
+	// Flow intent: load terminal intent, rebuild event, send, record redelivery.
+	trimmed := strings.TrimSpace(intentID)
+	if trimmed == "" {
+		return Intent{}, false, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	intent, ok, err := m.store.loadIntent(ctx, trimmed)
+	if err != nil || !ok {
+		return Intent{}, ok, err
+	}
+	if intent.Status != IntentAccepted && intent.Status != IntentRejected && intent.Status != IntentExhausted {
+		return intent, true, WebhookRedeliveryError{IntentID: trimmed, Reason: redeliveryIntentNotTerminal}
+	}
+	if intent.Contract.Webhook == nil {
+		return intent, true, WebhookRedeliveryError{IntentID: trimmed, Reason: redeliveryWebhookNotConfigured}
+	}
+	m.mu.Lock()
+	sender := m.webhookSender
+	m.mu.Unlock()
+	if sender == nil {
+		return intent, true, errors.New("webhook sender is not configured")
+	}
+
+	// Non-obvious constraint: redelivery is an operator action served by any instance, so it is not lease-fenced.
+	// The event reuses the original completion time so the body matches the first delivery.
+	attemptedAt := m.clock.Now()
+	status := webhookDelivered
+	errMsg := ""
+	delivery, err := buildWebhookDelivery(intent, intent.CompletedAt)
+	if err == nil {
+		err = sender(ctx, delivery)
+	}
+	if err != nil {
+		status = webhookFailureStatus(err)
+		errMsg = err.Error()
+	}
+	if err := m.store.recordWebhookRedelivery(ctx, trimmed, status, attemptedAt, errMsg); err != nil {
+		return Intent{}, true, err
+	}
+	slog.Info("webhook redeliver", logging.IntentID(trimmed), "status", status, "error", errMsg)
+
+	return m.store.loadIntent(ctx, trimmed)
+}
+
+func (m *Manager) dispatchWebhook(ctx context.Context, fence LeaseFence, intent Intent, occurredAt time.Time) {
 	if m.webhookSender == nil || intent.Contract.Webhook == nil {
 		return
 	}
 	if intent.WebhookStatus != webhookPending {
 		return
 	}
-	fence, ok := m.currentFence()
-	if !ok {
-		return
-	}
 	delivery, err := buildWebhookDelivery(intent, occurredAt)
 	if err != nil {
 		applied, err := m.store.recordWebhookAttempt(ctx, fence, intent.IntentID, webhookFailed, occurredAt, err.Error())
 		if err != nil || !applied {
 			if ctx == nil || ctx.Err() == nil {
-				m.notifyLeaseLoss()
+				m.notifyLeaseLoss(fence.Partition)
 			}
 		}
 		return
 	}
 	if err := m.webhookSender(ctx, delivery); err != nil {
-		applied, err := m.store.recordWebhookAttempt(ctx, fence, intent.IntentID, webhookFailed, occurredAt, err.Error())
+		applied, err := m.store.recordWebhookAttempt(ctx, fence, intent.IntentID, webhookFailureStatus(err), occurredAt, err.Error())
 		if err != nil || !applied {
 			if ctx == nil || ctx.Err() == nil {
-				m.notifyLeaseLoss()
+				m.notifyLeaseLoss(fence.Partition)
 			}
 		}
 		return
@@ -46,11 +132,54 @@ func (m *Manager) dispatchWebhook(ctx context.Context, intent Intent, occurredAt
 	applied, err := m.store.recordWebhookAttempt(ctx, fence, intent.IntentID, webhookDelivered, occurredAt, "")
 	if err != nil || !applied {
 		if ctx == nil || ctx.Err() == nil {
-			m.notifyLeaseLoss()
+			m.notifyLeaseLoss(fence.Partition)
+		}
+	}
+}
+
+// checkCallback validates a new intent's callback against the target's allowlist. A replay of an
+// existing intent passes: its callback was validated when it was created, and the allowlist or the
+// alias weights may have changed since.
+func (m *Manager) checkCallback(ctx context.Context, intentID string, contract submission.TargetContract, callbackURL, callbackSecretEnv string) error {
+	err := validateCallback(contract, callbackURL, callbackSecretEnv)
+	if err == nil {
+		return nil
+	}
+	_, found, loadErr := m.store.loadIntent(ctx, intentID)
+	if loadErr != nil {
+		return loadErr
+	}
+	if found {
+		return nil
+	}
+	return err
+}
+
+func validateCallback(contract submission.TargetContract, callbackURL, callbackSecretEnv string) error {
+	if callbackURL == "" {
+		if callbackSecretEnv != "" {
+			return CallbackNotAllowedError{SubmissionTarget: contract.SubmissionTarget, Reason: "callbackSecretEnv requires callbackUrl"}
 		}
+		return nil
+	}
+	webhook := contract.Webhook
+	if webhook == nil || len(webhook.AllowedCallbackURLs) == 0 {
+		return CallbackNotAllowedError{SubmissionTarget: contract.SubmissionTarget, CallbackURL: callbackURL, Reason: "target does not accept callback URLs"}
+	}
+	if !webhook.AllowsCallbackURL(callbackURL) {
+		return CallbackNotAllowedError{SubmissionTarget: contract.SubmissionTarget, CallbackURL: callbackURL, Reason: "callbackUrl is not in the target allowlist"}
 	}
+	if callbackSecretEnv != "" && !webhook.AllowsCallbackSecretEnv(callbackSecretEnv) {
+		return CallbackNotAllowedError{SubmissionTarget: contract.SubmissionTarget, CallbackURL: callbackURL, Reason: "callbackSecretEnv is not in the target allowlist"}
+	}
+	return nil
 }
 
+const (
+	webhookEventType       = "intent.terminal"
+	cloudEventsSpecVersion = "1.0"
+)
+
 func buildWebhookDelivery(intent Intent, occurredAt time.Time) (WebhookDelivery, error) {
 	webhook := intent.Contract.Webhook
 	if webhook == nil {
@@ -77,39 +206,92 @@ func buildWebhookDelivery(intent Intent, occurredAt time.Time) (WebhookDelivery,
 	case IntentExhausted:
 		intentPayload.ExhaustedReason = intent.ExhaustedReason
 	}
-	payload := struct {
-		EventID    string      `json:"eventId"`
-		EventType  string      `json:"eventType"`
-		OccurredAt string      `json:"occurredAt"`
-		Intent     interface{} `json:"intent"`
-	}{
-		EventID:    intent.IntentID,
-		EventType:  "intent.terminal",
-		OccurredAt: occurredAt.UTC().Format(time.RFC3339Nano),
-		Intent:     intentPayload,
+	occurred := occurredAt.UTC().Format(time.RFC3339Nano)
+	// Non-obvious constraint: CloudEvents source must be a stable URI-reference per producer and target.
+	source := "/setu/submission-targets/" + intent.SubmissionTarget
+
+	headers := make(map[string]string, len(webhook.Headers)+7)
+	for key, value := range webhook.Headers {
+		headers[key] = value
+	}
+
+	var payload interface{}
+	switch webhook.Format {
+	case "", submission.WebhookFormatSetu:
+		payload = struct {
+			EventID    string      `json:"eventId"`
+			EventType  string      `json:"eventType"`
+			OccurredAt string      `json:"occurredAt"`
+			Intent     interface{} `json:"intent"`
+		}{
+			EventID:    intent.IntentID,
+			EventType:  webhookEventType,
+			OccurredAt: occurred,
+			Intent:     intentPayload,
+		}
+		headers["Content-Type"] = "application/json"
+		headers["X-Setu-Event-Type"] = webhookEventType
+		headers["X-Setu-Event-Id"] = intent.IntentID
+	case submission.WebhookFormatCloudEventsStructured:
+		payload = struct {
+			SpecVersion     string      `json:"specversion"`
+			ID              string      `json:"id"`
+			Source          string      `json:"source"`
+			Type            string      `json:"type"`
+			Time            string      `json:"time"`
+			Subject         string      `json:"subject"`
+			DataContentType string      `json:"datacontenttype"`
+			Data            interface{} `json:"data"`
+		}{
+			SpecVersion:     cloudEventsSpecVersion,
+			ID:              intent.IntentID,
+			Source:          source,
+			Type:            webhookEventType,
+			Time:            occurred,
+			Subject:         intent.IntentID,
+			DataContentType: "application/json",
+			Data:            intentPayload,
+		}
+		headers["Content-Type"] = "application/cloudevents+json"
+	case submission.WebhookFormatCloudEventsBinary:
+		// Binary mode carries the attributes as ce-* headers and only the event data in the body.
+		payload = intentPayload
+		headers["Content-Type"] = "application/json"
+		headers["ce-specversion"] = cloudEventsSpecVersion
+		headers["ce-id"] = intent.IntentID
+		headers["ce-source"] = source
+		headers["ce-type"] = webhookEventType
+		headers["ce-time"] = occurred
+		headers["ce-subject"] = intent.IntentID
+	default:
+		return WebhookDelivery{}, fmt.Errorf("unknown webhook format %q", webhook.Format)
 	}
+
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return WebhookDelivery{}, err
 	}
-	headers := make(map[string]string, len(webhook.Headers)+3)
-	for key, value := range webhook.Headers {
-		headers[key] = value
-	}
-	headers["Content-Type"] = "application/json"
-	headers["X-Setu-Event-Type"] = "intent.terminal"
-	headers["X-Setu-Event-Id"] = intent.IntentID
 
 	headersEnv := map[string]string{}
 	for key, value := range webhook.HeadersEnv {
 		headersEnv[key] = value
 	}
 
+	// A per-intent callback replaces the destination; headers and format still come from the target.
+	destination := webhook.URL
+	secretEnv := webhook.SecretEnv
+	if intent.CallbackURL != "" {
+		destination = intent.CallbackURL
+		if intent.CallbackSecretEnv != "" {
+			secretEnv = intent.CallbackSecretEnv
+		}
+	}
+
 	return WebhookDelivery{
-		URL:        webhook.URL,
+		URL:        destination,
 		Headers:    headers,
 		HeadersEnv: headersEnv,
-		SecretEnv:  webhook.SecretEnv,
+		SecretEnv:  secretEnv,
 		Body:       body,
 	}, nil
 }
@@ -0,0 +1,202 @@
+package submissionmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"gateway/submission"
+)
+
+func terminalIntentWithFormat(format submission.WebhookFormat) Intent {
+	contract := contractWithWebhook(baseContract(submission.PolicyOneShot))
+	contract.Webhook.Format = format
+	return Intent{
+		IntentID:         "intent-1",
+		SubmissionTarget: contract.SubmissionTarget,
+		CreatedAt:        time.Unix(0, 0),
+		Status:           IntentRejected,
+		Contract:         contract,
+		FinalOutcome:     GatewayOutcome{Status: gatewayRejected, Reason: "invalid_request"},
+	}
+}
+
+func TestBuildWebhookDeliverySetuEnvelope(t *testing.T) {
+	delivery, err := buildWebhookDelivery(terminalIntentWithFormat(submission.WebhookFormatSetu), time.Unix(10, 0))
+	if err != nil {
+		t.Fatalf("build delivery: %v", err)
+	}
+	if delivery.Headers["X-Setu-Event-Id"] != "intent-1" {
+		t.Fatalf("expected X-Setu-Event-Id header, got %+v", delivery.Headers)
+	}
+	var body struct {
+		EventID   string `json:"eventId"`
+		EventType string `json:"eventType"`
+		Intent    struct {
+			RejectedReason string `json:"rejectedReason"`
+		} `json:"intent"`
+	}
+	if err := json.Unmarshal(delivery.Body, &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.EventID != "intent-1" || body.EventType != "intent.terminal" {
+		t.Fatalf("unexpected envelope: %+v", body)
+	}
+	if body.Intent.RejectedReason != "invalid_request" {
+		t.Fatalf("expected rejected reason, got %q", body.Intent.RejectedReason)
+	}
+}
+
+func TestBuildWebhookDeliveryCloudEventsStructured(t *testing.T) {
+	delivery, err := buildWebhookDelivery(terminalIntentWithFormat(submission.WebhookFormatCloudEventsStructured), time.Unix(10, 0))
+	if err != nil {
+		t.Fatalf("build delivery: %v", err)
+	}
+	if delivery.Headers["Content-Type"] != "application/cloudevents+json" {
+		t.Fatalf("expected cloudevents content type, got %q", delivery.Headers["Content-Type"])
+	}
+	var event struct {
+		SpecVersion string `json:"specversion"`
+		ID          string `json:"id"`
+		Source      string `json:"source"`
+		Type        string `json:"type"`
+		Time        string `json:"time"`
+		Subject     string `json:"subject"`
+		Data        struct {
+			IntentID string `json:"intentId"`
+			Status   string `json:"status"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(delivery.Body, &event); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if event.SpecVersion != "1.0" || event.ID != "intent-1" || event.Type != "intent.terminal" || event.Subject != "intent-1" {
+		t.Fatalf("unexpected cloudevent attributes: %+v", event)
+	}
+	if event.Source == "" || event.Time != "1970-01-01T00:00:10Z" {
+		t.Fatalf("unexpected source or time: %+v", event)
+	}
+	if event.Data.IntentID != "intent-1" || event.Data.Status != "rejected" {
+		t.Fatalf("unexpected cloudevent data: %+v", event.Data)
+	}
+}
+
+func TestBuildWebhookDeliveryCloudEventsBinary(t *testing.T) {
+	delivery, err := buildWebhookDelivery(terminalIntentWithFormat(submission.WebhookFormatCloudEventsBinary), time.Unix(10, 0))
+	if err != nil {
+		t.Fatalf("build delivery: %v", err)
+	}
+	if delivery.Headers["ce-id"] != "intent-1" || delivery.Headers["ce-subject"] != "intent-1" {
+		t.Fatalf("expected ce-id and ce-subject headers, got %+v", delivery.Headers)
+	}
+	if delivery.Headers["ce-type"] != "intent.terminal" || delivery.Headers["ce-specversion"] != "1.0" {
+		t.Fatalf("expected ce-type and ce-specversion headers, got %+v", delivery.Headers)
+	}
+	if _, ok := delivery.Headers["X-Setu-Event-Id"]; ok {
+		t.Fatalf("expected no Setu envelope headers in binary mode")
+	}
+	var data struct {
+		IntentID string `json:"intentId"`
+	}
+	if err := json.Unmarshal(delivery.Body, &data); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if data.IntentID != "intent-1" {
+		t.Fatalf("expected event data body, got %q", delivery.Body)
+	}
+}
+
+func TestBuildWebhookDeliveryUsesIntentCallback(t *testing.T) {
+	intent := terminalIntentWithFormat(submission.WebhookFormatSetu)
+	intent.CallbackURL = "https://hooks.example.com/team-a/events"
+	intent.CallbackSecretEnv = "TEAM_A_SECRET"
+
+	delivery, err := buildWebhookDelivery(intent, time.Unix(10, 0))
+	if err != nil {
+		t.Fatalf("build delivery: %v", err)
+	}
+	if delivery.URL != intent.CallbackURL {
+		t.Fatalf("expected callback url, got %q", delivery.URL)
+	}
+	if delivery.SecretEnv != "TEAM_A_SECRET" {
+		t.Fatalf("expected callback secret env, got %q", delivery.SecretEnv)
+	}
+}
+
+func TestValidateCallback(t *testing.T) {
+	contract := contractWithWebhook(baseContract(submission.PolicyOneShot))
+	contract.Webhook.AllowedCallbackURLs = []string{"https://hooks.example.com/team-a"}
+	contract.Webhook.AllowedCallbackSecretEnvs = []string{"TEAM_A_SECRET"}
+
+	cases := []struct {
+		name      string
+		url       string
+		secretEnv string
+		wantErr   bool
+	}{
+		{name: "no callback"},
+		{name: "allowed prefix", url: "https://hooks.example.com/team-a/events", secretEnv: "TEAM_A_SECRET"},
+		{name: "sibling path", url: "https://hooks.example.com/team-a-evil", wantErr: true},
+		{name: "trailing slash", url: "https://hooks.example.com/team-a/events/", secretEnv: "TEAM_A_SECRET"},
+		{name: "dot segments", url: "https://hooks.example.com/team-a/../admin", wantErr: true},
+		{name: "escaped dot segments", url: "https://hooks.example.com/team-a/%2e%2e/admin", wantErr: true},
+		{name: "escaped slash", url: "https://hooks.example.com/team-a%2F..%2Fadmin", wantErr: true},
+		{name: "duplicate slash", url: "https://hooks.example.com/team-a//events", wantErr: true},
+		{name: "other host", url: "https://evil.example.com/team-a", wantErr: true},
+		{name: "unknown secret", url: "https://hooks.example.com/team-a", secretEnv: "OTHER_SECRET", wantErr: true},
+		{name: "secret without url", secretEnv: "TEAM_A_SECRET", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateCallback(contract, tc.url, tc.secretEnv)
+			if tc.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCallbackReplayAfterAllowlistTightened(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	db := newTestDB(t)
+	contract := contractWithWebhook(baseContract(submission.PolicyOneShot))
+	contract.Webhook.AllowedCallbackURLs = []string{"https://hooks.example.com/team-a"}
+	reg := submission.Registry{Targets: map[string]submission.TargetContract{contract.SubmissionTarget: contract}}
+	stub := newStubExecutor(clock, nil)
+	manager := newManager(t, reg, stub.Exec, clock, db)
+
+	intent := Intent{
+		IntentID:         "intent-1",
+		SubmissionTarget: contract.SubmissionTarget,
+		Payload:          []byte(`{"a":1}`),
+		CallbackURL:      "https://hooks.example.com/team-a/events",
+	}
+	if _, err := manager.SubmitIntent(context.Background(), intent); err != nil {
+		t.Fatalf("submit intent: %v", err)
+	}
+
+	tightened := contractWithWebhook(baseContract(submission.PolicyOneShot))
+	tightened.Webhook.AllowedCallbackURLs = []string{"https://hooks.example.com/team-b"}
+	reg = submission.Registry{Targets: map[string]submission.TargetContract{tightened.SubmissionTarget: tightened}}
+	restarted := newManager(t, reg, stub.Exec, clock, db)
+
+	replayed, err := restarted.SubmitIntent(context.Background(), intent)
+	if err != nil {
+		t.Fatalf("replay after the allowlist changed: %v", err)
+	}
+	if replayed.CallbackURL != intent.CallbackURL {
+		t.Fatalf("expected the stored callback, got %q", replayed.CallbackURL)
+	}
+
+	intent.IntentID = "intent-2"
+	_, err = restarted.SubmitIntent(context.Background(), intent)
+	var notAllowed CallbackNotAllowedError
+	if !errors.As(err, &notAllowed) {
+		t.Fatalf("expected a new intent to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// JSONExporter writes one JSON object per span, newline-delimited.
+type JSONExporter struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+// NewJSONExporter writes spans to w.
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{enc: json.NewEncoder(w)}
+}
+
+// NewFileExporter appends spans to the file at path, creating it if needed.
+func NewFileExporter(path string) (*JSONExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	exporter := NewJSONExporter(file)
+	exporter.closer = file
+	return exporter, nil
+}
+
+// ExportSpan writes the span. Write errors are logged, never returned to the traced operation.
+func (e *JSONExporter) ExportSpan(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.enc.Encode(span); err != nil {
+		slog.Error("trace export error", "error", err)
+	}
+}
+
+// Close closes the underlying file for file exporters.
+func (e *JSONExporter) Close() error {
+	if e == nil || e.closer == nil {
+		return nil
+	}
+	return e.closer.Close()
+}
+
+// NewExporter builds an exporter by name: "none" (or empty), "stdout", or "file" (requires path).
+// A nil exporter with a nil error means spans are not exported.
+func NewExporter(kind, path string) (Exporter, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", "none":
+		return nil, nil
+	case "stdout":
+		return NewJSONExporter(os.Stdout), nil
+	case "file":
+		if strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("trace exporter %q requires a file path", kind)
+		}
+		return NewFileExporter(path)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (want none, stdout, or file)", kind)
+	}
+}
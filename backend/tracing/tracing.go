@@ -0,0 +1,265 @@
+// Package tracing propagates W3C trace context and exports finished spans.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TraceparentHeader is the W3C trace context header carrying trace and parent span ids.
+	TraceparentHeader = "traceparent"
+	// TracestateHeader is the W3C header carrying vendor-specific trace state.
+	TracestateHeader = "tracestate"
+
+	flagSampled = 0x01
+)
+
+// SpanContext identifies a span within a trace.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	Flags      byte
+	TraceState string
+}
+
+// IsValid reports whether both ids are non-zero.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceIDString returns the lowercase hex trace id.
+func (sc SpanContext) TraceIDString() string {
+	return hex.EncodeToString(sc.TraceID[:])
+}
+
+// SpanIDString returns the lowercase hex span id.
+func (sc SpanContext) SpanIDString() string {
+	return hex.EncodeToString(sc.SpanID[:])
+}
+
+// Traceparent formats the span context as a version 00 traceparent value.
+func (sc SpanContext) Traceparent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+	return "00-" + sc.TraceIDString() + "-" + sc.SpanIDString() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ParseTraceparent parses a traceparent header value and attaches the tracestate value.
+// Invalid values return false so callers start a new trace.
+func ParseTraceparent(traceparent, tracestate string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	// Non-obvious constraint: version ff is forbidden, and version 00 must have exactly four fields.
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil || strings.ToLower(parts[1]) != parts[1] {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil || strings.ToLower(parts[2]) != parts[2] {
+		return SpanContext{}, false
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return SpanContext{}, false
+	}
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	sc.TraceState = strings.TrimSpace(tracestate)
+	return sc, true
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns ctx carrying sc as the current span context.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the current span context, if any.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if ctx == nil {
+		return SpanContext{}, false
+	}
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// Extract returns ctx carrying the span context from incoming traceparent/tracestate headers.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	sc, ok := ParseTraceparent(header.Get(TraceparentHeader), header.Get(TracestateHeader))
+	if !ok {
+		return ctx
+	}
+	return ContextWithSpanContext(ctx, sc)
+}
+
+// Inject writes the current span context from ctx to outgoing headers.
+func Inject(ctx context.Context, header http.Header) {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok {
+		return
+	}
+	header.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		header.Set(TracestateHeader, sc.TraceState)
+	}
+}
+
+// SpanData is a finished span as handed to an Exporter.
+type SpanData struct {
+	Service      string            `json:"service"`
+	Name         string            `json:"name"`
+	TraceID      string            `json:"traceId"`
+	SpanID       string            `json:"spanId"`
+	ParentSpanID string            `json:"parentSpanId,omitempty"`
+	Start        time.Time         `json:"start"`
+	End          time.Time         `json:"end"`
+	DurationMs   float64           `json:"durationMs"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// Exporter receives finished spans. Implementations must be safe for concurrent use.
+type Exporter interface {
+	ExportSpan(SpanData)
+}
+
+// Tracer creates spans for one service. A nil Tracer creates no spans and leaves ctx unchanged.
+type Tracer struct {
+	service  string
+	exporter Exporter
+}
+
+// NewTracer constructs a Tracer. With a nil exporter spans still propagate ids but are not exported.
+func NewTracer(service string, exporter Exporter) *Tracer {
+	return &Tracer{service: service, exporter: exporter}
+}
+
+// Start begins a span as a child of the span context in ctx, or a new trace when there is none.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	return t.StartAt(ctx, name, time.Now())
+}
+
+// StartAt is Start with an explicit start time, for spans covering a wait that already began.
+func (t *Tracer) StartAt(ctx context.Context, name string, start time.Time) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{tracer: t, name: name, start: start}
+	if parent, ok := SpanContextFromContext(ctx); ok {
+		span.parentSpanID = parent.SpanIDString()
+		span.sc.TraceID = parent.TraceID
+		span.sc.Flags = parent.Flags
+		span.sc.TraceState = parent.TraceState
+	} else {
+		span.sc.TraceID = newTraceID()
+		span.sc.Flags = flagSampled
+	}
+	span.sc.SpanID = newSpanID()
+	return ContextWithSpanContext(ctx, span.sc), span
+}
+
+// Span is an in-progress operation. All methods are safe on a nil Span.
+type Span struct {
+	tracer       *Tracer
+	name         string
+	sc           SpanContext
+	parentSpanID string
+	start        time.Time
+
+	mu    sync.Mutex
+	attrs map[string]string
+	ended bool
+}
+
+// SpanContext returns the span's identity for propagation or persistence.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetAttribute records a string attribute; empty values are ignored.
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil || value == "" {
+		return
+	}
+	s.mu.Lock()
+	if s.attrs == nil {
+		s.attrs = make(map[string]string)
+	}
+	s.attrs[key] = value
+	s.mu.Unlock()
+}
+
+// End finishes the span now, recording err when non-nil.
+func (s *Span) End(err error) {
+	s.EndAt(time.Now(), err)
+}
+
+// EndAt finishes the span at the given time. Only the first call exports.
+func (s *Span) EndAt(end time.Time, err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	attrs := s.attrs
+	s.mu.Unlock()
+
+	if s.tracer.exporter == nil {
+		return
+	}
+	data := SpanData{
+		Service:      s.tracer.service,
+		Name:         s.name,
+		TraceID:      s.sc.TraceIDString(),
+		SpanID:       s.sc.SpanIDString(),
+		ParentSpanID: s.parentSpanID,
+		Start:        s.start.UTC(),
+		End:          end.UTC(),
+		DurationMs:   float64(end.Sub(s.start)) / float64(time.Millisecond),
+		Attributes:   attrs,
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	s.tracer.exporter.ExportSpan(data)
+}
+
+func newTraceID() [16]byte {
+	var id [16]byte
+	for id == [16]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() [8]byte {
+	var id [8]byte
+	for id == [8]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	sc, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "vendor=abc")
+	if !ok {
+		t.Fatal("expected valid traceparent")
+	}
+	if sc.TraceIDString() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanIDString() != "00f067aa0ba902b7" {
+		t.Fatalf("unexpected ids: %s %s", sc.TraceIDString(), sc.SpanIDString())
+	}
+	if sc.Traceparent() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("unexpected round trip: %s", sc.Traceparent())
+	}
+	if sc.TraceState != "vendor=abc" {
+		t.Fatalf("unexpected tracestate %q", sc.TraceState)
+	}
+
+	for _, value := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+	} {
+		if _, ok := ParseTraceparent(value, ""); ok {
+			t.Fatalf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestTracerPropagatesAndExports(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer("svc", NewJSONExporter(&buf))
+
+	incoming := http.Header{}
+	incoming.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	incoming.Set(TracestateHeader, "vendor=abc")
+	ctx, span := tracer.Start(Extract(context.Background(), incoming), "op")
+	span.SetAttribute("intentId", "intent-1")
+
+	outgoing := http.Header{}
+	Inject(ctx, outgoing)
+	child, ok := ParseTraceparent(outgoing.Get(TraceparentHeader), outgoing.Get(TracestateHeader))
+	if !ok {
+		t.Fatalf("expected injected traceparent, got %q", outgoing.Get(TraceparentHeader))
+	}
+	if child.TraceIDString() != "4bf92f3577b34da6a3ce929d0e0e4736" || child.SpanID != span.SpanContext().SpanID {
+		t.Fatalf("expected child of incoming trace, got %s", outgoing.Get(TraceparentHeader))
+	}
+	if child.TraceState != "vendor=abc" {
+		t.Fatalf("expected tracestate to propagate, got %q", child.TraceState)
+	}
+
+	span.End(errors.New("boom"))
+	span.End(nil)
+	var data SpanData
+	if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+		t.Fatalf("decode span (exported once): %v", err)
+	}
+	if data.Service != "svc" || data.Name != "op" || data.ParentSpanID != "00f067aa0ba902b7" || data.Error != "boom" || data.Attributes["intentId"] != "intent-1" {
+		t.Fatalf("unexpected span: %+v", data)
+	}
+}
+
+func TestNilTracerIsNoop(t *testing.T) {
+	var tracer *Tracer
+	ctx, span := tracer.Start(context.Background(), "op")
+	span.SetAttribute("k", "v")
+	span.End(nil)
+	if _, ok := SpanContextFromContext(ctx); ok {
+		t.Fatal("expected no span context")
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	if exporter, err := NewExporter("none", ""); err != nil || exporter != nil {
+		t.Fatalf("expected nil exporter, got %v %v", exporter, err)
+	}
+	if _, err := NewExporter("file", ""); err == nil {
+		t.Fatal("expected error for file exporter without path")
+	}
+	if _, err := NewExporter("zipkin", ""); err == nil {
+		t.Fatal("expected error for unknown exporter")
+	}
+}